@@ -0,0 +1,53 @@
+package api
+
+import "math"
+
+//ThroughputAt returns the throughput of the profile at t seconds after the start of load generation.
+func (p *LoadProfile) ThroughputAt(t float64) float64 {
+	points := p.GetPoints()
+	if len(points) == 0 {
+		return 0
+	}
+	if t < points[0].OffsetSeconds {
+		return points[0].Throughput
+	}
+	for i := 1; i < len(points); i++ {
+		from, to := points[i-1], points[i]
+		if t < to.OffsetSeconds {
+			return from.Throughput + (to.Throughput-from.Throughput)*(t-from.OffsetSeconds)/(to.OffsetSeconds-from.OffsetSeconds)
+		}
+	}
+	return points[len(points)-1].Throughput
+}
+
+//Integral returns the number of traces the profile generates in the first t seconds, i.e. the integral of the throughput from 0 to t.
+//Each segment between two points is integrated with its own throughputs, so jumps, i.e. segments of length 0, count on neither side.
+func (p *LoadProfile) Integral(t float64) float64 {
+	points := p.GetPoints()
+	if len(points) == 0 || t <= 0 {
+		return 0
+	}
+	sum := points[0].Throughput * math.Max(0, math.Min(t, points[0].OffsetSeconds))
+	for i := 1; i < len(points) && points[i-1].OffsetSeconds < t; i++ {
+		from, to := points[i-1], points[i]
+		length := to.OffsetSeconds - from.OffsetSeconds
+		if length <= 0 {
+			continue
+		}
+		end := math.Min(t, to.OffsetSeconds)
+		throughput := from.Throughput + (to.Throughput-from.Throughput)*(end-from.OffsetSeconds)/length
+		sum += (from.Throughput + throughput) / 2 * (end - from.OffsetSeconds)
+	}
+	if last := points[len(points)-1]; t > last.OffsetSeconds {
+		sum += last.Throughput * (t - last.OffsetSeconds)
+	}
+	return sum
+}
+
+//MeanThroughput returns the mean throughput of the profile over the first runtime seconds.
+func (p *LoadProfile) MeanThroughput(runtime float64) float64 {
+	if runtime <= 0 {
+		return p.ThroughputAt(0)
+	}
+	return p.Integral(runtime) / runtime
+}
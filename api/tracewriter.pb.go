@@ -21,6 +21,67 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type WorkerState int32
+
+const (
+	WorkerState_IDLE     WorkerState = 0
+	WorkerState_PREPARED WorkerState = 1
+	WorkerState_RUNNING  WorkerState = 2
+	WorkerState_STOPPING WorkerState = 3
+	WorkerState_FINISHED WorkerState = 4
+	WorkerState_ABORTED  WorkerState = 5
+	WorkerState_FAILED   WorkerState = 6
+)
+
+// Enum value maps for WorkerState.
+var (
+	WorkerState_name = map[int32]string{
+		0: "IDLE",
+		1: "PREPARED",
+		2: "RUNNING",
+		3: "STOPPING",
+		4: "FINISHED",
+		5: "ABORTED",
+		6: "FAILED",
+	}
+	WorkerState_value = map[string]int32{
+		"IDLE":     0,
+		"PREPARED": 1,
+		"RUNNING":  2,
+		"STOPPING": 3,
+		"FINISHED": 4,
+		"ABORTED":  5,
+		"FAILED":   6,
+	}
+)
+
+func (x WorkerState) Enum() *WorkerState {
+	p := new(WorkerState)
+	*p = x
+	return p
+}
+
+func (x WorkerState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WorkerState) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_tracewriter_proto_enumTypes[0].Descriptor()
+}
+
+func (WorkerState) Type() protoreflect.EnumType {
+	return &file_api_tracewriter_proto_enumTypes[0]
+}
+
+func (x WorkerState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WorkerState.Descriptor instead.
+func (WorkerState) EnumDescriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{0}
+}
+
 type RelationshipType int32
 
 const (
@@ -66,11 +127,11 @@ func (x RelationshipType) String() string {
 }
 
 func (RelationshipType) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_tracewriter_proto_enumTypes[0].Descriptor()
+	return file_api_tracewriter_proto_enumTypes[1].Descriptor()
 }
 
 func (RelationshipType) Type() protoreflect.EnumType {
-	return &file_api_tracewriter_proto_enumTypes[0]
+	return &file_api_tracewriter_proto_enumTypes[1]
 }
 
 func (x RelationshipType) Number() protoreflect.EnumNumber {
@@ -79,7 +140,7 @@ func (x RelationshipType) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RelationshipType.Descriptor instead.
 func (RelationshipType) EnumDescriptor() ([]byte, []int) {
-	return file_api_tracewriter_proto_rawDescGZIP(), []int{0}
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{1}
 }
 
 type WorkerConfiguration struct {
@@ -92,8 +153,18 @@ type WorkerConfiguration struct {
 	RuntimeSeconds   int64  `protobuf:"varint,4,opt,name=runtime_seconds,json=runtimeSeconds,proto3" json:"runtime_seconds,omitempty"`
 	TargetThroughput int64  `protobuf:"varint,5,opt,name=target_throughput,json=targetThroughput,proto3" json:"target_throughput,omitempty"`
 	//the sink is the backend address to send traces to, i.e. an endpoint of an opentracing-compatible tracer
-	SinkHostPort string  `protobuf:"bytes,6,opt,name=sink_host_port,json=sinkHostPort,proto3" json:"sink_host_port,omitempty"`
-	Units        []*Unit `protobuf:"bytes,9,rep,name=units,proto3" json:"units,omitempty"`
+	SinkHostPort string `protobuf:"bytes,6,opt,name=sink_host_port,json=sinkHostPort,proto3" json:"sink_host_port,omitempty"`
+	//the sink provider selects the tracer backend used to send traces to the sink, e.g. jaeger, otlp-grpc or otlp-http
+	SinkProvider string `protobuf:"bytes,7,opt,name=sink_provider,json=sinkProvider,proto3" json:"sink_provider,omitempty"`
+	//the propagation format of trace context between services, e.g. uber, w3c, b3 or b3multi. Empty uses the native format of the sink provider.
+	Propagation string  `protobuf:"bytes,10,opt,name=propagation,proto3" json:"propagation,omitempty"`
+	Units       []*Unit `protobuf:"bytes,9,rep,name=units,proto3" json:"units,omitempty"`
+	//the load profile changes target_throughput over the run. Unset keeps target_throughput for the whole run.
+	LoadProfile *LoadProfile `protobuf:"bytes,11,opt,name=load_profile,json=loadProfile,proto3" json:"load_profile,omitempty"`
+	//limits the number of invocations the worker runs concurrently in goroutines it starts itself. Unset doesn't limit the worker.
+	Concurrency *Concurrency `protobuf:"bytes,12,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
+	//seeds all random choices of the worker, i.e. work times, random tag values and arrivals, per unit. 0 uses a random seed.
+	Seed int64 `protobuf:"varint,13,opt,name=seed,proto3" json:"seed,omitempty"`
 }
 
 func (x *WorkerConfiguration) Reset() {
@@ -163,6 +234,20 @@ func (x *WorkerConfiguration) GetSinkHostPort() string {
 	return ""
 }
 
+func (x *WorkerConfiguration) GetSinkProvider() string {
+	if x != nil {
+		return x.SinkProvider
+	}
+	return ""
+}
+
+func (x *WorkerConfiguration) GetPropagation() string {
+	if x != nil {
+		return x.Propagation
+	}
+	return ""
+}
+
 func (x *WorkerConfiguration) GetUnits() []*Unit {
 	if x != nil {
 		return x.Units
@@ -170,27 +255,41 @@ func (x *WorkerConfiguration) GetUnits() []*Unit {
 	return nil
 }
 
-//Unit captures a request-response interaction with another emulated service.
-type Unit struct {
+func (x *WorkerConfiguration) GetLoadProfile() *LoadProfile {
+	if x != nil {
+		return x.LoadProfile
+	}
+	return nil
+}
+
+func (x *WorkerConfiguration) GetConcurrency() *Concurrency {
+	if x != nil {
+		return x.Concurrency
+	}
+	return nil
+}
+
+func (x *WorkerConfiguration) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+// Concurrency limits the number of concurrent invocations. If the limit is reached, the policy decides what happens to further invocations:
+// drop discards them, queue lets up to queue invocations wait for a free slot and discards the rest, block makes the generator wait for a free slot.
+type Concurrency struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Identifier string           `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
-	RelType    RelationshipType `protobuf:"varint,2,opt,name=rel_type,json=relType,proto3,enum=api.RelationshipType" json:"rel_type,omitempty"`
-	//This is sampled and waited for before the call is dispatched to the next worker listening at invoked_host_port.
-	WorkBefore *Work            `protobuf:"bytes,3,opt,name=work_before,json=workBefore,proto3" json:"work_before,omitempty"`
-	Context    *ContextTemplate `protobuf:"bytes,5,opt,name=context,proto3" json:"context,omitempty"`
-	Inputs     []*UnitRef       `protobuf:"bytes,6,rep,name=inputs,proto3" json:"inputs,omitempty"`
-	Successors []*UnitRef       `protobuf:"bytes,7,rep,name=successors,proto3" json:"successors,omitempty"`
-	//this ration indicates how throughput of the service is distributed across units. If this is 0, then the unit is not a "root" unit and consequently not actively generating load.
-	ThroughputRatio float64 `protobuf:"fixed64,8,opt,name=throughputRatio,proto3" json:"throughputRatio,omitempty"`
-	Sync            bool    `protobuf:"varint,9,opt,name=sync,proto3" json:"sync,omitempty"`
-	IsServer        bool    `protobuf:"varint,10,opt,name=isServer,proto3" json:"isServer,omitempty"`
+	Limit  int64  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Policy string `protobuf:"bytes,2,opt,name=policy,proto3" json:"policy,omitempty"`
+	Queue  int64  `protobuf:"varint,3,opt,name=queue,proto3" json:"queue,omitempty"`
 }
 
-func (x *Unit) Reset() {
-	*x = Unit{}
+func (x *Concurrency) Reset() {
+	*x = Concurrency{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_tracewriter_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -198,13 +297,13 @@ func (x *Unit) Reset() {
 	}
 }
 
-func (x *Unit) String() string {
+func (x *Concurrency) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Unit) ProtoMessage() {}
+func (*Concurrency) ProtoMessage() {}
 
-func (x *Unit) ProtoReflect() protoreflect.Message {
+func (x *Concurrency) ProtoReflect() protoreflect.Message {
 	mi := &file_api_tracewriter_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -216,88 +315,44 @@ func (x *Unit) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Unit.ProtoReflect.Descriptor instead.
-func (*Unit) Descriptor() ([]byte, []int) {
+// Deprecated: Use Concurrency.ProtoReflect.Descriptor instead.
+func (*Concurrency) Descriptor() ([]byte, []int) {
 	return file_api_tracewriter_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *Unit) GetIdentifier() string {
-	if x != nil {
-		return x.Identifier
-	}
-	return ""
-}
-
-func (x *Unit) GetRelType() RelationshipType {
-	if x != nil {
-		return x.RelType
-	}
-	return RelationshipType_CHILD
-}
-
-func (x *Unit) GetWorkBefore() *Work {
-	if x != nil {
-		return x.WorkBefore
-	}
-	return nil
-}
-
-func (x *Unit) GetContext() *ContextTemplate {
-	if x != nil {
-		return x.Context
-	}
-	return nil
-}
-
-func (x *Unit) GetInputs() []*UnitRef {
-	if x != nil {
-		return x.Inputs
-	}
-	return nil
-}
-
-func (x *Unit) GetSuccessors() []*UnitRef {
-	if x != nil {
-		return x.Successors
-	}
-	return nil
-}
-
-func (x *Unit) GetThroughputRatio() float64 {
+func (x *Concurrency) GetLimit() int64 {
 	if x != nil {
-		return x.ThroughputRatio
+		return x.Limit
 	}
 	return 0
 }
 
-func (x *Unit) GetSync() bool {
+func (x *Concurrency) GetPolicy() string {
 	if x != nil {
-		return x.Sync
+		return x.Policy
 	}
-	return false
+	return ""
 }
 
-func (x *Unit) GetIsServer() bool {
+func (x *Concurrency) GetQueue() int64 {
 	if x != nil {
-		return x.IsServer
+		return x.Queue
 	}
-	return false
+	return 0
 }
 
-type UnitRef struct {
+// LoadProfile is the baseline throughput over the time since the start of load generation. Throughput is interpolated linearly between points,
+// and the throughput of the last point is kept until the end of the run. Points with the same offset describe a jump.
+type LoadProfile struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ServiceId string `protobuf:"bytes,1,opt,name=serviceId,proto3" json:"serviceId,omitempty"`
-	UnitId    string `protobuf:"bytes,2,opt,name=unitId,proto3" json:"unitId,omitempty"`
-	Sync      bool   `protobuf:"varint,3,opt,name=sync,proto3" json:"sync,omitempty"`
-	IsRemote  bool   `protobuf:"varint,4,opt,name=isRemote,proto3" json:"isRemote,omitempty"`                //do we need this here? likely not, just look up the service by id from deployment?
-	HostPort  string `protobuf:"bytes,5,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"` //do we need this here? likely not, just look up the service by id from deployment?
+	Points []*LoadPoint `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
 }
 
-func (x *UnitRef) Reset() {
-	*x = UnitRef{}
+func (x *LoadProfile) Reset() {
+	*x = LoadProfile{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_tracewriter_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -305,13 +360,13 @@ func (x *UnitRef) Reset() {
 	}
 }
 
-func (x *UnitRef) String() string {
+func (x *LoadProfile) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UnitRef) ProtoMessage() {}
+func (*LoadProfile) ProtoMessage() {}
 
-func (x *UnitRef) ProtoReflect() protoreflect.Message {
+func (x *LoadProfile) ProtoReflect() protoreflect.Message {
 	mi := &file_api_tracewriter_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -323,57 +378,29 @@ func (x *UnitRef) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UnitRef.ProtoReflect.Descriptor instead.
-func (*UnitRef) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoadProfile.ProtoReflect.Descriptor instead.
+func (*LoadProfile) Descriptor() ([]byte, []int) {
 	return file_api_tracewriter_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *UnitRef) GetServiceId() string {
-	if x != nil {
-		return x.ServiceId
-	}
-	return ""
-}
-
-func (x *UnitRef) GetUnitId() string {
-	if x != nil {
-		return x.UnitId
-	}
-	return ""
-}
-
-func (x *UnitRef) GetSync() bool {
-	if x != nil {
-		return x.Sync
-	}
-	return false
-}
-
-func (x *UnitRef) GetIsRemote() bool {
-	if x != nil {
-		return x.IsRemote
-	}
-	return false
-}
-
-func (x *UnitRef) GetHostPort() string {
+func (x *LoadProfile) GetPoints() []*LoadPoint {
 	if x != nil {
-		return x.HostPort
+		return x.Points
 	}
-	return ""
+	return nil
 }
 
-type Work struct {
+type LoadPoint struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DistType   string             `protobuf:"bytes,1,opt,name=dist_type,json=distType,proto3" json:"dist_type,omitempty"`
-	Parameters map[string]float64 `protobuf:"bytes,2,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	OffsetSeconds float64 `protobuf:"fixed64,1,opt,name=offset_seconds,json=offsetSeconds,proto3" json:"offset_seconds,omitempty"`
+	Throughput    float64 `protobuf:"fixed64,2,opt,name=throughput,proto3" json:"throughput,omitempty"`
 }
 
-func (x *Work) Reset() {
-	*x = Work{}
+func (x *LoadPoint) Reset() {
+	*x = LoadPoint{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_tracewriter_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -381,13 +408,13 @@ func (x *Work) Reset() {
 	}
 }
 
-func (x *Work) String() string {
+func (x *LoadPoint) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Work) ProtoMessage() {}
+func (*LoadPoint) ProtoMessage() {}
 
-func (x *Work) ProtoReflect() protoreflect.Message {
+func (x *LoadPoint) ProtoReflect() protoreflect.Message {
 	mi := &file_api_tracewriter_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -399,38 +426,52 @@ func (x *Work) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Work.ProtoReflect.Descriptor instead.
-func (*Work) Descriptor() ([]byte, []int) {
+// Deprecated: Use LoadPoint.ProtoReflect.Descriptor instead.
+func (*LoadPoint) Descriptor() ([]byte, []int) {
 	return file_api_tracewriter_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *Work) GetDistType() string {
+func (x *LoadPoint) GetOffsetSeconds() float64 {
 	if x != nil {
-		return x.DistType
+		return x.OffsetSeconds
 	}
-	return ""
+	return 0
 }
 
-func (x *Work) GetParameters() map[string]float64 {
+func (x *LoadPoint) GetThroughput() float64 {
 	if x != nil {
-		return x.Parameters
+		return x.Throughput
 	}
-	return nil
+	return 0
 }
 
-type KeyValueTemplate struct {
+//Unit captures a request-response interaction with another emulated service.
+type Unit struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	KeyStatic   string `protobuf:"bytes,1,opt,name=key_static,json=keyStatic,proto3" json:"key_static,omitempty"`
-	KeyLength   int64  `protobuf:"varint,2,opt,name=key_length,json=keyLength,proto3" json:"key_length,omitempty"`
-	ValueStatic string `protobuf:"bytes,3,opt,name=value_static,json=valueStatic,proto3" json:"value_static,omitempty"`
-	ValueLength int64  `protobuf:"varint,4,opt,name=value_length,json=valueLength,proto3" json:"value_length,omitempty"`
+	Identifier string           `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	RelType    RelationshipType `protobuf:"varint,2,opt,name=rel_type,json=relType,proto3,enum=api.RelationshipType" json:"rel_type,omitempty"`
+	//This is sampled and waited for before the call is dispatched to the next worker listening at invoked_host_port.
+	WorkBefore *Work            `protobuf:"bytes,3,opt,name=work_before,json=workBefore,proto3" json:"work_before,omitempty"`
+	Context    *ContextTemplate `protobuf:"bytes,5,opt,name=context,proto3" json:"context,omitempty"`
+	Inputs     []*UnitRef       `protobuf:"bytes,6,rep,name=inputs,proto3" json:"inputs,omitempty"`
+	Successors []*UnitRef       `protobuf:"bytes,7,rep,name=successors,proto3" json:"successors,omitempty"`
+	//this ration indicates how throughput of the service is distributed across units. If this is 0, then the unit is not a "root" unit and consequently not actively generating load.
+	ThroughputRatio float64 `protobuf:"fixed64,8,opt,name=throughputRatio,proto3" json:"throughputRatio,omitempty"`
+	Sync            bool    `protobuf:"varint,9,opt,name=sync,proto3" json:"sync,omitempty"`
+	IsServer        bool    `protobuf:"varint,10,opt,name=isServer,proto3" json:"isServer,omitempty"`
+	//the arrival process of traces at a root unit, i.e. a unit with a throughputRatio above 0. Unset starts traces in fixed intervals.
+	Arrival *Arrival `protobuf:"bytes,11,opt,name=arrival,proto3" json:"arrival,omitempty"`
+	//virtual users invoke the unit in a closed loop instead of an arrival process. The throughputRatio of such units is 0.
+	Users *Users `protobuf:"bytes,12,opt,name=users,proto3" json:"users,omitempty"`
+	//limits the number of concurrent invocations of the unit started by its generator or by asynchronous calls. Unset doesn't limit the unit.
+	Concurrency *Concurrency `protobuf:"bytes,13,opt,name=concurrency,proto3" json:"concurrency,omitempty"`
 }
 
-func (x *KeyValueTemplate) Reset() {
-	*x = KeyValueTemplate{}
+func (x *Unit) Reset() {
+	*x = Unit{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_api_tracewriter_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -438,13 +479,13 @@ func (x *KeyValueTemplate) Reset() {
 	}
 }
 
-func (x *KeyValueTemplate) String() string {
+func (x *Unit) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*KeyValueTemplate) ProtoMessage() {}
+func (*Unit) ProtoMessage() {}
 
-func (x *KeyValueTemplate) ProtoReflect() protoreflect.Message {
+func (x *Unit) ProtoReflect() protoreflect.Message {
 	mi := &file_api_tracewriter_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -456,57 +497,567 @@ func (x *KeyValueTemplate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use KeyValueTemplate.ProtoReflect.Descriptor instead.
-func (*KeyValueTemplate) Descriptor() ([]byte, []int) {
+// Deprecated: Use Unit.ProtoReflect.Descriptor instead.
+func (*Unit) Descriptor() ([]byte, []int) {
 	return file_api_tracewriter_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *KeyValueTemplate) GetKeyStatic() string {
+func (x *Unit) GetIdentifier() string {
 	if x != nil {
-		return x.KeyStatic
+		return x.Identifier
 	}
 	return ""
 }
 
-func (x *KeyValueTemplate) GetKeyLength() int64 {
+func (x *Unit) GetRelType() RelationshipType {
 	if x != nil {
-		return x.KeyLength
+		return x.RelType
 	}
-	return 0
+	return RelationshipType_CHILD
 }
 
-func (x *KeyValueTemplate) GetValueStatic() string {
+func (x *Unit) GetWorkBefore() *Work {
 	if x != nil {
-		return x.ValueStatic
+		return x.WorkBefore
 	}
-	return ""
+	return nil
 }
 
-func (x *KeyValueTemplate) GetValueLength() int64 {
+func (x *Unit) GetContext() *ContextTemplate {
 	if x != nil {
-		return x.ValueLength
+		return x.Context
 	}
-	return 0
+	return nil
 }
 
-type Result struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *Unit) GetInputs() []*UnitRef {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
 
-	TraceId    []byte                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+func (x *Unit) GetSuccessors() []*UnitRef {
+	if x != nil {
+		return x.Successors
+	}
+	return nil
+}
+
+func (x *Unit) GetThroughputRatio() float64 {
+	if x != nil {
+		return x.ThroughputRatio
+	}
+	return 0
+}
+
+func (x *Unit) GetSync() bool {
+	if x != nil {
+		return x.Sync
+	}
+	return false
+}
+
+func (x *Unit) GetIsServer() bool {
+	if x != nil {
+		return x.IsServer
+	}
+	return false
+}
+
+func (x *Unit) GetArrival() *Arrival {
+	if x != nil {
+		return x.Arrival
+	}
+	return nil
+}
+
+func (x *Unit) GetUsers() *Users {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *Unit) GetConcurrency() *Concurrency {
+	if x != nil {
+		return x.Concurrency
+	}
+	return nil
+}
+
+// Users invoke a unit in a closed loop: each user invokes the unit, waits until its synchronous calls are finished, waits for a think time sampled from think_time and repeats.
+type Users struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Count     int64 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	ThinkTime *Work `protobuf:"bytes,2,opt,name=think_time,json=thinkTime,proto3" json:"think_time,omitempty"`
+}
+
+func (x *Users) Reset() {
+	*x = Users{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Users) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Users) ProtoMessage() {}
+
+func (x *Users) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Users.ProtoReflect.Descriptor instead.
+func (*Users) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Users) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Users) GetThinkTime() *Work {
+	if x != nil {
+		return x.ThinkTime
+	}
+	return nil
+}
+
+// Arrival selects an arrival process by type, e.g. constant, poisson, onoff, mmpp or diurnal, and its parameters.
+type Arrival struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type       string             `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Parameters map[string]float64 `protobuf:"bytes,2,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+}
+
+func (x *Arrival) Reset() {
+	*x = Arrival{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Arrival) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Arrival) ProtoMessage() {}
+
+func (x *Arrival) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Arrival.ProtoReflect.Descriptor instead.
+func (*Arrival) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Arrival) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Arrival) GetParameters() map[string]float64 {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+type UnitRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServiceId string `protobuf:"bytes,1,opt,name=serviceId,proto3" json:"serviceId,omitempty"`
+	UnitId    string `protobuf:"bytes,2,opt,name=unitId,proto3" json:"unitId,omitempty"`
+	Sync      bool   `protobuf:"varint,3,opt,name=sync,proto3" json:"sync,omitempty"`
+	IsRemote  bool   `protobuf:"varint,4,opt,name=isRemote,proto3" json:"isRemote,omitempty"`                //do we need this here? likely not, just look up the service by id from deployment?
+	HostPort  string `protobuf:"bytes,5,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"` //do we need this here? likely not, just look up the service by id from deployment?
+}
+
+func (x *UnitRef) Reset() {
+	*x = UnitRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnitRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnitRef) ProtoMessage() {}
+
+func (x *UnitRef) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnitRef.ProtoReflect.Descriptor instead.
+func (*UnitRef) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UnitRef) GetServiceId() string {
+	if x != nil {
+		return x.ServiceId
+	}
+	return ""
+}
+
+func (x *UnitRef) GetUnitId() string {
+	if x != nil {
+		return x.UnitId
+	}
+	return ""
+}
+
+func (x *UnitRef) GetSync() bool {
+	if x != nil {
+		return x.Sync
+	}
+	return false
+}
+
+func (x *UnitRef) GetIsRemote() bool {
+	if x != nil {
+		return x.IsRemote
+	}
+	return false
+}
+
+func (x *UnitRef) GetHostPort() string {
+	if x != nil {
+		return x.HostPort
+	}
+	return ""
+}
+
+type Work struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DistType   string             `protobuf:"bytes,1,opt,name=dist_type,json=distType,proto3" json:"dist_type,omitempty"`
+	Parameters map[string]float64 `protobuf:"bytes,2,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	//components of a mixture, i.e. each value is sampled from a component chosen by its weight
+	Components []*MixtureComponent `protobuf:"bytes,3,rep,name=components,proto3" json:"components,omitempty"`
+	//observed distribution of an empirical work type, which is sampled by inverting its CDF
+	Empirical *EmpiricalDistribution `protobuf:"bytes,4,opt,name=empirical,proto3" json:"empirical,omitempty"`
+}
+
+func (x *Work) Reset() {
+	*x = Work{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Work) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Work) ProtoMessage() {}
+
+func (x *Work) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Work.ProtoReflect.Descriptor instead.
+func (*Work) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Work) GetDistType() string {
+	if x != nil {
+		return x.DistType
+	}
+	return ""
+}
+
+func (x *Work) GetParameters() map[string]float64 {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+func (x *Work) GetComponents() []*MixtureComponent {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+func (x *Work) GetEmpirical() *EmpiricalDistribution {
+	if x != nil {
+		return x.Empirical
+	}
+	return nil
+}
+
+// EmpiricalDistribution is a piecewise linear CDF. Values are ascending, probabilities are the share of samples up to each value and ascend from 0 to 1.
+type EmpiricalDistribution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Values        []float64 `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	Probabilities []float64 `protobuf:"fixed64,2,rep,packed,name=probabilities,proto3" json:"probabilities,omitempty"`
+}
+
+func (x *EmpiricalDistribution) Reset() {
+	*x = EmpiricalDistribution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EmpiricalDistribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmpiricalDistribution) ProtoMessage() {}
+
+func (x *EmpiricalDistribution) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmpiricalDistribution.ProtoReflect.Descriptor instead.
+func (*EmpiricalDistribution) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *EmpiricalDistribution) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *EmpiricalDistribution) GetProbabilities() []float64 {
+	if x != nil {
+		return x.Probabilities
+	}
+	return nil
+}
+
+type MixtureComponent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Weight float64 `protobuf:"fixed64,1,opt,name=weight,proto3" json:"weight,omitempty"`
+	Work   *Work   `protobuf:"bytes,2,opt,name=work,proto3" json:"work,omitempty"`
+}
+
+func (x *MixtureComponent) Reset() {
+	*x = MixtureComponent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MixtureComponent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MixtureComponent) ProtoMessage() {}
+
+func (x *MixtureComponent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MixtureComponent.ProtoReflect.Descriptor instead.
+func (*MixtureComponent) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MixtureComponent) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *MixtureComponent) GetWork() *Work {
+	if x != nil {
+		return x.Work
+	}
+	return nil
+}
+
+type KeyValueTemplate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyStatic   string `protobuf:"bytes,1,opt,name=key_static,json=keyStatic,proto3" json:"key_static,omitempty"`
+	KeyLength   int64  `protobuf:"varint,2,opt,name=key_length,json=keyLength,proto3" json:"key_length,omitempty"`
+	ValueStatic string `protobuf:"bytes,3,opt,name=value_static,json=valueStatic,proto3" json:"value_static,omitempty"`
+	ValueLength int64  `protobuf:"varint,4,opt,name=value_length,json=valueLength,proto3" json:"value_length,omitempty"`
+}
+
+func (x *KeyValueTemplate) Reset() {
+	*x = KeyValueTemplate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyValueTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyValueTemplate) ProtoMessage() {}
+
+func (x *KeyValueTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyValueTemplate.ProtoReflect.Descriptor instead.
+func (*KeyValueTemplate) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *KeyValueTemplate) GetKeyStatic() string {
+	if x != nil {
+		return x.KeyStatic
+	}
+	return ""
+}
+
+func (x *KeyValueTemplate) GetKeyLength() int64 {
+	if x != nil {
+		return x.KeyLength
+	}
+	return 0
+}
+
+func (x *KeyValueTemplate) GetValueStatic() string {
+	if x != nil {
+		return x.ValueStatic
+	}
+	return ""
+}
+
+func (x *KeyValueTemplate) GetValueLength() int64 {
+	if x != nil {
+		return x.ValueLength
+	}
+	return 0
+}
+
+type Result struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TraceId    []byte                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
 	TraceNum   int64                  `protobuf:"varint,2,opt,name=trace_num,json=traceNum,proto3" json:"trace_num,omitempty"`
 	SpanId     []byte                 `protobuf:"bytes,3,opt,name=span_id,json=spanId,proto3" json:"span_id,omitempty"`
 	SpanNum    int64                  `protobuf:"varint,4,opt,name=span_num,json=spanNum,proto3" json:"span_num,omitempty"`
 	StartTime  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
 	FinishTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=finish_time,json=finishTime,proto3" json:"finish_time,omitempty"`
 	Sampled    bool                   `protobuf:"varint,7,opt,name=sampled,proto3" json:"sampled,omitempty"`
+	//empty for root spans
+	ParentId []byte `protobuf:"bytes,8,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	//gRPC status code of the span, i.e. 0 (OK) unless the unit or one of its synchronous calls to successors failed
+	StatusCode uint32 `protobuf:"varint,9,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	//message of the first error, empty if status_code is 0
+	Error string `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	//identifier of the unit which created the span
+	Unit string `protobuf:"bytes,11,opt,name=unit,proto3" json:"unit,omitempty"`
 }
 
 func (x *Result) Reset() {
 	*x = Result{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_tracewriter_proto_msgTypes[5]
+		mi := &file_api_tracewriter_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -519,7 +1070,7 @@ func (x *Result) String() string {
 func (*Result) ProtoMessage() {}
 
 func (x *Result) ProtoReflect() protoreflect.Message {
-	mi := &file_api_tracewriter_proto_msgTypes[5]
+	mi := &file_api_tracewriter_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -532,7 +1083,7 @@ func (x *Result) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Result.ProtoReflect.Descriptor instead.
 func (*Result) Descriptor() ([]byte, []int) {
-	return file_api_tracewriter_proto_rawDescGZIP(), []int{5}
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Result) GetTraceId() []byte {
@@ -584,33 +1135,272 @@ func (x *Result) GetSampled() bool {
 	return false
 }
 
-type ContextTemplate struct {
+func (x *Result) GetParentId() []byte {
+	if x != nil {
+		return x.ParentId
+	}
+	return nil
+}
+
+func (x *Result) GetStatusCode() uint32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *Result) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Result) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+type ContextTemplate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tags    []*KeyValueTemplate `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	Logs    []*KeyValueTemplate `protobuf:"bytes,2,rep,name=logs,proto3" json:"logs,omitempty"`
+	Baggage []*KeyValueTemplate `protobuf:"bytes,3,rep,name=baggage,proto3" json:"baggage,omitempty"`
+}
+
+func (x *ContextTemplate) Reset() {
+	*x = ContextTemplate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ContextTemplate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContextTemplate) ProtoMessage() {}
+
+func (x *ContextTemplate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContextTemplate.ProtoReflect.Descriptor instead.
+func (*ContextTemplate) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ContextTemplate) GetTags() []*KeyValueTemplate {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ContextTemplate) GetLogs() []*KeyValueTemplate {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *ContextTemplate) GetBaggage() []*KeyValueTemplate {
+	if x != nil {
+		return x.Baggage
+	}
+	return nil
+}
+
+type ResultPackage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WorkerId      string    `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	EnvironmentId string    `protobuf:"bytes,2,opt,name=environment_id,json=environmentId,proto3" json:"environment_id,omitempty"`
+	Results       []*Result `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *ResultPackage) Reset() {
+	*x = ResultPackage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResultPackage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultPackage) ProtoMessage() {}
+
+func (x *ResultPackage) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultPackage.ProtoReflect.Descriptor instead.
+func (*ResultPackage) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ResultPackage) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *ResultPackage) GetEnvironmentId() string {
+	if x != nil {
+		return x.EnvironmentId
+	}
+	return ""
+}
+
+func (x *ResultPackage) GetResults() []*Result {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type DispatchId struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UnitReference string `protobuf:"bytes,1,opt,name=unitReference,proto3" json:"unitReference,omitempty"`
+}
+
+func (x *DispatchId) Reset() {
+	*x = DispatchId{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DispatchId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DispatchId) ProtoMessage() {}
+
+func (x *DispatchId) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DispatchId.ProtoReflect.Descriptor instead.
+func (*DispatchId) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DispatchId) GetUnitReference() string {
+	if x != nil {
+		return x.UnitReference
+	}
+	return ""
+}
+
+type Empty struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_tracewriter_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{16}
+}
+
+type StartRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Tags    []*KeyValueTemplate `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
-	Logs    []*KeyValueTemplate `protobuf:"bytes,2,rep,name=logs,proto3" json:"logs,omitempty"`
-	Baggage []*KeyValueTemplate `protobuf:"bytes,3,rep,name=baggage,proto3" json:"baggage,omitempty"`
+	WorkerId string `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	//wall-clock time at which load generation begins, so all workers start at the same instant. Unset starts immediately.
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
 }
 
-func (x *ContextTemplate) Reset() {
-	*x = ContextTemplate{}
+func (x *StartRequest) Reset() {
+	*x = StartRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_tracewriter_proto_msgTypes[6]
+		mi := &file_api_tracewriter_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ContextTemplate) String() string {
+func (x *StartRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ContextTemplate) ProtoMessage() {}
+func (*StartRequest) ProtoMessage() {}
 
-func (x *ContextTemplate) ProtoReflect() protoreflect.Message {
-	mi := &file_api_tracewriter_proto_msgTypes[6]
+func (x *StartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -621,59 +1411,52 @@ func (x *ContextTemplate) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ContextTemplate.ProtoReflect.Descriptor instead.
-func (*ContextTemplate) Descriptor() ([]byte, []int) {
-	return file_api_tracewriter_proto_rawDescGZIP(), []int{6}
-}
-
-func (x *ContextTemplate) GetTags() []*KeyValueTemplate {
-	if x != nil {
-		return x.Tags
-	}
-	return nil
+// Deprecated: Use StartRequest.ProtoReflect.Descriptor instead.
+func (*StartRequest) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *ContextTemplate) GetLogs() []*KeyValueTemplate {
+func (x *StartRequest) GetWorkerId() string {
 	if x != nil {
-		return x.Logs
+		return x.WorkerId
 	}
-	return nil
+	return ""
 }
 
-func (x *ContextTemplate) GetBaggage() []*KeyValueTemplate {
+func (x *StartRequest) GetStartTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Baggage
+		return x.StartTime
 	}
 	return nil
 }
 
-type ResultPackage struct {
+type StopRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	WorkerId      string    `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
-	EnvironmentId string    `protobuf:"bytes,2,opt,name=environment_id,json=environmentId,proto3" json:"environment_id,omitempty"`
-	Results       []*Result `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	//aborted runs discard results which weren't reported yet
+	Abort  bool   `protobuf:"varint,1,opt,name=abort,proto3" json:"abort,omitempty"`
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
 }
 
-func (x *ResultPackage) Reset() {
-	*x = ResultPackage{}
+func (x *StopRequest) Reset() {
+	*x = StopRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_tracewriter_proto_msgTypes[7]
+		mi := &file_api_tracewriter_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ResultPackage) String() string {
+func (x *StopRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResultPackage) ProtoMessage() {}
+func (*StopRequest) ProtoMessage() {}
 
-func (x *ResultPackage) ProtoReflect() protoreflect.Message {
-	mi := &file_api_tracewriter_proto_msgTypes[7]
+func (x *StopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -684,57 +1467,66 @@ func (x *ResultPackage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResultPackage.ProtoReflect.Descriptor instead.
-func (*ResultPackage) Descriptor() ([]byte, []int) {
-	return file_api_tracewriter_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use StopRequest.ProtoReflect.Descriptor instead.
+func (*StopRequest) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *ResultPackage) GetWorkerId() string {
+func (x *StopRequest) GetAbort() bool {
 	if x != nil {
-		return x.WorkerId
+		return x.Abort
 	}
-	return ""
+	return false
 }
 
-func (x *ResultPackage) GetEnvironmentId() string {
+func (x *StopRequest) GetReason() string {
 	if x != nil {
-		return x.EnvironmentId
+		return x.Reason
 	}
 	return ""
 }
 
-func (x *ResultPackage) GetResults() []*Result {
-	if x != nil {
-		return x.Results
-	}
-	return nil
-}
-
-type DispatchId struct {
+type WorkerStatus struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	UnitReference string `protobuf:"bytes,1,opt,name=unitReference,proto3" json:"unitReference,omitempty"`
-}
-
-func (x *DispatchId) Reset() {
-	*x = DispatchId{}
+	WorkerId string      `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	State    WorkerState `protobuf:"varint,2,opt,name=state,proto3,enum=api.WorkerState" json:"state,omitempty"`
+	//number of results sent to the coordinator in the current run
+	ResultsReported int64 `protobuf:"varint,3,opt,name=results_reported,json=resultsReported,proto3" json:"results_reported,omitempty"`
+	//set if the state is FAILED
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	//a prepared worker is ready once it is connected to all remote successors
+	Ready bool `protobuf:"varint,5,opt,name=ready,proto3" json:"ready,omitempty"`
+	//services of remote successors which the worker isn't connected to yet
+	PendingSuccessors []string `protobuf:"bytes,6,rep,name=pending_successors,json=pendingSuccessors,proto3" json:"pending_successors,omitempty"`
+	//sampling settings of the worker, which are set when the worker is started and apply to all runs
+	SamplingStrategy string  `protobuf:"bytes,7,opt,name=sampling_strategy,json=samplingStrategy,proto3" json:"sampling_strategy,omitempty"`
+	SamplingParam    float64 `protobuf:"fixed64,8,opt,name=sampling_param,json=samplingParam,proto3" json:"sampling_param,omitempty"`
+	Version          string  `protobuf:"bytes,9,opt,name=version,proto3" json:"version,omitempty"`
+	Hostname         string  `protobuf:"bytes,10,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	//invocations per unit dropped in the current run, because a concurrency limit was reached
+	DroppedInvocations map[string]int64 `protobuf:"bytes,11,rep,name=dropped_invocations,json=droppedInvocations,proto3" json:"dropped_invocations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *WorkerStatus) Reset() {
+	*x = WorkerStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_tracewriter_proto_msgTypes[8]
+		mi := &file_api_tracewriter_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DispatchId) String() string {
+func (x *WorkerStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DispatchId) ProtoMessage() {}
+func (*WorkerStatus) ProtoMessage() {}
 
-func (x *DispatchId) ProtoReflect() protoreflect.Message {
-	mi := &file_api_tracewriter_proto_msgTypes[8]
+func (x *WorkerStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tracewriter_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -745,54 +1537,86 @@ func (x *DispatchId) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DispatchId.ProtoReflect.Descriptor instead.
-func (*DispatchId) Descriptor() ([]byte, []int) {
-	return file_api_tracewriter_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use WorkerStatus.ProtoReflect.Descriptor instead.
+func (*WorkerStatus) Descriptor() ([]byte, []int) {
+	return file_api_tracewriter_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *DispatchId) GetUnitReference() string {
+func (x *WorkerStatus) GetWorkerId() string {
 	if x != nil {
-		return x.UnitReference
+		return x.WorkerId
 	}
 	return ""
 }
 
-type Empty struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
-	unknownFields protoimpl.UnknownFields
+func (x *WorkerStatus) GetState() WorkerState {
+	if x != nil {
+		return x.State
+	}
+	return WorkerState_IDLE
 }
 
-func (x *Empty) Reset() {
-	*x = Empty{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_api_tracewriter_proto_msgTypes[9]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
+func (x *WorkerStatus) GetResultsReported() int64 {
+	if x != nil {
+		return x.ResultsReported
 	}
+	return 0
 }
 
-func (x *Empty) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *WorkerStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
 }
 
-func (*Empty) ProtoMessage() {}
+func (x *WorkerStatus) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
 
-func (x *Empty) ProtoReflect() protoreflect.Message {
-	mi := &file_api_tracewriter_proto_msgTypes[9]
-	if protoimpl.UnsafeEnabled && x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+func (x *WorkerStatus) GetPendingSuccessors() []string {
+	if x != nil {
+		return x.PendingSuccessors
 	}
-	return mi.MessageOf(x)
+	return nil
 }
 
-// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
-func (*Empty) Descriptor() ([]byte, []int) {
-	return file_api_tracewriter_proto_rawDescGZIP(), []int{9}
+func (x *WorkerStatus) GetSamplingStrategy() string {
+	if x != nil {
+		return x.SamplingStrategy
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetSamplingParam() float64 {
+	if x != nil {
+		return x.SamplingParam
+	}
+	return 0
+}
+
+func (x *WorkerStatus) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *WorkerStatus) GetDroppedInvocations() map[string]int64 {
+	if x != nil {
+		return x.DroppedInvocations
+	}
+	return nil
 }
 
 var File_api_tracewriter_proto protoreflect.FileDescriptor
@@ -801,7 +1625,7 @@ var file_api_tracewriter_proto_rawDesc = []byte{
 	0x0a, 0x15, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x72, 0x61, 0x63, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65,
 	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x61, 0x70, 0x69, 0x1a, 0x1f, 0x67, 0x6f,
 	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
-	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf2, 0x01,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb6, 0x03,
 	0x0a, 0x13, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
 	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x5f,
 	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72,
@@ -815,113 +1639,247 @@ var file_api_tracewriter_proto_rawDesc = []byte{
 	0x74, 0x54, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x73,
 	0x69, 0x6e, 0x6b, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x06, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x69, 0x6e, 0x6b, 0x48, 0x6f, 0x73, 0x74, 0x50, 0x6f, 0x72,
-	0x74, 0x12, 0x1f, 0x0a, 0x05, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x05, 0x75, 0x6e, 0x69,
-	0x74, 0x73, 0x22, 0xe2, 0x02, 0x0a, 0x04, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x69,
-	0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x30, 0x0a, 0x08, 0x72,
-	0x65, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e,
-	0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70,
-	0x54, 0x79, 0x70, 0x65, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x2a, 0x0a,
-	0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x0a, 0x77,
-	0x6f, 0x72, 0x6b, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x63, 0x6f, 0x6e,
-	0x74, 0x65, 0x78, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x69,
-	0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
-	0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x69, 0x6e, 0x70,
-	0x75, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x66, 0x52, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x12,
-	0x2c, 0x0a, 0x0a, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x73, 0x18, 0x07, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65,
-	0x66, 0x52, 0x0a, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x73, 0x12, 0x28, 0x0a,
-	0x0f, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f,
-	0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70,
-	0x75, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x18,
-	0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x1a, 0x0a, 0x08, 0x69,
-	0x73, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69,
-	0x73, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x22, 0x8c, 0x01, 0x0a, 0x07, 0x55, 0x6e, 0x69, 0x74,
-	0x52, 0x65, 0x66, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x49,
-	0x64, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e, 0x69, 0x74, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x06, 0x75, 0x6e, 0x69, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x79, 0x6e,
-	0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x1a, 0x0a,
-	0x08, 0x69, 0x73, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x08, 0x69, 0x73, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x6f, 0x73,
-	0x74, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f,
-	0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x22, 0x9d, 0x01, 0x0a, 0x04, 0x57, 0x6f, 0x72, 0x6b, 0x12,
-	0x1b, 0x0a, 0x09, 0x64, 0x69, 0x73, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x39, 0x0a, 0x0a,
-	0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x19, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x2e, 0x50, 0x61, 0x72, 0x61,
-	0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x70, 0x61, 0x72,
-	0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x1a, 0x3d, 0x0a, 0x0f, 0x50, 0x61, 0x72, 0x61, 0x6d,
-	0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
-	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x96, 0x01, 0x0a, 0x10, 0x4b, 0x65, 0x79, 0x56, 0x61,
-	0x6c, 0x75, 0x65, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6b,
-	0x65, 0x79, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x6b, 0x65, 0x79, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65,
-	0x79, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
-	0x6b, 0x65, 0x79, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x69, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x12, 0x21, 0x0a, 0x0c,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x0b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x22,
-	0x86, 0x02, 0x0a, 0x06, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72,
-	0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x72,
-	0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x6e,
-	0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x74, 0x72, 0x61, 0x63, 0x65, 0x4e,
-	0x75, 0x6d, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x70, 0x61, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x70, 0x61, 0x6e, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x73,
-	0x70, 0x61, 0x6e, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73,
-	0x70, 0x61, 0x6e, 0x4e, 0x75, 0x6d, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f,
-	0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d,
-	0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x5f, 0x74, 0x69, 0x6d, 0x65,
-	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
-	0x6d, 0x70, 0x52, 0x0a, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18,
-	0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x64, 0x22, 0x98, 0x01, 0x0a, 0x0f, 0x43, 0x6f, 0x6e,
-	0x74, 0x65, 0x78, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x04,
-	0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69,
-	0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
-	0x65, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x29, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4b, 0x65, 0x79, 0x56,
-	0x61, 0x6c, 0x75, 0x65, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x04, 0x6c, 0x6f,
-	0x67, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x62, 0x61, 0x67, 0x67, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x07, 0x62, 0x61, 0x67, 0x67,
-	0x61, 0x67, 0x65, 0x22, 0x7a, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x50, 0x61, 0x63,
-	0x6b, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x5f, 0x69,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x49,
-	0x64, 0x12, 0x25, 0x0a, 0x0e, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74,
-	0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x65, 0x6e, 0x76, 0x69, 0x72,
-	0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75,
-	0x6c, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22,
-	0x32, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x49, 0x64, 0x12, 0x24, 0x0a,
-	0x0d, 0x75, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x75, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65,
-	0x6e, 0x63, 0x65, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x2a, 0x6c, 0x0a, 0x10,
-	0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68, 0x69, 0x70, 0x54, 0x79, 0x70, 0x65,
-	0x12, 0x09, 0x0a, 0x05, 0x43, 0x48, 0x49, 0x4c, 0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x46,
-	0x4f, 0x4c, 0x4c, 0x4f, 0x57, 0x53, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x45, 0x52, 0x56,
-	0x45, 0x52, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x10, 0x03,
-	0x12, 0x0c, 0x0a, 0x08, 0x50, 0x52, 0x4f, 0x44, 0x55, 0x43, 0x45, 0x52, 0x10, 0x04, 0x12, 0x0c,
-	0x0a, 0x08, 0x43, 0x4f, 0x4e, 0x53, 0x55, 0x4d, 0x45, 0x52, 0x10, 0x05, 0x12, 0x0c, 0x0a, 0x08,
-	0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0x06, 0x32, 0x79, 0x0a, 0x0f, 0x42, 0x65,
-	0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x3f, 0x0a,
-	0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x18, 0x2e, 0x61,
-	0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73,
-	0x75, 0x6c, 0x74, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x25,
-	0x0a, 0x04, 0x43, 0x61, 0x6c, 0x6c, 0x12, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x69, 0x73,
-	0x70, 0x61, 0x74, 0x63, 0x68, 0x49, 0x64, 0x1a, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x6d,
-	0x70, 0x74, 0x79, 0x22, 0x00, 0x42, 0x05, 0x5a, 0x03, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x69, 0x6e, 0x6b, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64,
+	0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x69, 0x6e, 0x6b, 0x50, 0x72,
+	0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x70, 0x72, 0x6f, 0x70, 0x61, 0x67,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x6f,
+	0x70, 0x61, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x05, 0x75, 0x6e, 0x69, 0x74,
+	0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x6e,
+	0x69, 0x74, 0x52, 0x05, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x33, 0x0a, 0x0c, 0x6c, 0x6f, 0x61,
+	0x64, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x52, 0x0b, 0x6c, 0x6f, 0x61, 0x64, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x32,
+	0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x63, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x04, 0x73, 0x65, 0x65, 0x64, 0x22, 0x51, 0x0a, 0x0b, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x71, 0x75, 0x65, 0x75, 0x65, 0x22, 0x35, 0x0a, 0x0b, 0x4c, 0x6f, 0x61,
+	0x64, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4c,
+	0x6f, 0x61, 0x64, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73,
+	0x22, 0x52, 0x0a, 0x09, 0x4c, 0x6f, 0x61, 0x64, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x25, 0x0a,
+	0x0e, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70,
+	0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67,
+	0x68, 0x70, 0x75, 0x74, 0x22, 0xe0, 0x03, 0x0a, 0x04, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x1e, 0x0a,
+	0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x30, 0x0a,
+	0x08, 0x72, 0x65, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x68,
+	0x69, 0x70, 0x54, 0x79, 0x70, 0x65, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x2a, 0x0a, 0x0b, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x52,
+	0x0a, 0x77, 0x6f, 0x72, 0x6b, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x24, 0x0a, 0x06, 0x69,
+	0x6e, 0x70, 0x75, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x66, 0x52, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74,
+	0x73, 0x12, 0x2c, 0x0a, 0x0a, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x73, 0x18,
+	0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x6e, 0x69, 0x74,
+	0x52, 0x65, 0x66, 0x52, 0x0a, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x73, 0x12,
+	0x28, 0x0a, 0x0f, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x52, 0x61, 0x74,
+	0x69, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67,
+	0x68, 0x70, 0x75, 0x74, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x79, 0x6e,
+	0x63, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x12, 0x1a, 0x0a,
+	0x08, 0x69, 0x73, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x69, 0x73, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x07, 0x61, 0x72, 0x72,
+	0x69, 0x76, 0x61, 0x6c, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x52, 0x07, 0x61, 0x72, 0x72, 0x69, 0x76, 0x61,
+	0x6c, 0x12, 0x20, 0x0a, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x73, 0x52, 0x05, 0x75, 0x73,
+	0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x63, 0x79, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x43,
+	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x22, 0x47, 0x0a, 0x05, 0x55, 0x73, 0x65, 0x72, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x28, 0x0a, 0x0a, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x09, 0x74, 0x68, 0x69, 0x6e, 0x6b, 0x54, 0x69, 0x6d, 0x65,
+	0x22, 0x9a, 0x01, 0x0a, 0x07, 0x41, 0x72, 0x72, 0x69, 0x76, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x3c, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x41, 0x72, 0x72, 0x69, 0x76,
+	0x61, 0x6c, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x1a, 0x3d,
+	0x0a, 0x0f, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x8c, 0x01,
+	0x0a, 0x07, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x66, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x6e, 0x69, 0x74, 0x49,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x6e, 0x69, 0x74, 0x49, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x79, 0x6e, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x73,
+	0x79, 0x6e, 0x63, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x73, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x73, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x22, 0x8e, 0x02, 0x0a,
+	0x04, 0x57, 0x6f, 0x72, 0x6b, 0x12, 0x1b, 0x0a, 0x09, 0x64, 0x69, 0x73, 0x74, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x69, 0x73, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72,
+	0x6b, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x35, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4d, 0x69, 0x78, 0x74, 0x75, 0x72, 0x65, 0x43,
+	0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x6f, 0x6e,
+	0x65, 0x6e, 0x74, 0x73, 0x12, 0x38, 0x0a, 0x09, 0x65, 0x6d, 0x70, 0x69, 0x72, 0x69, 0x63, 0x61,
+	0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x6d,
+	0x70, 0x69, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x09, 0x65, 0x6d, 0x70, 0x69, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x1a, 0x3d,
+	0x0a, 0x0f, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x55, 0x0a,
+	0x15, 0x45, 0x6d, 0x70, 0x69, 0x72, 0x69, 0x63, 0x61, 0x6c, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x01, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12, 0x24,
+	0x0a, 0x0d, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x01, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x62, 0x61, 0x62, 0x69, 0x6c, 0x69,
+	0x74, 0x69, 0x65, 0x73, 0x22, 0x49, 0x0a, 0x10, 0x4d, 0x69, 0x78, 0x74, 0x75, 0x72, 0x65, 0x43,
+	0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x12, 0x1d, 0x0a, 0x04, 0x77, 0x6f, 0x72, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x52, 0x04, 0x77, 0x6f, 0x72, 0x6b, 0x22,
+	0x96, 0x01, 0x0a, 0x10, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x65, 0x6d, 0x70,
+	0x6c, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x53, 0x74, 0x61,
+	0x74, 0x69, 0x63, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x4c, 0x65, 0x6e, 0x67,
+	0x74, 0x68, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x53,
+	0x74, 0x61, 0x74, 0x69, 0x63, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x6c,
+	0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x22, 0xee, 0x02, 0x0a, 0x06, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12, 0x1b,
+	0x0a, 0x09, 0x74, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x74, 0x72, 0x61, 0x63, 0x65, 0x4e, 0x75, 0x6d, 0x12, 0x17, 0x0a, 0x07, 0x73,
+	0x70, 0x61, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x70,
+	0x61, 0x6e, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x73, 0x70, 0x61, 0x6e, 0x5f, 0x6e, 0x75, 0x6d,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x70, 0x61, 0x6e, 0x4e, 0x75, 0x6d, 0x12,
+	0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x66, 0x69,
+	0x6e, 0x69, 0x73, 0x68, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x66, 0x69, 0x6e,
+	0x69, 0x73, 0x68, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+	0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x6e, 0x69, 0x74, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x6e, 0x69, 0x74, 0x22, 0x98, 0x01, 0x0a, 0x0f, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a,
+	0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x29, 0x0a, 0x04, 0x6c, 0x6f, 0x67, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4b, 0x65, 0x79,
+	0x56, 0x61, 0x6c, 0x75, 0x65, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x04, 0x6c,
+	0x6f, 0x67, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x62, 0x61, 0x67, 0x67, 0x61, 0x67, 0x65, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x4b, 0x65, 0x79, 0x56, 0x61,
+	0x6c, 0x75, 0x65, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x07, 0x62, 0x61, 0x67,
+	0x67, 0x61, 0x67, 0x65, 0x22, 0x7a, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x50, 0x61,
+	0x63, 0x6b, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x65, 0x6e, 0x76, 0x69, 0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x65, 0x6e, 0x76, 0x69,
+	0x72, 0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x07, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73,
+	0x22, 0x32, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x49, 0x64, 0x12, 0x24,
+	0x0a, 0x0d, 0x75, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x75, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x66, 0x65, 0x72,
+	0x65, 0x6e, 0x63, 0x65, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x66, 0x0a,
+	0x0c, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a,
+	0x09, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x3b, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x62, 0x6f, 0x72, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x22, 0x86, 0x04, 0x0a, 0x0c, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x49, 0x64,
+	0x12, 0x26, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x73, 0x5f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x61,
+	0x64, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x72, 0x65, 0x61, 0x64, 0x79, 0x12,
+	0x2d, 0x0a, 0x12, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x75, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x6f, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11, 0x70, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x6f, 0x72, 0x73, 0x12, 0x2b,
+	0x0a, 0x11, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x74, 0x72, 0x61, 0x74,
+	0x65, 0x67, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x73, 0x61, 0x6d, 0x70, 0x6c,
+	0x69, 0x6e, 0x67, 0x53, 0x74, 0x72, 0x61, 0x74, 0x65, 0x67, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0d, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x5a, 0x0a, 0x13, 0x64, 0x72, 0x6f, 0x70,
+	0x70, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64,
+	0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x12, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x49, 0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x45, 0x0a, 0x17, 0x44, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x49,
+	0x6e, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x67, 0x0a, 0x0b, 0x57,
+	0x6f, 0x72, 0x6b, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x49, 0x44,
+	0x4c, 0x45, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x52, 0x45, 0x50, 0x41, 0x52, 0x45, 0x44,
+	0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12,
+	0x0c, 0x0a, 0x08, 0x53, 0x54, 0x4f, 0x50, 0x50, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x12, 0x0c, 0x0a,
+	0x08, 0x46, 0x49, 0x4e, 0x49, 0x53, 0x48, 0x45, 0x44, 0x10, 0x04, 0x12, 0x0b, 0x0a, 0x07, 0x41,
+	0x42, 0x4f, 0x52, 0x54, 0x45, 0x44, 0x10, 0x05, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c,
+	0x45, 0x44, 0x10, 0x06, 0x2a, 0x6c, 0x0a, 0x10, 0x52, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x68, 0x69, 0x70, 0x54, 0x79, 0x70, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x43, 0x48, 0x49, 0x4c,
+	0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x46, 0x4f, 0x4c, 0x4c, 0x4f, 0x57, 0x53, 0x10, 0x01,
+	0x12, 0x0a, 0x0a, 0x06, 0x53, 0x45, 0x52, 0x56, 0x45, 0x52, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06,
+	0x43, 0x4c, 0x49, 0x45, 0x4e, 0x54, 0x10, 0x03, 0x12, 0x0c, 0x0a, 0x08, 0x50, 0x52, 0x4f, 0x44,
+	0x55, 0x43, 0x45, 0x52, 0x10, 0x04, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x4f, 0x4e, 0x53, 0x55, 0x4d,
+	0x45, 0x52, 0x10, 0x05, 0x12, 0x0c, 0x0a, 0x08, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c,
+	0x10, 0x06, 0x32, 0xc1, 0x02, 0x0a, 0x0f, 0x42, 0x65, 0x6e, 0x63, 0x68, 0x6d, 0x61, 0x72, 0x6b,
+	0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x3f, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x57,
+	0x6f, 0x72, 0x6b, 0x65, 0x72, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b,
+	0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a,
+	0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x50, 0x61, 0x63, 0x6b,
+	0x61, 0x67, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x25, 0x0a, 0x04, 0x43, 0x61, 0x6c, 0x6c, 0x12,
+	0x0f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x44, 0x69, 0x73, 0x70, 0x61, 0x74, 0x63, 0x68, 0x49, 0x64,
+	0x1a, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x38,
+	0x0a, 0x07, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x00, 0x12, 0x32, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x72,
+	0x74, 0x12, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x50, 0x61, 0x63, 0x6b, 0x61, 0x67, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x2d, 0x0a, 0x04,
+	0x53, 0x74, 0x6f, 0x70, 0x12, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72,
+	0x6b, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x00, 0x12, 0x29, 0x0a, 0x06, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0a, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x1a, 0x11, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x22, 0x00, 0x42, 0x05, 0x5a, 0x03, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -936,46 +1894,81 @@ func file_api_tracewriter_proto_rawDescGZIP() []byte {
 	return file_api_tracewriter_proto_rawDescData
 }
 
-var file_api_tracewriter_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_api_tracewriter_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_api_tracewriter_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_api_tracewriter_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
 var file_api_tracewriter_proto_goTypes = []interface{}{
-	(RelationshipType)(0),         // 0: api.RelationshipType
-	(*WorkerConfiguration)(nil),   // 1: api.WorkerConfiguration
-	(*Unit)(nil),                  // 2: api.Unit
-	(*UnitRef)(nil),               // 3: api.UnitRef
-	(*Work)(nil),                  // 4: api.Work
-	(*KeyValueTemplate)(nil),      // 5: api.KeyValueTemplate
-	(*Result)(nil),                // 6: api.Result
-	(*ContextTemplate)(nil),       // 7: api.ContextTemplate
-	(*ResultPackage)(nil),         // 8: api.ResultPackage
-	(*DispatchId)(nil),            // 9: api.DispatchId
-	(*Empty)(nil),                 // 10: api.Empty
-	nil,                           // 11: api.Work.ParametersEntry
-	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+	(WorkerState)(0),              // 0: api.WorkerState
+	(RelationshipType)(0),         // 1: api.RelationshipType
+	(*WorkerConfiguration)(nil),   // 2: api.WorkerConfiguration
+	(*Concurrency)(nil),           // 3: api.Concurrency
+	(*LoadProfile)(nil),           // 4: api.LoadProfile
+	(*LoadPoint)(nil),             // 5: api.LoadPoint
+	(*Unit)(nil),                  // 6: api.Unit
+	(*Users)(nil),                 // 7: api.Users
+	(*Arrival)(nil),               // 8: api.Arrival
+	(*UnitRef)(nil),               // 9: api.UnitRef
+	(*Work)(nil),                  // 10: api.Work
+	(*EmpiricalDistribution)(nil), // 11: api.EmpiricalDistribution
+	(*MixtureComponent)(nil),      // 12: api.MixtureComponent
+	(*KeyValueTemplate)(nil),      // 13: api.KeyValueTemplate
+	(*Result)(nil),                // 14: api.Result
+	(*ContextTemplate)(nil),       // 15: api.ContextTemplate
+	(*ResultPackage)(nil),         // 16: api.ResultPackage
+	(*DispatchId)(nil),            // 17: api.DispatchId
+	(*Empty)(nil),                 // 18: api.Empty
+	(*StartRequest)(nil),          // 19: api.StartRequest
+	(*StopRequest)(nil),           // 20: api.StopRequest
+	(*WorkerStatus)(nil),          // 21: api.WorkerStatus
+	nil,                           // 22: api.Arrival.ParametersEntry
+	nil,                           // 23: api.Work.ParametersEntry
+	nil,                           // 24: api.WorkerStatus.DroppedInvocationsEntry
+	(*timestamppb.Timestamp)(nil), // 25: google.protobuf.Timestamp
 }
 var file_api_tracewriter_proto_depIdxs = []int32{
-	2,  // 0: api.WorkerConfiguration.units:type_name -> api.Unit
-	0,  // 1: api.Unit.rel_type:type_name -> api.RelationshipType
-	4,  // 2: api.Unit.work_before:type_name -> api.Work
-	7,  // 3: api.Unit.context:type_name -> api.ContextTemplate
-	3,  // 4: api.Unit.inputs:type_name -> api.UnitRef
-	3,  // 5: api.Unit.successors:type_name -> api.UnitRef
-	11, // 6: api.Work.parameters:type_name -> api.Work.ParametersEntry
-	12, // 7: api.Result.start_time:type_name -> google.protobuf.Timestamp
-	12, // 8: api.Result.finish_time:type_name -> google.protobuf.Timestamp
-	5,  // 9: api.ContextTemplate.tags:type_name -> api.KeyValueTemplate
-	5,  // 10: api.ContextTemplate.logs:type_name -> api.KeyValueTemplate
-	5,  // 11: api.ContextTemplate.baggage:type_name -> api.KeyValueTemplate
-	6,  // 12: api.ResultPackage.results:type_name -> api.Result
-	1,  // 13: api.BenchmarkWorker.StartWorker:input_type -> api.WorkerConfiguration
-	9,  // 14: api.BenchmarkWorker.Call:input_type -> api.DispatchId
-	8,  // 15: api.BenchmarkWorker.StartWorker:output_type -> api.ResultPackage
-	10, // 16: api.BenchmarkWorker.Call:output_type -> api.Empty
-	15, // [15:17] is the sub-list for method output_type
-	13, // [13:15] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	6,  // 0: api.WorkerConfiguration.units:type_name -> api.Unit
+	4,  // 1: api.WorkerConfiguration.load_profile:type_name -> api.LoadProfile
+	3,  // 2: api.WorkerConfiguration.concurrency:type_name -> api.Concurrency
+	5,  // 3: api.LoadProfile.points:type_name -> api.LoadPoint
+	1,  // 4: api.Unit.rel_type:type_name -> api.RelationshipType
+	10, // 5: api.Unit.work_before:type_name -> api.Work
+	15, // 6: api.Unit.context:type_name -> api.ContextTemplate
+	9,  // 7: api.Unit.inputs:type_name -> api.UnitRef
+	9,  // 8: api.Unit.successors:type_name -> api.UnitRef
+	8,  // 9: api.Unit.arrival:type_name -> api.Arrival
+	7,  // 10: api.Unit.users:type_name -> api.Users
+	3,  // 11: api.Unit.concurrency:type_name -> api.Concurrency
+	10, // 12: api.Users.think_time:type_name -> api.Work
+	22, // 13: api.Arrival.parameters:type_name -> api.Arrival.ParametersEntry
+	23, // 14: api.Work.parameters:type_name -> api.Work.ParametersEntry
+	12, // 15: api.Work.components:type_name -> api.MixtureComponent
+	11, // 16: api.Work.empirical:type_name -> api.EmpiricalDistribution
+	10, // 17: api.MixtureComponent.work:type_name -> api.Work
+	25, // 18: api.Result.start_time:type_name -> google.protobuf.Timestamp
+	25, // 19: api.Result.finish_time:type_name -> google.protobuf.Timestamp
+	13, // 20: api.ContextTemplate.tags:type_name -> api.KeyValueTemplate
+	13, // 21: api.ContextTemplate.logs:type_name -> api.KeyValueTemplate
+	13, // 22: api.ContextTemplate.baggage:type_name -> api.KeyValueTemplate
+	14, // 23: api.ResultPackage.results:type_name -> api.Result
+	25, // 24: api.StartRequest.start_time:type_name -> google.protobuf.Timestamp
+	0,  // 25: api.WorkerStatus.state:type_name -> api.WorkerState
+	24, // 26: api.WorkerStatus.dropped_invocations:type_name -> api.WorkerStatus.DroppedInvocationsEntry
+	2,  // 27: api.BenchmarkWorker.StartWorker:input_type -> api.WorkerConfiguration
+	17, // 28: api.BenchmarkWorker.Call:input_type -> api.DispatchId
+	2,  // 29: api.BenchmarkWorker.Prepare:input_type -> api.WorkerConfiguration
+	19, // 30: api.BenchmarkWorker.Start:input_type -> api.StartRequest
+	20, // 31: api.BenchmarkWorker.Stop:input_type -> api.StopRequest
+	18, // 32: api.BenchmarkWorker.Status:input_type -> api.Empty
+	16, // 33: api.BenchmarkWorker.StartWorker:output_type -> api.ResultPackage
+	18, // 34: api.BenchmarkWorker.Call:output_type -> api.Empty
+	21, // 35: api.BenchmarkWorker.Prepare:output_type -> api.WorkerStatus
+	16, // 36: api.BenchmarkWorker.Start:output_type -> api.ResultPackage
+	21, // 37: api.BenchmarkWorker.Stop:output_type -> api.WorkerStatus
+	21, // 38: api.BenchmarkWorker.Status:output_type -> api.WorkerStatus
+	33, // [33:39] is the sub-list for method output_type
+	27, // [27:33] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_api_tracewriter_proto_init() }
@@ -997,7 +1990,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Unit); i {
+			switch v := v.(*Concurrency); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1009,7 +2002,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UnitRef); i {
+			switch v := v.(*LoadProfile); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1021,7 +2014,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Work); i {
+			switch v := v.(*LoadPoint); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1033,7 +2026,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*KeyValueTemplate); i {
+			switch v := v.(*Unit); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1045,7 +2038,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Result); i {
+			switch v := v.(*Users); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1057,7 +2050,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ContextTemplate); i {
+			switch v := v.(*Arrival); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1069,7 +2062,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ResultPackage); i {
+			switch v := v.(*UnitRef); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1081,7 +2074,7 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DispatchId); i {
+			switch v := v.(*Work); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1093,6 +2086,90 @@ func file_api_tracewriter_proto_init() {
 			}
 		}
 		file_api_tracewriter_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EmpiricalDistribution); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MixtureComponent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyValueTemplate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Result); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ContextTemplate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResultPackage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DispatchId); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Empty); i {
 			case 0:
 				return &v.state
@@ -1104,14 +2181,50 @@ func file_api_tracewriter_proto_init() {
 				return nil
 			}
 		}
+		file_api_tracewriter_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StartRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_tracewriter_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WorkerStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_tracewriter_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   11,
+			NumEnums:      2,
+			NumMessages:   23,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -17,8 +17,16 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type BenchmarkWorkerClient interface {
+	//StartWorker prepares and starts a worker in one step, i.e. it equals Prepare followed by Start.
 	StartWorker(ctx context.Context, in *WorkerConfiguration, opts ...grpc.CallOption) (BenchmarkWorker_StartWorkerClient, error)
 	Call(ctx context.Context, in *DispatchId, opts ...grpc.CallOption) (*Empty, error)
+	//Prepare configures a worker for a run without generating load: the tracer, units and the service endpoint are created, and successors are dialed.
+	Prepare(ctx context.Context, in *WorkerConfiguration, opts ...grpc.CallOption) (*WorkerStatus, error)
+	//Start begins load generation of a prepared worker and streams results. The stream is closed after the last ResultPackage of the run was sent.
+	Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (BenchmarkWorker_StartClient, error)
+	//Stop ends load generation before the runtime is over. Results are still flushed to the stream of Start, unless the run is aborted.
+	Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*WorkerStatus, error)
+	Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*WorkerStatus, error)
 }
 
 type benchmarkWorkerClient struct {
@@ -70,12 +78,79 @@ func (c *benchmarkWorkerClient) Call(ctx context.Context, in *DispatchId, opts .
 	return out, nil
 }
 
+func (c *benchmarkWorkerClient) Prepare(ctx context.Context, in *WorkerConfiguration, opts ...grpc.CallOption) (*WorkerStatus, error) {
+	out := new(WorkerStatus)
+	err := c.cc.Invoke(ctx, "/api.BenchmarkWorker/Prepare", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *benchmarkWorkerClient) Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (BenchmarkWorker_StartClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BenchmarkWorker_ServiceDesc.Streams[1], "/api.BenchmarkWorker/Start", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &benchmarkWorkerStartClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BenchmarkWorker_StartClient interface {
+	Recv() (*ResultPackage, error)
+	grpc.ClientStream
+}
+
+type benchmarkWorkerStartClient struct {
+	grpc.ClientStream
+}
+
+func (x *benchmarkWorkerStartClient) Recv() (*ResultPackage, error) {
+	m := new(ResultPackage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *benchmarkWorkerClient) Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*WorkerStatus, error) {
+	out := new(WorkerStatus)
+	err := c.cc.Invoke(ctx, "/api.BenchmarkWorker/Stop", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *benchmarkWorkerClient) Status(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*WorkerStatus, error) {
+	out := new(WorkerStatus)
+	err := c.cc.Invoke(ctx, "/api.BenchmarkWorker/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BenchmarkWorkerServer is the server API for BenchmarkWorker service.
 // All implementations must embed UnimplementedBenchmarkWorkerServer
 // for forward compatibility
 type BenchmarkWorkerServer interface {
+	//StartWorker prepares and starts a worker in one step, i.e. it equals Prepare followed by Start.
 	StartWorker(*WorkerConfiguration, BenchmarkWorker_StartWorkerServer) error
 	Call(context.Context, *DispatchId) (*Empty, error)
+	//Prepare configures a worker for a run without generating load: the tracer, units and the service endpoint are created, and successors are dialed.
+	Prepare(context.Context, *WorkerConfiguration) (*WorkerStatus, error)
+	//Start begins load generation of a prepared worker and streams results. The stream is closed after the last ResultPackage of the run was sent.
+	Start(*StartRequest, BenchmarkWorker_StartServer) error
+	//Stop ends load generation before the runtime is over. Results are still flushed to the stream of Start, unless the run is aborted.
+	Stop(context.Context, *StopRequest) (*WorkerStatus, error)
+	Status(context.Context, *Empty) (*WorkerStatus, error)
 	mustEmbedUnimplementedBenchmarkWorkerServer()
 }
 
@@ -89,6 +164,18 @@ func (UnimplementedBenchmarkWorkerServer) StartWorker(*WorkerConfiguration, Benc
 func (UnimplementedBenchmarkWorkerServer) Call(context.Context, *DispatchId) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Call not implemented")
 }
+func (UnimplementedBenchmarkWorkerServer) Prepare(context.Context, *WorkerConfiguration) (*WorkerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Prepare not implemented")
+}
+func (UnimplementedBenchmarkWorkerServer) Start(*StartRequest, BenchmarkWorker_StartServer) error {
+	return status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedBenchmarkWorkerServer) Stop(context.Context, *StopRequest) (*WorkerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedBenchmarkWorkerServer) Status(context.Context, *Empty) (*WorkerStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
 func (UnimplementedBenchmarkWorkerServer) mustEmbedUnimplementedBenchmarkWorkerServer() {}
 
 // UnsafeBenchmarkWorkerServer may be embedded to opt out of forward compatibility for this service.
@@ -141,6 +228,81 @@ func _BenchmarkWorker_Call_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BenchmarkWorker_Prepare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WorkerConfiguration)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BenchmarkWorkerServer).Prepare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.BenchmarkWorker/Prepare",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BenchmarkWorkerServer).Prepare(ctx, req.(*WorkerConfiguration))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BenchmarkWorker_Start_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StartRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BenchmarkWorkerServer).Start(m, &benchmarkWorkerStartServer{stream})
+}
+
+type BenchmarkWorker_StartServer interface {
+	Send(*ResultPackage) error
+	grpc.ServerStream
+}
+
+type benchmarkWorkerStartServer struct {
+	grpc.ServerStream
+}
+
+func (x *benchmarkWorkerStartServer) Send(m *ResultPackage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _BenchmarkWorker_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BenchmarkWorkerServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.BenchmarkWorker/Stop",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BenchmarkWorkerServer).Stop(ctx, req.(*StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BenchmarkWorker_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BenchmarkWorkerServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/api.BenchmarkWorker/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BenchmarkWorkerServer).Status(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BenchmarkWorker_ServiceDesc is the grpc.ServiceDesc for BenchmarkWorker service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -152,6 +314,18 @@ var BenchmarkWorker_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Call",
 			Handler:    _BenchmarkWorker_Call_Handler,
 		},
+		{
+			MethodName: "Prepare",
+			Handler:    _BenchmarkWorker_Prepare_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _BenchmarkWorker_Stop_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _BenchmarkWorker_Status_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -159,6 +333,11 @@ var BenchmarkWorker_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _BenchmarkWorker_StartWorker_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Start",
+			Handler:       _BenchmarkWorker_Start_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/tracewriter.proto",
 }
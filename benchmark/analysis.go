@@ -0,0 +1,501 @@
+package benchmark
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/dominik-/t-race/executionmodel"
+)
+
+//Report summarizes the results of a run: latencies and throughput of services and units, and the completeness of traces reconstructed from the records.
+type Report struct {
+	Name      string `json:"name"`
+	ResultDir string `json:"resultDir"`
+	//DurationSeconds is the runtime of the run if a manifest exists, otherwise the time between the first and the last span start.
+	DurationSeconds float64 `json:"durationSeconds"`
+	//HasTargets is true if target rates and expected trace sizes could be derived from the manifest of the run.
+	HasTargets bool             `json:"hasTargets"`
+	Spans      int              `json:"spans"`
+	Services   []*ServiceReport `json:"services"`
+	Traces     *TraceReport     `json:"traces"`
+}
+
+//SpanStats are statistics of a set of spans. Rates are spans per second.
+type SpanStats struct {
+	Spans             int          `json:"spans"`
+	TargetPerSecond   float64      `json:"targetPerSecond"`
+	AchievedPerSecond float64      `json:"achievedPerSecond"`
+	SampledRatio      float64      `json:"sampledRatio"`
+	ErrorRatio        float64      `json:"errorRatio"`
+	Latency           LatencyStats `json:"latency"`
+}
+
+//LatencyStats are span durations in milliseconds.
+type LatencyStats struct {
+	Mean float64 `json:"meanMs"`
+	P50  float64 `json:"p50Ms"`
+	P90  float64 `json:"p90Ms"`
+	P95  float64 `json:"p95Ms"`
+	P99  float64 `json:"p99Ms"`
+	Max  float64 `json:"maxMs"`
+}
+
+type ServiceReport struct {
+	Service string `json:"service"`
+	SpanStats
+	Units []*UnitReport `json:"units"`
+}
+
+type UnitReport struct {
+	Unit string `json:"unit"`
+	SpanStats
+}
+
+//TraceReport describes the number of spans per trace. A trace is complete if it contains a span for every invocation expected from its root unit.
+type TraceReport struct {
+	Traces int `json:"traces"`
+	//WithoutRoot counts traces whose root span wasn't recorded, e.g. because it was still running when the run ended.
+	WithoutRoot int              `json:"withoutRoot"`
+	Complete    int              `json:"complete"`
+	Min         int              `json:"minSpans"`
+	Max         int              `json:"maxSpans"`
+	Mean        float64          `json:"meanSpans"`
+	P50         int              `json:"p50Spans"`
+	P90         int              `json:"p90Spans"`
+	P99         int              `json:"p99Spans"`
+	Histogram   []*TraceSizes    `json:"histogram"`
+	RootUnits   []*RootUnitStats `json:"rootUnits"`
+}
+
+//TraceSizes is the number of traces with the given number of spans.
+type TraceSizes struct {
+	Spans  int `json:"spans"`
+	Traces int `json:"traces"`
+}
+
+//RootUnitStats describes the traces started by a unit.
+type RootUnitStats struct {
+	Service       string `json:"service"`
+	Unit          string `json:"unit"`
+	Traces        int    `json:"traces"`
+	ExpectedSpans int    `json:"expectedSpans"`
+	Complete      int    `json:"complete"`
+}
+
+//Analyze creates the report of a run. The manifest is optional; without it, no targets are reported.
+func Analyze(records []*Record, manifest *Manifest, resultDir string) (*Report, error) {
+	report := &Report{
+		ResultDir: resultDir,
+		Spans:     len(records),
+	}
+	var plan *Plan
+	var configs map[string]*api.WorkerConfiguration
+	if manifest != nil {
+		report.Name = manifest.Name
+		report.DurationSeconds = float64(manifest.Runtime)
+		var err error
+		configs, err = manifest.WorkerConfigurations()
+		if err != nil {
+			return nil, err
+		}
+		plan = planFromManifest(manifest, configs)
+		report.HasTargets = plan != nil
+	}
+	if report.DurationSeconds <= 0 {
+		report.DurationSeconds = measuredDuration(records)
+	}
+
+	byService := make(map[string][]*Record)
+	byUnit := make(map[string][]*Record)
+	for _, r := range records {
+		byService[r.Service] = append(byService[r.Service], r)
+		byUnit[r.Service+"/"+r.Unit] = append(byUnit[r.Service+"/"+r.Unit], r)
+	}
+	services := make([]string, 0, len(byService))
+	for service := range byService {
+		services = append(services, service)
+	}
+	//services and units without any records are reported, if they were planned
+	if plan != nil {
+		for _, servicePlan := range plan.Services {
+			if _, ok := byService[servicePlan.Service]; !ok {
+				services = append(services, servicePlan.Service)
+			}
+		}
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		serviceReport := &ServiceReport{
+			Service:   service,
+			SpanStats: newSpanStats(byService[service], report.DurationSeconds),
+			Units:     make([]*UnitReport, 0),
+		}
+		units := make(map[string]bool)
+		for _, r := range byService[service] {
+			units[r.Unit] = true
+		}
+		targets := make(map[string]float64)
+		if servicePlan := findServicePlan(plan, service); servicePlan != nil {
+			for _, unitPlan := range servicePlan.Units {
+				units[unitPlan.Unit] = true
+				targets[unitPlan.Unit] = unitPlan.InvocationsPerSecond
+				serviceReport.TargetPerSecond += unitPlan.InvocationsPerSecond
+			}
+		}
+		unitNames := make([]string, 0, len(units))
+		for unit := range units {
+			unitNames = append(unitNames, unit)
+		}
+		sort.Strings(unitNames)
+		for _, unit := range unitNames {
+			unitReport := &UnitReport{
+				Unit:      unit,
+				SpanStats: newSpanStats(byUnit[service+"/"+unit], report.DurationSeconds),
+			}
+			unitReport.TargetPerSecond = targets[unit]
+			serviceReport.Units = append(serviceReport.Units, unitReport)
+		}
+		report.Services = append(report.Services, serviceReport)
+	}
+	report.Traces = newTraceReport(records, expectedSpansPerTrace(configs))
+	return report, nil
+}
+
+func planFromManifest(manifest *Manifest, configs map[string]*api.WorkerConfiguration) *Plan {
+	if manifest.Architecture == nil || manifest.Allocation == nil {
+		return nil
+	}
+	for _, svc := range manifest.Architecture.Services {
+		if _, ok := configs[svc.Identifier]; !ok {
+			return nil
+		}
+	}
+	return NewPlan(manifest.Architecture, configs, manifest.Allocation.Services, manifest.Allocation.Workers, &executionmodel.BenchmarkConfig{
+		Throughput: manifest.Throughput,
+		Runtime:    manifest.Runtime,
+	})
+}
+
+func findServicePlan(plan *Plan, service string) *ServicePlan {
+	if plan == nil {
+		return nil
+	}
+	for _, servicePlan := range plan.Services {
+		if servicePlan.Service == service {
+			return servicePlan
+		}
+	}
+	return nil
+}
+
+func measuredDuration(records []*Record) float64 {
+	if len(records) == 0 {
+		return 0
+	}
+	first, last := records[0].StartTime, records[0].StartTime
+	for _, r := range records {
+		if r.StartTime < first {
+			first = r.StartTime
+		}
+		if r.StartTime > last {
+			last = r.StartTime
+		}
+	}
+	return float64(last-first) / 1e9
+}
+
+func newSpanStats(records []*Record, durationSeconds float64) SpanStats {
+	stats := SpanStats{Spans: len(records)}
+	if len(records) == 0 {
+		return stats
+	}
+	if durationSeconds > 0 {
+		stats.AchievedPerSecond = float64(len(records)) / durationSeconds
+	}
+	durations := make([]float64, len(records))
+	sampled, failed := 0, 0
+	for i, r := range records {
+		durations[i] = float64(r.FinishTime-r.StartTime) / 1e6
+		if r.Sampled {
+			sampled++
+		}
+		if r.Status != "" && r.Status != "OK" {
+			failed++
+		}
+	}
+	stats.SampledRatio = float64(sampled) / float64(len(records))
+	stats.ErrorRatio = float64(failed) / float64(len(records))
+	stats.Latency = newLatencyStats(durations)
+	return stats
+}
+
+//newLatencyStats computes the statistics of durations in milliseconds. The slice is sorted in place.
+func newLatencyStats(durations []float64) LatencyStats {
+	if len(durations) == 0 {
+		return LatencyStats{}
+	}
+	sort.Float64s(durations)
+	sum := 0.0
+	for _, d := range durations {
+		sum += d
+	}
+	return LatencyStats{
+		Mean: sum / float64(len(durations)),
+		P50:  durations[percentileIndex(len(durations), 50)],
+		P90:  durations[percentileIndex(len(durations), 90)],
+		P95:  durations[percentileIndex(len(durations), 95)],
+		P99:  durations[percentileIndex(len(durations), 99)],
+		Max:  durations[len(durations)-1],
+	}
+}
+
+//percentileIndex returns the index of the p-th percentile in a sorted slice of length n, using the nearest-rank method.
+func percentileIndex(n int, p float64) int {
+	index := int(math.Ceil(p/100*float64(n))) - 1
+	if index < 0 {
+		return 0
+	}
+	return index
+}
+
+//expectedSpansPerTrace returns the number of spans a trace started by each unit (keyed by "service/unit") should contain: one per invocation of a unit,
+//where each unit invokes all of its successors once.
+func expectedSpansPerTrace(configs map[string]*api.WorkerConfiguration) map[string]int {
+	if configs == nil {
+		return nil
+	}
+	units := make(map[string]*api.Unit)
+	for service, config := range configs {
+		for _, unit := range config.Units {
+			units[service+"/"+unit.Identifier] = unit
+		}
+	}
+	expected := make(map[string]int, len(units))
+	order := topologicalOrder(units)
+	//successors come after their predecessors, so the sizes are computed in reverse order
+	for i := len(order) - 1; i >= 0; i-- {
+		spans := 1
+		for _, successor := range units[order[i]].Successors {
+			spans += expected[successor.ServiceId+"/"+successor.UnitId]
+		}
+		expected[order[i]] = spans
+	}
+	return expected
+}
+
+func newTraceReport(records []*Record, expected map[string]int) *TraceReport {
+	type trace struct {
+		spans int
+		root  *Record
+	}
+	traces := make(map[string]*trace)
+	for _, r := range records {
+		t, ok := traces[r.TraceID]
+		if !ok {
+			t = &trace{}
+			traces[r.TraceID] = t
+		}
+		t.spans++
+		if r.ParentID == 0 {
+			t.root = r
+		}
+	}
+	report := &TraceReport{
+		Traces:    len(traces),
+		Histogram: make([]*TraceSizes, 0),
+		RootUnits: make([]*RootUnitStats, 0),
+	}
+	if len(traces) == 0 {
+		return report
+	}
+	sizes := make([]int, 0, len(traces))
+	histogram := make(map[int]int)
+	roots := make(map[string]*RootUnitStats)
+	sum := 0
+	for _, t := range traces {
+		sizes = append(sizes, t.spans)
+		histogram[t.spans]++
+		sum += t.spans
+		if t.root == nil {
+			report.WithoutRoot++
+			continue
+		}
+		key := t.root.Service + "/" + t.root.Unit
+		root, ok := roots[key]
+		if !ok {
+			root = &RootUnitStats{
+				Service:       t.root.Service,
+				Unit:          t.root.Unit,
+				ExpectedSpans: expected[key],
+			}
+			roots[key] = root
+		}
+		root.Traces++
+		if root.ExpectedSpans > 0 && t.spans == root.ExpectedSpans {
+			root.Complete++
+			report.Complete++
+		}
+	}
+	sort.Ints(sizes)
+	report.Min = sizes[0]
+	report.Max = sizes[len(sizes)-1]
+	report.Mean = float64(sum) / float64(len(sizes))
+	report.P50 = sizes[percentileIndex(len(sizes), 50)]
+	report.P90 = sizes[percentileIndex(len(sizes), 90)]
+	report.P99 = sizes[percentileIndex(len(sizes), 99)]
+	for spans, count := range histogram {
+		report.Histogram = append(report.Histogram, &TraceSizes{Spans: spans, Traces: count})
+	}
+	sort.Slice(report.Histogram, func(i, j int) bool { return report.Histogram[i].Spans < report.Histogram[j].Spans })
+	for _, root := range roots {
+		report.RootUnits = append(report.RootUnits, root)
+	}
+	sort.Slice(report.RootUnits, func(i, j int) bool {
+		if report.RootUnits[i].Service != report.RootUnits[j].Service {
+			return report.RootUnits[i].Service < report.RootUnits[j].Service
+		}
+		return report.RootUnits[i].Unit < report.RootUnits[j].Unit
+	})
+	return report
+}
+
+//WriteText writes a human-readable version of the report.
+func (r *Report) WriteText(out io.Writer) error {
+	fmt.Fprintf(out, "Results of %q in %s: %d spans in %d traces over %.1fs\n\n", r.Name, r.ResultDir, r.Spans, r.Traces.Traces, r.DurationSeconds)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tUNIT\tSPANS\tTARGET/S\tACHIEVED/S\tSAMPLED\tERRORS\tMEAN MS\tP50 MS\tP90 MS\tP95 MS\tP99 MS\tMAX MS")
+	for _, s := range r.Services {
+		r.writeStatsRow(w, s.Service, "*", s.SpanStats)
+		for _, u := range s.Units {
+			r.writeStatsRow(w, s.Service, u.Unit, u.SpanStats)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	t := r.Traces
+	fmt.Fprintf(out, "\nSpans per trace: min %d, mean %.2f, p50 %d, p90 %d, p99 %d, max %d\n", t.Min, t.Mean, t.P50, t.P90, t.P99, t.Max)
+	if r.HasTargets {
+		fmt.Fprintf(out, "Complete traces: %d of %d (%s), %d without root span\n", t.Complete, t.Traces, percent(t.Complete, t.Traces), t.WithoutRoot)
+	} else {
+		fmt.Fprintf(out, "Traces without root span: %d\n", t.WithoutRoot)
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROOT SERVICE\tROOT UNIT\tTRACES\tEXPECTED SPANS\tCOMPLETE")
+	for _, root := range t.RootUnits {
+		expected, complete := "-", "-"
+		if root.ExpectedSpans > 0 {
+			expected = fmt.Sprintf("%d", root.ExpectedSpans)
+			complete = fmt.Sprintf("%d (%s)", root.Complete, percent(root.Complete, root.Traces))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", root.Service, root.Unit, root.Traces, expected, complete)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SPANS PER TRACE\tTRACES")
+	for _, h := range t.Histogram {
+		fmt.Fprintf(w, "%d\t%d\n", h.Spans, h.Traces)
+	}
+	return w.Flush()
+}
+
+func (r *Report) writeStatsRow(w io.Writer, service, unit string, s SpanStats) {
+	target := "-"
+	if r.HasTargets {
+		target = fmt.Sprintf("%.2f", s.TargetPerSecond)
+	}
+	l := s.Latency
+	fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%.2f\t%.3f\t%.3f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\n", service, unit, s.Spans, target, s.AchievedPerSecond, s.SampledRatio, s.ErrorRatio, l.Mean, l.P50, l.P90, l.P95, l.P99, l.Max)
+}
+
+func percent(part, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(part)/float64(total))
+}
+
+//WriteHTML writes the report as a self-contained HTML page.
+func (r *Report) WriteHTML(out io.Writer) error {
+	return reportTemplate.Execute(out, r)
+}
+
+//statsRow is the input of the stats template, which renders the cells of a row of SpanStats.
+type statsRow struct {
+	HasTargets bool
+	Stats      SpanStats
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": percent,
+	"statsRow": func(hasTargets bool, stats SpanStats) statsRow {
+		return statsRow{HasTargets: hasTargets, Stats: stats}
+	},
+	"barWidth": func(count int, traces int) string {
+		if traces == 0 {
+			return "0"
+		}
+		return fmt.Sprintf("%.1f", 100*float64(count)/float64(traces))
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>t-race results: {{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th { background: #eee; }
+td.name { text-align: left; }
+tr.service td { font-weight: bold; background: #f7f7f7; }
+.bar { background: #4a7ab5; height: 1em; }
+</style>
+</head>
+<body>
+<h1>t-race results: {{.Name}}</h1>
+<p>{{.Spans}} spans in {{.Traces.Traces}} traces over {{printf "%.1f" .DurationSeconds}}s, read from {{.ResultDir}}.</p>
+<h2>Services and units</h2>
+<table>
+<tr><th>Service</th><th>Unit</th><th>Spans</th><th>Target/s</th><th>Achieved/s</th><th>Sampled</th><th>Errors</th><th>Mean ms</th><th>p50 ms</th><th>p90 ms</th><th>p95 ms</th><th>p99 ms</th><th>Max ms</th></tr>
+{{- $hasTargets := .HasTargets}}
+{{- range .Services}}
+{{- $service := .Service}}
+<tr class="service"><td class="name">{{.Service}}</td><td class="name">*</td>{{template "stats" (statsRow $hasTargets .SpanStats)}}</tr>
+{{- range .Units}}
+<tr><td class="name">{{$service}}</td><td class="name">{{.Unit}}</td>{{template "stats" (statsRow $hasTargets .SpanStats)}}</tr>
+{{- end}}
+{{- end}}
+</table>
+<h2>Traces</h2>
+{{- with .Traces}}
+<p>Spans per trace: min {{.Min}}, mean {{printf "%.2f" .Mean}}, p50 {{.P50}}, p90 {{.P90}}, p99 {{.P99}}, max {{.Max}}.
+{{- if $hasTargets}} Complete traces: {{.Complete}} of {{.Traces}} ({{percent .Complete .Traces}}).{{end}} Traces without root span: {{.WithoutRoot}}.</p>
+<table>
+<tr><th>Root service</th><th>Root unit</th><th>Traces</th><th>Expected spans</th><th>Complete</th></tr>
+{{- range .RootUnits}}
+<tr><td class="name">{{.Service}}</td><td class="name">{{.Unit}}</td><td>{{.Traces}}</td>{{if .ExpectedSpans}}<td>{{.ExpectedSpans}}</td><td>{{.Complete}} ({{percent .Complete .Traces}})</td>{{else}}<td>-</td><td>-</td>{{end}}</tr>
+{{- end}}
+</table>
+<h3>Spans per trace</h3>
+{{- $traces := .Traces}}
+<table>
+<tr><th>Spans</th><th>Traces</th><th style="width: 300px"></th></tr>
+{{- range .Histogram}}
+<tr><td>{{.Spans}}</td><td>{{.Traces}}</td><td class="name"><div class="bar" style="width: {{barWidth .Traces $traces}}%"></div></td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+{{define "stats"}}<td>{{.Stats.Spans}}</td><td>{{if .HasTargets}}{{printf "%.2f" .Stats.TargetPerSecond}}{{else}}-{{end}}</td><td>{{printf "%.2f" .Stats.AchievedPerSecond}}</td><td>{{printf "%.3f" .Stats.SampledRatio}}</td><td>{{printf "%.3f" .Stats.ErrorRatio}}</td>
+{{- with .Stats.Latency}}<td>{{printf "%.2f" .Mean}}</td><td>{{printf "%.2f" .P50}}</td><td>{{printf "%.2f" .P90}}</td><td>{{printf "%.2f" .P95}}</td><td>{{printf "%.2f" .P99}}</td><td>{{printf "%.2f" .Max}}</td>{{end}}{{end}}`))
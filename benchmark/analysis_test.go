@@ -0,0 +1,115 @@
+package benchmark
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dominik-/t-race/executionmodel"
+)
+
+//analysisRecords returns the spans of a complete and an incomplete trace of frontend/root, which invokes backend/b and frontend/async,
+//of a trace whose root wasn't recorded and of a trace of backend/users. Times are in milliseconds.
+func analysisRecords() []*Record {
+	span := func(trace, service, unit string, spanID, parentID uint64, start, finish int64, sampled bool, status string) *Record {
+		return &Record{Service: service, Unit: unit, TraceID: trace, SpanID: spanID, ParentID: parentID, StartTime: start * 1e6, FinishTime: finish * 1e6, Sampled: sampled, Status: status}
+	}
+	return []*Record{
+		span("1", "frontend", "root", 1, 0, 0, 10, true, "OK"),
+		span("1", "backend", "b", 2, 1, 1, 5, true, "OK"),
+		span("1", "frontend", "async", 3, 1, 2, 4, true, "OK"),
+		span("1", "backend", "b", 4, 3, 3, 6, true, "OK"),
+		span("2", "frontend", "root", 5, 0, 1000, 1020, false, "OK"),
+		span("2", "frontend", "async", 6, 5, 1001, 1003, false, "Unavailable"),
+		span("3", "backend", "b", 7, 99, 2000, 2002, false, "OK"),
+		span("4", "backend", "users", 8, 0, 3000, 3001, false, "OK"),
+	}
+}
+
+func TestAnalyzeWithManifest(t *testing.T) {
+	architecture, configs := planConfigs(nil)
+	for service, config := range configs {
+		config.ServiceName = service
+	}
+	benchmark := &Benchmark{
+		Config:  &executionmodel.BenchmarkConfig{Throughput: 100, Runtime: 60},
+		Workers: []*Worker{{Config: configs["frontend"]}, {Config: configs["backend"]}},
+	}
+	manifest := newManifest(benchmark, architecture, map[string]string{}, map[string]string{}, map[string]string{})
+	report, err := Analyze(analysisRecords(), manifest, "results/plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Name != "plan" || report.DurationSeconds != 60 || !report.HasTargets || report.Spans != 8 || len(report.Services) != 2 {
+		t.Fatalf("expected a report of 8 spans in 2 services over the runtime, with targets, got %+v", report)
+	}
+	backend, frontend := report.Services[0], report.Services[1]
+	if frontend.Spans != 4 || frontend.TargetPerSecond != 200 || math.Abs(frontend.AchievedPerSecond-4.0/60) > 1e-9 || frontend.SampledRatio != 0.5 || frontend.ErrorRatio != 0.25 {
+		t.Errorf("unexpected stats of the frontend: %+v", frontend.SpanStats)
+	}
+	if backend.Spans != 4 || backend.TargetPerSecond != 250 {
+		t.Errorf("unexpected stats of the backend: %+v", backend.SpanStats)
+	}
+	units := make(map[string]*UnitReport)
+	for _, service := range report.Services {
+		for _, unit := range service.Units {
+			units[service.Service+"/"+unit.Unit] = unit
+		}
+	}
+	if root := units["frontend/root"]; root.Spans != 2 || root.TargetPerSecond != 100 || root.Latency != (LatencyStats{Mean: 15, P50: 10, P90: 20, P95: 20, P99: 20, Max: 20}) {
+		t.Errorf("unexpected stats of frontend/root: %+v", root.SpanStats)
+	}
+	if async := units["frontend/async"]; async.ErrorRatio != 0.5 || async.Latency.Max != 2 {
+		t.Errorf("unexpected stats of frontend/async: %+v", async.SpanStats)
+	}
+	if users := units["backend/users"]; users.Spans != 1 || users.TargetPerSecond != 0 {
+		t.Errorf("unexpected stats of backend/users: %+v", users.SpanStats)
+	}
+
+	traces := report.Traces
+	if traces.Traces != 4 || traces.WithoutRoot != 1 || traces.Complete != 2 || traces.Min != 1 || traces.Max != 4 || traces.Mean != 2 || traces.P50 != 1 || traces.P90 != 4 {
+		t.Errorf("unexpected trace stats: %+v", traces)
+	}
+	if expected := []*TraceSizes{{Spans: 1, Traces: 2}, {Spans: 2, Traces: 1}, {Spans: 4, Traces: 1}}; !reflect.DeepEqual(traces.Histogram, expected) {
+		t.Errorf("expected the histogram %v, got %v", expected, traces.Histogram)
+	}
+	//a trace of frontend/root contains root, async and two spans of b
+	expectedRoots := []*RootUnitStats{
+		{Service: "backend", Unit: "users", Traces: 1, ExpectedSpans: 1, Complete: 1},
+		{Service: "frontend", Unit: "root", Traces: 2, ExpectedSpans: 4, Complete: 1},
+	}
+	if !reflect.DeepEqual(traces.RootUnits, expectedRoots) {
+		t.Errorf("expected the root units %+v, got %+v", expectedRoots, traces.RootUnits)
+	}
+
+	for format, write := range map[string]func(*bytes.Buffer) error{
+		"text": func(out *bytes.Buffer) error { return report.WriteText(out) },
+		"html": func(out *bytes.Buffer) error { return report.WriteHTML(out) },
+	} {
+		out := &bytes.Buffer{}
+		if err := write(out); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for _, expected := range []string{"frontend", "async", "users"} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("%s: expected %q in the report, got\n%s", format, expected, out.String())
+			}
+		}
+	}
+}
+
+func TestAnalyzeWithoutManifest(t *testing.T) {
+	report, err := Analyze(analysisRecords(), nil, "results/plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//without a manifest, the duration is measured from the first to the last span start
+	if report.HasTargets || report.DurationSeconds != 3 || report.Services[1].TargetPerSecond != 0 || report.Services[1].AchievedPerSecond != 4.0/3 {
+		t.Errorf("expected a report without targets over 3s, got %+v", report)
+	}
+	if report.Traces.Complete != 0 || len(report.Traces.RootUnits) != 2 || report.Traces.RootUnits[1].ExpectedSpans != 0 {
+		t.Errorf("expected no complete traces without a manifest, got %+v", report.Traces)
+	}
+}
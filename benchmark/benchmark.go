@@ -6,6 +6,7 @@ import (
 
 	"github.com/dominik-/t-race/api"
 	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
 )
 
 type BenchmarkConfig struct {
@@ -14,15 +15,21 @@ type BenchmarkConfig struct {
 	Runtime         int64
 }
 
+//Record is a span reported by a worker. Column names are the same in all result formats; start and finish times are nanoseconds since the epoch.
 type Record struct {
 	Service     string
+	Unit        string
 	TraceNumber int64
 	SpanNumber  int64
 	TraceID     string
 	SpanID      uint64
+	ParentID    uint64
 	StartTime   int64
 	FinishTime  int64
 	Sampled     bool
+	//Status is the gRPC status code of the span, e.g. OK, or Unavailable and DeadlineExceeded if a call to a successor failed.
+	Status string
+	Error  string
 }
 
 type TraceID []byte
@@ -43,6 +50,11 @@ func resultsToRecords(results *api.ResultPackage, worker *api.WorkerConfiguratio
 		traceID := TraceID{}
 		traceID = resultSlice[i].TraceId
 		spanID := binary.BigEndian.Uint64(resultSlice[i].SpanId)
+		//root spans don't have a parent ID, so we keep 0 here.
+		var parentID uint64
+		if len(resultSlice[i].ParentId) == 8 {
+			parentID = binary.BigEndian.Uint64(resultSlice[i].ParentId)
+		}
 		startTime, err := ptypes.Timestamp(resultSlice[i].StartTime)
 		endTime, err := ptypes.Timestamp(resultSlice[i].FinishTime)
 		if err != nil {
@@ -50,13 +62,17 @@ func resultsToRecords(results *api.ResultPackage, worker *api.WorkerConfiguratio
 		}
 		records[i] = &Record{
 			Service:     worker.ServiceName,
+			Unit:        resultSlice[i].Unit,
 			TraceNumber: resultSlice[i].TraceNum,
 			SpanNumber:  resultSlice[i].SpanNum,
 			TraceID:     traceID.String(),
 			SpanID:      spanID,
+			ParentID:    parentID,
 			StartTime:   startTime.UnixNano(),
 			FinishTime:  endTime.UnixNano(),
 			Sampled:     resultSlice[i].Sampled,
+			Status:      codes.Code(resultSlice[i].StatusCode).String(),
+			Error:       resultSlice[i].Error,
 		}
 	}
 	return records
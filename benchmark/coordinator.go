@@ -3,35 +3,53 @@ package benchmark
 import (
 	"context"
 	"encoding/csv"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/dominik-/t-race/api"
 	"github.com/dominik-/t-race/executionmodel"
 	"github.com/gocarina/gocsv"
+	"github.com/golang/protobuf/ptypes"
 
 	"google.golang.org/grpc"
 )
 
 var resultDirFormat = "2006-01-02T150405"
 
+//readinessTimeout is the maximum time prepared workers may take to connect to their successors.
+var readinessTimeout = 30 * time.Second
+
+//startDelay is the offset of the common start time from the time the first Start request is sent. It must cover sending Start to all workers.
+var startDelay = 2 * time.Second
+
 type Benchmark struct {
-	Name    string
-	Workers []*Worker
-	Config  *executionmodel.BenchmarkConfig
+	Name      string
+	Workers   []*Worker
+	Config    *executionmodel.BenchmarkConfig
+	Manifest  *Manifest
+	resultDir string
+	abortOnce sync.Once
+	probe     *ingestionProbe
 }
 
 type Worker struct {
 	Config       *api.WorkerConfiguration
 	Address      string
 	Connection   *grpc.ClientConn
-	ResultStream api.BenchmarkWorker_StartWorkerClient
+	Client       api.BenchmarkWorkerClient
+	ResultStream api.BenchmarkWorker_StartClient
 }
 
-func Setup(architecture *executionmodel.Architecture, serviceMap, workerMap, sinkMap map[string]string, config *executionmodel.BenchmarkConfig) *Benchmark {
+//Setup maps the architecture to worker configurations and connects to all workers. Additional dial options can be used to connect to workers of a worker.LocalCluster.
+func Setup(architecture *executionmodel.Architecture, serviceMap, workerMap, sinkMap map[string]string, config *executionmodel.BenchmarkConfig, dialOptions ...grpc.DialOption) *Benchmark {
 	workers := make([]*Worker, 0)
 
 	configs := executionmodel.MapArchitectureToWorkers(*architecture, *config, sinkMap, serviceMap)
@@ -51,22 +69,26 @@ func Setup(architecture *executionmodel.Architecture, serviceMap, workerMap, sin
 	option := grpc.WithInsecure()
 	// Establish connections to all workers.
 	for _, w := range workers {
-		conn, err := grpc.Dial(w.Address, option)
+		conn, err := grpc.Dial(w.Address, append([]grpc.DialOption{option}, dialOptions...)...)
 		if err != nil {
 			log.Printf("Couldnt connect to worker: %v, error was: %v", w, err)
 		}
 		w.Connection = conn
 	}
-	return &Benchmark{
+	benchmark := &Benchmark{
 		Name:    architecture.Name,
 		Workers: workers,
 		Config:  config,
 	}
+	benchmark.Manifest = newManifest(benchmark, architecture, serviceMap, workerMap, sinkMap)
+	return benchmark
 }
 
+//StartBenchmark runs the benchmark in two phases: first all workers are prepared and the coordinator waits until all are connected to their successors,
+//then all are started with a common start time. It returns once every worker closed its result stream,
+//i.e. has sent its last ResultPackage. If a worker fails, or the user interrupts the run, the run is aborted on all workers.
 func (benchmark *Benchmark) StartBenchmark() {
-	//start benchmark on all workers and keep receiving their results
-	//need to fork out into separate threads and write results to files/database
+	benchmark.Manifest.StartTime = time.Now()
 	rootDir := "results"
 	fInfo, err := os.Stat(rootDir)
 	if err != nil {
@@ -86,69 +108,216 @@ func (benchmark *Benchmark) StartBenchmark() {
 	if err != nil {
 		log.Printf("Couldn't create output directory, reason: %v", err)
 	}
-	finishedChannels := make([]chan bool, 0)
+	benchmark.resultDir = dirname
+	benchmark.writeManifest()
+	resultWriter, err := NewResultWriter(benchmark.Config.ResultFormat, dirname)
+	if err != nil {
+		log.Fatalf("Couldn't create result writer: %v", err)
+	}
+	if probeConfig := benchmark.Config.IngestionProbe; probeConfig != nil {
+		benchmark.probe, err = newIngestionProbe(probeConfig)
+		if err != nil {
+			log.Fatalf("Couldn't create ingestion probe: %v", err)
+		}
+		resultWriter = &probingResultWriter{ResultWriter: resultWriter, probe: benchmark.probe}
+		log.Printf("Probing %.1f%% of finished traces at %s.", 100*probeConfig.Ratio, probeConfig.Endpoint)
+	}
+	for _, w := range benchmark.Workers {
+		w.Client = api.NewBenchmarkWorkerClient(w.Connection)
+		_, err := w.Client.Prepare(context.Background(), w.Config)
+		if err != nil {
+			benchmark.Abort(fmt.Sprintf("preparing worker %s failed", w.Config.WorkerId))
+			log.Fatalf("Couldn't prepare worker %s at %s, error was: %v", w.Config.WorkerId, w.Address, err)
+		}
+	}
+	log.Printf("Prepared %d workers.", len(benchmark.Workers))
+	if err := benchmark.awaitReadiness(readinessTimeout); err != nil {
+		benchmark.Abort("workers weren't ready")
+		log.Fatalf("Workers weren't ready within %v: %v", readinessTimeout, err)
+	}
+	startTime := time.Now().Add(startDelay)
+	startTimeProto, err := ptypes.TimestampProto(startTime)
+	if err != nil {
+		log.Fatalf("Couldn't convert start time to proto format: %v", err)
+	}
+	benchmark.Manifest.setTime(&benchmark.Manifest.LoadStartTime, startTime)
+	benchmark.writeManifest()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var resultWG sync.WaitGroup
 	for _, w := range benchmark.Workers {
-		clientStub := api.NewBenchmarkWorkerClient(w.Connection)
-		clientStream, err := clientStub.StartWorker(context.Background(), w.Config)
+		stream, err := w.Client.Start(ctx, &api.StartRequest{WorkerId: w.Config.WorkerId, StartTime: startTimeProto})
 		if err != nil {
-			log.Fatalf("Couldn't call worker %v, error was : %v", w, err)
+			benchmark.Abort(fmt.Sprintf("starting worker %s failed", w.Config.WorkerId))
+			log.Fatalf("Couldn't start worker %s at %s, error was: %v", w.Config.WorkerId, w.Address, err)
 		}
-		w.ResultStream = clientStream
-		finishedChan := make(chan bool, 1)
-		finishedChannels = append(finishedChannels, finishedChan)
-		go WriteResults(w, dirname, finishedChan)
+		w.ResultStream = stream
+		resultWG.Add(1)
+		go func(w *Worker) {
+			defer resultWG.Done()
+			if err := WriteResults(w, resultWriter); err != nil {
+				log.Printf("Receiving results from worker %s failed: %v", w.Config.WorkerId, err)
+				benchmark.Abort(fmt.Sprintf("worker %s failed", w.Config.WorkerId))
+			}
+		}(w)
 	}
-	<-time.NewTimer(time.Second * time.Duration(benchmark.Config.Runtime)).C
-	//we wait additional time to make sure we received all events.
+	log.Printf("Started %d workers, load generation begins at %v.", len(benchmark.Workers), startTime.Format(time.RFC3339Nano))
+	finished := make(chan bool)
+	go func() {
+		resultWG.Wait()
+		close(finished)
+	}()
+	sigTermRecv := make(chan os.Signal, 1)
+	signal.Notify(sigTermRecv, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigTermRecv)
+	//workers finish on their own after the runtime; the tolerance only guards against workers which don't.
 	toleranceDuration := 30 * time.Second
-	log.Printf("Runtime finished. Waiting %v for final benchmark results...", toleranceDuration)
-	<-time.NewTimer(toleranceDuration).C
-	for _, channel := range finishedChannels {
-		channel <- true
+	select {
+	case <-finished:
+		log.Println("All workers finished and reported their results.")
+	case <-sigTermRecv:
+		benchmark.Abort("interrupted by user")
+	case <-time.After(time.Until(startTime) + time.Second*time.Duration(benchmark.Config.Runtime) + toleranceDuration):
+		log.Printf("Workers didn't finish within runtime plus %v, stopping them.", toleranceDuration)
+		benchmark.Stop("runtime plus tolerance exceeded")
+	}
+	select {
+	case <-finished:
+	case <-time.After(toleranceDuration):
+		log.Printf("Workers didn't close their result streams within %v, closing them.", toleranceDuration)
+		cancel()
+		<-finished
 	}
-	<-time.NewTimer(1 * time.Second).C
+	if err := resultWriter.Close(); err != nil {
+		log.Printf("Couldn't close result files: %v", err)
+	}
+	log.Printf("Results were written to %s.", dirname)
+	if benchmark.probe != nil {
+		benchmark.writeIngestionReport()
+	}
+	benchmark.logStatus()
+	benchmark.Manifest.setTime(&benchmark.Manifest.EndTime, time.Now())
+	benchmark.writeManifest()
 	log.Println("Finishing benchmark.")
-	os.Exit(0)
 }
 
-func CSVWriterToFile(file *os.File) *gocsv.SafeCSVWriter {
-	csvWriter := csv.NewWriter(file)
-	return gocsv.NewSafeCSVWriter(csvWriter)
+//Stop asks all workers to stop load generation and report their remaining results.
+func (benchmark *Benchmark) Stop(reason string) {
+	benchmark.stopWorkers(&api.StopRequest{Reason: reason})
 }
 
-func WriteResults(worker *Worker, resultDir string, finishedChannel <-chan bool) {
-	fileHandle, err := os.Create(resultDir + "/" + worker.Config.WorkerId + ".csv")
-	if err != nil {
-		log.Printf("Couldn't create output file, reason: %v", err)
-	}
-	defer fileHandle.Close()
-	writer := CSVWriterToFile(fileHandle)
-	firstWrite := true
-	ticker := time.NewTicker(5 * time.Second)
+//Abort aborts the run on all workers, which discard unreported results. Only the first call has an effect.
+func (benchmark *Benchmark) Abort(reason string) {
+	benchmark.abortOnce.Do(func() {
+		log.Printf("Aborting benchmark: %s", reason)
+		benchmark.Manifest.setAborted(reason)
+		if benchmark.probe != nil {
+			benchmark.probe.stop()
+		}
+		benchmark.stopWorkers(&api.StopRequest{Abort: true, Reason: reason})
+		benchmark.writeManifest()
+	})
+}
+
+//awaitReadiness polls the status of all workers until each is connected to its remote successors.
+func (benchmark *Benchmark) awaitReadiness(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
 	for {
-		select {
-		case <-ticker.C:
-			//Problem: ticker interval is semi-meaningless, since Recv() blocks until a result is received.
-			//This means that the interval of receiving results here is strongly correlated to the interval with which results are sent to the stream.
-			resultPackage, err := worker.ResultStream.Recv()
+		pending := make([]string, 0)
+		for _, w := range benchmark.Workers {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			status, err := w.Client.Status(ctx, &api.Empty{})
+			cancel()
 			if err != nil {
-				if err == io.EOF {
-					return
-				}
-				log.Printf("Error receiving result from worker/service %s: %v", worker.Config.ServiceName, err)
-			} else {
-				log.Printf("Received result package from worker/service %s. Size: %d", worker.Config.ServiceName, len(resultPackage.GetResults()))
+				return fmt.Errorf("couldn't get status of worker %s: %v", w.Config.WorkerId, err)
 			}
-			if resultPackage != nil {
-				if firstWrite {
-					gocsv.MarshalCSV(resultsToRecords(resultPackage, worker.Config), writer)
-					firstWrite = false
-				} else {
-					gocsv.MarshalCSVWithoutHeaders(resultsToRecords(resultPackage, worker.Config), writer)
-				}
+			benchmark.Manifest.setStatus(w.Config.WorkerId, status)
+			if status.State != api.WorkerState_PREPARED {
+				return fmt.Errorf("worker %s is %s instead of %s", w.Config.WorkerId, status.State, api.WorkerState_PREPARED)
 			}
-		case <-finishedChannel:
-			return
+			if !status.Ready {
+				pending = append(pending, fmt.Sprintf("%s -> %s", w.Config.WorkerId, strings.Join(status.PendingSuccessors, ", ")))
+			}
+		}
+		if len(pending) == 0 {
+			log.Printf("All %d workers are ready.", len(benchmark.Workers))
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("workers aren't connected to successors: %s", strings.Join(pending, "; "))
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+//writeIngestionReport waits until all probed traces are visible or timed out, then writes the report of the probe to the result directory and logs a summary.
+func (benchmark *Benchmark) writeIngestionReport() {
+	log.Printf("Waiting for probed traces to become visible, at most %v.", benchmark.probe.config.Timeout)
+	report := benchmark.probe.close()
+	if err := report.Write(benchmark.resultDir); err != nil {
+		log.Printf("Couldn't write ingestion report: %v", err)
+	}
+	report.WriteText(log.Writer())
+}
+
+//writeManifest writes the manifest to the result directory, once it was created.
+func (benchmark *Benchmark) writeManifest() {
+	if benchmark.resultDir == "" {
+		return
+	}
+	if err := benchmark.Manifest.Write(benchmark.resultDir); err != nil {
+		log.Printf("Couldn't write manifest: %v", err)
+	}
+}
+
+func (benchmark *Benchmark) stopWorkers(request *api.StopRequest) {
+	for _, w := range benchmark.Workers {
+		if w.Client == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if _, err := w.Client.Stop(ctx, request); err != nil {
+			log.Printf("Couldn't stop worker %s: %v", w.Config.WorkerId, err)
+		}
+		cancel()
+	}
+}
+
+func (benchmark *Benchmark) logStatus() {
+	for _, w := range benchmark.Workers {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		status, err := w.Client.Status(ctx, &api.Empty{})
+		cancel()
+		if err != nil {
+			log.Printf("Couldn't get status of worker %s: %v", w.Config.WorkerId, err)
+			continue
+		}
+		log.Printf("Worker %s is %s, reported %d results.", w.Config.WorkerId, status.State, status.ResultsReported)
+		for unit, dropped := range status.DroppedInvocations {
+			log.Printf("Worker %s dropped %d invocations of unit %s, because a concurrency limit was reached.", w.Config.WorkerId, dropped, unit)
+		}
+		benchmark.Manifest.setStatus(w.Config.WorkerId, status)
+	}
+}
+
+func CSVWriterToFile(file *os.File) *gocsv.SafeCSVWriter {
+	csvWriter := csv.NewWriter(file)
+	return gocsv.NewSafeCSVWriter(csvWriter)
+}
+
+//WriteResults receives results from the stream of a worker and writes them with the result writer, until the worker closes the stream.
+func WriteResults(worker *Worker, writer ResultWriter) error {
+	for {
+		resultPackage, err := worker.ResultStream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Received result package from worker/service %s. Size: %d", worker.Config.ServiceName, len(resultPackage.GetResults()))
+		if err := writer.Write(worker.Config.WorkerId, resultsToRecords(resultPackage, worker.Config)); err != nil {
+			return fmt.Errorf("couldn't write results: %v", err)
 		}
 	}
 }
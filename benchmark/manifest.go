@@ -0,0 +1,174 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/version"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+//ManifestFileName is the name of the manifest in the result directory of a run.
+const ManifestFileName = "manifest.json"
+
+//Manifest records how a run was configured and how it ended, so runs can be reproduced and compared later. It is written to the result directory
+//when the run starts and updated when load generation starts and after the run.
+type Manifest struct {
+	Version         string                      `json:"version"`
+	Name            string                      `json:"name"`
+	ServiceFile     string                      `json:"serviceFile,omitempty"`
+	DeploymentFile  string                      `json:"deploymentFile,omitempty"`
+	Throughput      int64                       `json:"baselineThroughput"`
+	Runtime         int64                       `json:"runtimeSeconds"`
+	ResultDirPrefix string                      `json:"resultDirPrefix"`
+	ResultFormat    string                      `json:"resultFormat"`
+	LoadProfile     *executionmodel.LoadProfile `json:"loadProfile,omitempty"`
+	//Seed repeats the work times and random tag values of the run, if passed to --seed.
+	Seed int64 `json:"seed"`
+	//IngestionProbe is the configuration of the ingestion probe, if it was enabled.
+	IngestionProbe *executionmodel.IngestionProbeConfig `json:"ingestionProbe,omitempty"`
+	//Architecture is the parsed service descriptor file, including the work templates resolved for each unit.
+	Architecture *executionmodel.Architecture `json:"architecture"`
+	Allocation   *ManifestAllocation          `json:"allocation"`
+	Workers      []*ManifestWorker            `json:"workers"`
+	Host         *ManifestHost                `json:"host"`
+	StartTime    time.Time                    `json:"startTime"`
+	//LoadStartTime is the common start time of load generation sent to all workers.
+	LoadStartTime *time.Time `json:"loadStartTime,omitempty"`
+	EndTime       *time.Time `json:"endTime,omitempty"`
+	Aborted       bool       `json:"aborted"`
+	AbortReason   string     `json:"abortReason,omitempty"`
+	lock          sync.Mutex
+}
+
+//ManifestAllocation maps services to the addresses of their worker and service endpoint, and sinks to their addresses.
+type ManifestAllocation struct {
+	Workers  map[string]string `json:"workers"`
+	Services map[string]string `json:"services"`
+	Sinks    map[string]string `json:"sinks"`
+}
+
+//ManifestWorker contains the configuration sent to a worker, and the last status received from it. Both are in the JSON format of the protobuf messages.
+type ManifestWorker struct {
+	WorkerID      string          `json:"workerId"`
+	Address       string          `json:"address"`
+	Configuration json.RawMessage `json:"configuration"`
+	Status        json.RawMessage `json:"status,omitempty"`
+}
+
+//ManifestHost describes the host of the coordinator.
+type ManifestHost struct {
+	Hostname  string `json:"hostname"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	CPUs      int    `json:"cpus"`
+	GoVersion string `json:"goVersion"`
+}
+
+func newManifest(benchmark *Benchmark, architecture *executionmodel.Architecture, serviceMap, workerMap, sinkMap map[string]string) *Manifest {
+	hostname, _ := os.Hostname()
+	manifest := &Manifest{
+		Version:         version.String(),
+		Name:            architecture.Name,
+		ServiceFile:     benchmark.Config.ServiceFile,
+		DeploymentFile:  benchmark.Config.DeploymentFile,
+		Throughput:      benchmark.Config.Throughput,
+		Runtime:         benchmark.Config.Runtime,
+		ResultDirPrefix: benchmark.Config.ResultDirPrefix,
+		ResultFormat:    benchmark.Config.ResultFormat,
+		IngestionProbe:  benchmark.Config.IngestionProbe,
+		LoadProfile:     benchmark.Config.LoadProfile,
+		Seed:            benchmark.Config.Seed,
+		Architecture:    architecture,
+		Allocation: &ManifestAllocation{
+			Workers:  workerMap,
+			Services: serviceMap,
+			Sinks:    sinkMap,
+		},
+		Workers: make([]*ManifestWorker, len(benchmark.Workers)),
+		Host: &ManifestHost{
+			Hostname:  hostname,
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			CPUs:      runtime.NumCPU(),
+			GoVersion: runtime.Version(),
+		},
+	}
+	for i, w := range benchmark.Workers {
+		configuration, _ := protojson.Marshal(w.Config)
+		manifest.Workers[i] = &ManifestWorker{
+			WorkerID:      w.Config.WorkerId,
+			Address:       w.Address,
+			Configuration: configuration,
+		}
+	}
+	return manifest
+}
+
+//setStatus records the last status received from a worker.
+func (m *Manifest) setStatus(workerID string, status *api.WorkerStatus) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, w := range m.Workers {
+		if w.WorkerID == workerID {
+			w.Status, _ = protojson.Marshal(status)
+		}
+	}
+}
+
+func (m *Manifest) setAborted(reason string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.Aborted = true
+	m.AbortReason = reason
+}
+
+func (m *Manifest) setTime(field **time.Time, t time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	*field = &t
+}
+
+//Write writes the manifest to the result directory, replacing a previous version.
+func (m *Manifest) Write(resultDir string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(resultDir, ManifestFileName), data, 0600)
+}
+
+//ReadManifest reads the manifest of a run from its result directory.
+func ReadManifest(resultDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(resultDir, ManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("couldn't parse manifest: %v", err)
+	}
+	return manifest, nil
+}
+
+//WorkerConfigurations returns the configurations sent to the workers of the run, keyed by service.
+func (m *Manifest) WorkerConfigurations() (map[string]*api.WorkerConfiguration, error) {
+	configs := make(map[string]*api.WorkerConfiguration, len(m.Workers))
+	for _, w := range m.Workers {
+		config := &api.WorkerConfiguration{}
+		if err := protojson.Unmarshal(w.Configuration, config); err != nil {
+			return nil, fmt.Errorf("couldn't parse configuration of worker %s: %v", w.WorkerID, err)
+		}
+		configs[config.ServiceName] = config
+	}
+	return configs, nil
+}
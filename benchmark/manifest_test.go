@@ -0,0 +1,82 @@
+package benchmark
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/version"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	architecture, configs := planConfigs(&api.LoadProfile{Points: []*api.LoadPoint{{OffsetSeconds: 0, Throughput: 10}, {OffsetSeconds: 60, Throughput: 100}}})
+	configs["frontend"].ServiceName = "frontend"
+	configs["backend"].ServiceName = "backend"
+	benchmark := &Benchmark{
+		Config: &executionmodel.BenchmarkConfig{Throughput: 100, Runtime: 60, ResultFormat: "csv", ServiceFile: "services.yaml", DeploymentFile: "deployment.yaml", Seed: 42},
+		Workers: []*Worker{
+			{Config: configs["frontend"], Address: "worker-1:8000"},
+			{Config: configs["backend"], Address: "worker-2:8000"},
+		},
+	}
+	allocation := &ManifestAllocation{
+		Workers:  map[string]string{"frontend": "worker-1:8000", "backend": "worker-2:8000"},
+		Services: map[string]string{"frontend": "worker-1:7000", "backend": "worker-2:7000"},
+		Sinks:    map[string]string{"jaeger": "jaeger:6831"},
+	}
+	manifest := newManifest(benchmark, architecture, allocation.Services, allocation.Workers, allocation.Sinks)
+	manifest.StartTime = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	manifest.setTime(&manifest.LoadStartTime, manifest.StartTime.Add(2*time.Second))
+	manifest.setTime(&manifest.EndTime, manifest.StartTime.Add(time.Minute))
+	manifest.setStatus("worker-backend", &api.WorkerStatus{WorkerId: "worker-backend", State: api.WorkerState_FINISHED, ResultsReported: 3000})
+	manifest.setAborted("interrupted by user")
+	dir := t.TempDir()
+	if err := manifest.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Version != version.String() || read.Name != "plan" || read.ServiceFile != "services.yaml" || read.DeploymentFile != "deployment.yaml" ||
+		read.Throughput != 100 || read.Runtime != 60 || read.ResultFormat != "csv" || read.Seed != 42 {
+		t.Errorf("expected the configuration of the run, got %+v", read)
+	}
+	if !reflect.DeepEqual(read.Allocation, allocation) || read.Host == nil || read.Host.GoVersion == "" {
+		t.Errorf("expected the allocation %+v and the host, got %+v and %+v", allocation, read.Allocation, read.Host)
+	}
+	if !read.StartTime.Equal(manifest.StartTime) || !read.LoadStartTime.Equal(*manifest.LoadStartTime) || !read.EndTime.Equal(*manifest.EndTime) {
+		t.Errorf("expected the times %v, %v and %v, got %v, %v and %v", manifest.StartTime, manifest.LoadStartTime, manifest.EndTime, read.StartTime, read.LoadStartTime, read.EndTime)
+	}
+	if !read.Aborted || read.AbortReason != "interrupted by user" {
+		t.Errorf("expected the run to be recorded as aborted, got %t, %q", read.Aborted, read.AbortReason)
+	}
+	if len(read.Architecture.Services) != 2 || read.Architecture.Services[1].Identifier != "backend" {
+		t.Errorf("expected the architecture of the run, got %+v", read.Architecture)
+	}
+	//the configurations are read back as sent to the workers
+	readConfigs, err := read.WorkerConfigurations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readConfigs) != len(configs) {
+		t.Errorf("expected %d worker configurations, got %d", len(configs), len(readConfigs))
+	}
+	for service, config := range configs {
+		if !proto.Equal(readConfigs[service], config) {
+			t.Errorf("expected the configuration %v of %s, got %v", config, service, readConfigs[service])
+		}
+	}
+	if read.Workers[0].Status != nil || read.Workers[1].Status == nil || read.Workers[1].Address != "worker-2:8000" {
+		t.Errorf("expected only the status of the backend, got %+v", read.Workers)
+	}
+}
+
+func TestReadManifestWithoutManifest(t *testing.T) {
+	if _, err := ReadManifest(t.TempDir()); err == nil {
+		t.Errorf("expected an error for a result directory without a manifest")
+	}
+}
@@ -0,0 +1,219 @@
+package benchmark
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/worker"
+)
+
+//Plan describes where the services of an architecture are deployed and which load is expected from them. It is derived from the worker configurations, without contacting any worker.
+type Plan struct {
+	Name       string `json:"name"`
+	Throughput int64  `json:"baselineThroughput"`
+	Runtime    int64  `json:"runtimeSeconds"`
+	//LoadProfile changes the throughput over the run. If it is set, all rates of the plan are means over the runtime.
+	LoadProfile         *api.LoadProfile `json:"loadProfile,omitempty"`
+	MeanThroughput      float64          `json:"meanThroughput"`
+	Services            []*ServicePlan   `json:"services"`
+	TotalSpansPerSecond float64          `json:"totalSpansPerSecond"`
+	//ClosedLoop is true if any unit has virtual users. Their rates depend on the response times of the SUT, so they aren't included in the plan.
+	ClosedLoop bool `json:"closedLoop,omitempty"`
+}
+
+//ServicePlan is the deployment of a single service to a worker.
+type ServicePlan struct {
+	Service        string      `json:"service"`
+	WorkerID       string      `json:"workerId"`
+	WorkerAddress  string      `json:"workerAddress"`
+	ServiceAddress string      `json:"serviceAddress"`
+	Sink           string      `json:"sink"`
+	SinkAddress    string      `json:"sinkAddress"`
+	SinkProvider   string      `json:"sinkProvider"`
+	Propagation    string      `json:"propagation"`
+	Units          []*UnitPlan `json:"units"`
+	SpansPerSecond float64     `json:"spansPerSecond"`
+}
+
+//UnitPlan contains the expected rates of a unit. Units are invoked by their own generator (baselineTP × ratio) and once per invocation of each predecessor.
+//Each invocation creates one span for the unit and one client span per successor.
+type UnitPlan struct {
+	Unit  string  `json:"unit"`
+	Ratio float64 `json:"ratio"`
+	Work  string  `json:"work"`
+	//Arrival is the arrival process of generated invocations, empty for units which don't generate load. Users is the number of virtual users of closed-loop units.
+	Arrival              string  `json:"arrival,omitempty"`
+	Users                int64   `json:"users,omitempty"`
+	GeneratedPerSecond   float64 `json:"generatedPerSecond"`
+	InvocationsPerSecond float64 `json:"invocationsPerSecond"`
+	SpansPerSecond       float64 `json:"spansPerSecond"`
+}
+
+//NewPlan creates the plan for the given worker configurations, as created by executionmodel.MapArchitectureToWorkers. The unit graph must not contain cycles.
+func NewPlan(architecture *executionmodel.Architecture, configs map[string]*api.WorkerConfiguration, serviceMap, workerMap map[string]string, config *executionmodel.BenchmarkConfig) *Plan {
+	plan := &Plan{
+		Name:           architecture.Name,
+		Throughput:     config.Throughput,
+		Runtime:        config.Runtime,
+		Services:       make([]*ServicePlan, 0, len(architecture.Services)),
+		MeanThroughput: float64(config.Throughput),
+	}
+	units := make(map[string]*api.Unit)
+	unitPlans := make(map[string]*UnitPlan)
+	for _, svc := range architecture.Services {
+		workerConfig := configs[svc.Identifier]
+		if workerConfig.LoadProfile != nil {
+			plan.LoadProfile = workerConfig.LoadProfile
+			plan.MeanThroughput = workerConfig.LoadProfile.MeanThroughput(float64(config.Runtime))
+		}
+		servicePlan := &ServicePlan{
+			Service:        svc.Identifier,
+			WorkerID:       workerConfig.WorkerId,
+			WorkerAddress:  workerMap[svc.Identifier],
+			ServiceAddress: serviceMap[svc.Identifier],
+			Sink:           svc.SinkRef,
+			SinkAddress:    workerConfig.SinkHostPort,
+			SinkProvider:   workerConfig.SinkProvider,
+			Propagation:    workerConfig.Propagation,
+			Units:          make([]*UnitPlan, 0, len(workerConfig.Units)),
+		}
+		for _, unit := range workerConfig.Units {
+			unitPlan := &UnitPlan{
+				Unit:  unit.Identifier,
+				Ratio: unit.ThroughputRatio,
+				Work:  "none",
+			}
+			if unit.WorkBefore != nil {
+				unitPlan.Work = unit.WorkBefore.DistType
+			}
+			//same threshold as workers use to decide whether a unit gets a generator
+			if unit.Users != nil && unit.Users.Count > 0 {
+				unitPlan.Users = unit.Users.Count
+				unitPlan.Arrival = fmt.Sprintf("%d users", unit.Users.Count)
+				plan.ClosedLoop = true
+			} else if unit.ThroughputRatio > 0.000001 {
+				unitPlan.GeneratedPerSecond = plan.MeanThroughput * unit.ThroughputRatio
+				unitPlan.Arrival = worker.DefaultArrivalType
+				if unit.Arrival != nil && unit.Arrival.Type != "" {
+					unitPlan.Arrival = unit.Arrival.Type
+				}
+			}
+			key := svc.Identifier + "/" + unit.Identifier
+			units[key] = unit
+			unitPlans[key] = unitPlan
+			servicePlan.Units = append(servicePlan.Units, unitPlan)
+		}
+		plan.Services = append(plan.Services, servicePlan)
+	}
+	for _, key := range topologicalOrder(units) {
+		unitPlan := unitPlans[key]
+		unitPlan.InvocationsPerSecond += unitPlan.GeneratedPerSecond
+		unitPlan.SpansPerSecond = unitPlan.InvocationsPerSecond * float64(1+len(units[key].Successors))
+		for _, successor := range units[key].Successors {
+			if successorPlan, exists := unitPlans[successor.ServiceId+"/"+successor.UnitId]; exists {
+				successorPlan.InvocationsPerSecond += unitPlan.InvocationsPerSecond
+			}
+		}
+	}
+	for _, servicePlan := range plan.Services {
+		for _, unitPlan := range servicePlan.Units {
+			servicePlan.SpansPerSecond += unitPlan.SpansPerSecond
+		}
+		plan.TotalSpansPerSecond += servicePlan.SpansPerSecond
+	}
+	return plan
+}
+
+//topologicalOrder sorts units (keyed by "service/unit") so that each unit comes before its successors. Units on cycles are omitted.
+func topologicalOrder(units map[string]*api.Unit) []string {
+	inDegree := make(map[string]int, len(units))
+	for key, unit := range units {
+		if _, exists := inDegree[key]; !exists {
+			inDegree[key] = 0
+		}
+		for _, successor := range unit.Successors {
+			if _, exists := units[successor.ServiceId+"/"+successor.UnitId]; exists {
+				inDegree[successor.ServiceId+"/"+successor.UnitId]++
+			}
+		}
+	}
+	queue := make([]string, 0)
+	for key, degree := range inDegree {
+		if degree == 0 {
+			queue = append(queue, key)
+		}
+	}
+	sort.Strings(queue)
+	order := make([]string, 0, len(units))
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		order = append(order, key)
+		for _, successor := range units[key].Successors {
+			successorKey := successor.ServiceId + "/" + successor.UnitId
+			if _, exists := units[successorKey]; !exists {
+				continue
+			}
+			inDegree[successorKey]--
+			if inDegree[successorKey] == 0 {
+				queue = append(queue, successorKey)
+			}
+		}
+	}
+	return order
+}
+
+//WriteText writes a human-readable version of the plan.
+func (p *Plan) WriteText(out io.Writer) error {
+	fmt.Fprintf(out, "Benchmark plan for %q: baseline throughput %d/s, runtime %ds\n\n", p.Name, p.Throughput, p.Runtime)
+	if p.LoadProfile != nil {
+		points := make([]string, len(p.LoadProfile.Points))
+		for i, point := range p.LoadProfile.Points {
+			points[i] = fmt.Sprintf("%gs: %g/s", point.OffsetSeconds, point.Throughput)
+		}
+		fmt.Fprintf(out, "Load profile: %s\nMean throughput %.2f/s over the runtime, all rates are means.\n\n", strings.Join(points, ", "), p.MeanThroughput)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tWORKER\tSERVICE ADDRESS\tSINK\tSINK ADDRESS\tPROVIDER\tPROPAGATION\tSPANS/S")
+	for _, s := range p.Services {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%.2f\n", s.Service, s.WorkerAddress, s.ServiceAddress, s.Sink, s.SinkAddress, orDefault(s.SinkProvider), orDefault(s.Propagation), s.SpansPerSecond)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tUNIT\tWORK\tRATIO\tARRIVAL\tGENERATED/S\tINVOCATIONS/S\tSPANS/S")
+	for _, s := range p.Services {
+		for _, u := range s.Units {
+			arrival := u.Arrival
+			if arrival == "" {
+				arrival = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.4f\t%s\t%.2f\t%.2f\t%.2f\n", s.Service, u.Unit, u.Work, u.Ratio, arrival, u.GeneratedPerSecond, u.InvocationsPerSecond, u.SpansPerSecond)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(out, "\nExpected spans in total: %.2f/s, %.0f over the runtime\n", p.TotalSpansPerSecond, p.TotalSpansPerSecond*float64(p.Runtime)); err != nil {
+		return err
+	}
+	if p.ClosedLoop {
+		_, err := fmt.Fprintln(out, "Units with virtual users run in a closed loop, their rates depend on the response times of the SUT and are not included.")
+		return err
+	}
+	return nil
+}
+
+func orDefault(value string) string {
+	if value == "" {
+		return "(default)"
+	}
+	return value
+}
@@ -0,0 +1,92 @@
+package benchmark
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/worker"
+)
+
+//planConfigs returns the worker configurations of a frontend, whose root unit calls an async unit and both call backend/b, and of a backend with a generator for b and a unit with virtual users.
+func planConfigs(loadProfile *api.LoadProfile) (*executionmodel.Architecture, map[string]*api.WorkerConfiguration) {
+	architecture := &executionmodel.Architecture{
+		Name: "plan",
+		Services: []*executionmodel.Service{
+			{Identifier: "frontend", SinkRef: "jaeger"},
+			{Identifier: "backend", SinkRef: "jaeger"},
+		},
+	}
+	b := &api.UnitRef{ServiceId: "backend", UnitId: "b", IsRemote: true}
+	configs := map[string]*api.WorkerConfiguration{
+		"frontend": {WorkerId: "worker-frontend", SinkHostPort: "jaeger:6831", LoadProfile: loadProfile, Units: []*api.Unit{
+			{Identifier: "root", ThroughputRatio: 1, WorkBefore: &api.Work{DistType: "normal"}, Successors: []*api.UnitRef{b, {ServiceId: "frontend", UnitId: "async"}}},
+			{Identifier: "async", Successors: []*api.UnitRef{b}},
+		}},
+		"backend": {WorkerId: "worker-backend", SinkHostPort: "jaeger:6831", LoadProfile: loadProfile, Units: []*api.Unit{
+			{Identifier: "b", ThroughputRatio: 0.5, Arrival: &api.Arrival{Type: "poisson"}},
+			{Identifier: "users", ThroughputRatio: 1, Users: &api.Users{Count: 4}},
+		}},
+	}
+	return architecture, configs
+}
+
+func TestPlanRates(t *testing.T) {
+	ramp := &api.LoadProfile{Points: []*api.LoadPoint{{OffsetSeconds: 0, Throughput: 0}, {OffsetSeconds: 60, Throughput: 200}}}
+	for _, test := range []struct {
+		name        string
+		throughput  int64
+		loadProfile *api.LoadProfile
+	}{
+		{"baseline", 100, nil},
+		//the ramp has a mean throughput of 100/s, which replaces the baseline
+		{"load profile", 10, ramp},
+	} {
+		architecture, configs := planConfigs(test.loadProfile)
+		plan := NewPlan(architecture, configs, map[string]string{"frontend": "frontend:7000"}, map[string]string{"frontend": "worker-1:8000"},
+			&executionmodel.BenchmarkConfig{Throughput: test.throughput, Runtime: 60})
+		if plan.MeanThroughput != 100 || plan.LoadProfile != test.loadProfile || !plan.ClosedLoop {
+			t.Errorf("%s: expected a closed-loop plan with a mean throughput of 100/s, got %+v", test.name, plan)
+		}
+		expected := map[string]UnitPlan{
+			"frontend/root":  {Work: "normal", Arrival: worker.DefaultArrivalType, GeneratedPerSecond: 100, InvocationsPerSecond: 100, SpansPerSecond: 300},
+			"frontend/async": {Work: "none", InvocationsPerSecond: 100, SpansPerSecond: 200},
+			//b is generated at 50/s and invoked by both frontend units
+			"backend/b":     {Work: "none", Arrival: "poisson", GeneratedPerSecond: 50, InvocationsPerSecond: 250, SpansPerSecond: 250},
+			"backend/users": {Work: "none", Arrival: "4 users", Users: 4},
+		}
+		spans := map[string]float64{"frontend": 500, "backend": 250}
+		for _, service := range plan.Services {
+			if service.SpansPerSecond != spans[service.Service] {
+				t.Errorf("%s: expected %.2f spans/s of %s, got %.2f", test.name, spans[service.Service], service.Service, service.SpansPerSecond)
+			}
+			for _, unit := range service.Units {
+				actual := *unit
+				actual.Unit, actual.Ratio = "", 0
+				if actual != expected[service.Service+"/"+unit.Unit] {
+					t.Errorf("%s: expected %s/%s to be %+v, got %+v", test.name, service.Service, unit.Unit, expected[service.Service+"/"+unit.Unit], actual)
+				}
+			}
+		}
+		if plan.TotalSpansPerSecond != 750 {
+			t.Errorf("%s: expected 750 spans/s in total, got %.2f", test.name, plan.TotalSpansPerSecond)
+		}
+	}
+}
+
+func TestPlanWriteText(t *testing.T) {
+	architecture, configs := planConfigs(&api.LoadProfile{Points: []*api.LoadPoint{{OffsetSeconds: 0, Throughput: 0}, {OffsetSeconds: 60, Throughput: 200}}})
+	plan := NewPlan(architecture, configs, map[string]string{"frontend": "frontend:7000"}, map[string]string{"frontend": "worker-1:8000"},
+		&executionmodel.BenchmarkConfig{Throughput: 100, Runtime: 60})
+	out := &bytes.Buffer{}
+	if err := plan.WriteText(out); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`Benchmark plan for "plan"`, "0s: 0/s, 60s: 200/s", "frontend:7000", "worker-1:8000", "4 users", "poisson"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the plan, got\n%s", expected, out.String())
+		}
+	}
+}
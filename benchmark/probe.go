@@ -0,0 +1,249 @@
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/query"
+)
+
+//IngestionFileName is the name of the report of the ingestion probe in the result directory of a run.
+const IngestionFileName = "ingestion.json"
+
+//ingestionBuckets are the upper bounds of the histogram of visibility latencies in milliseconds. Larger latencies fall into a last bucket without bound.
+var ingestionBuckets = []float64{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 25000, 60000}
+
+//IngestionReport contains the time finished traces took to become visible in the query API of the SUT, measured from the finish time of their root span.
+type IngestionReport struct {
+	Config *executionmodel.IngestionProbeConfig `json:"config"`
+	//Candidates is the number of finished, sampled traces reported by workers, of which Probed were polled.
+	Candidates int `json:"candidates"`
+	Probed     int `json:"probed"`
+	//Skipped counts traces which were chosen, but not polled because MaxInFlight traces were polled already.
+	Skipped int `json:"skipped"`
+	Visible int `json:"visible"`
+	//VisibleOnFirstQuery counts traces which were already visible when the coordinator received their root span. Workers report results periodically,
+	//so the latency of these traces is only an upper bound.
+	VisibleOnFirstQuery int `json:"visibleOnFirstQuery"`
+	TimedOut            int `json:"timedOut"`
+	//QueryErrors counts queries which failed with an error other than not found.
+	QueryErrors int              `json:"queryErrors"`
+	Latency     LatencyStats     `json:"latency"`
+	Histogram   []*LatencyBucket `json:"histogram"`
+	Traces      []*ProbedTrace   `json:"traces"`
+}
+
+//LatencyBucket counts the traces which became visible within UpperBoundMs, but not within the bound of the previous bucket. The last bucket has no bound.
+type LatencyBucket struct {
+	UpperBoundMs float64 `json:"upperBoundMs,omitempty"`
+	Traces       int     `json:"traces"`
+}
+
+//ProbedTrace is a trace polled by the probe. VisibleAt and LatencyMs are only set once the backend returned the root span of the trace.
+type ProbedTrace struct {
+	TraceID     string     `json:"traceId"`
+	Service     string     `json:"service"`
+	Unit        string     `json:"unit"`
+	FinishTime  time.Time  `json:"finishTime"`
+	ReceiveTime time.Time  `json:"receiveTime"`
+	VisibleAt   *time.Time `json:"visibleAt,omitempty"`
+	LatencyMs   float64    `json:"latencyMs,omitempty"`
+	Queries     int        `json:"queries"`
+	Error       string     `json:"error,omitempty"`
+}
+
+//ingestionProbe polls the root spans of a share of the traces received from workers, until they're visible in the query API of the SUT.
+type ingestionProbe struct {
+	client   query.Client
+	config   *executionmodel.IngestionProbeConfig
+	random   *rand.Rand
+	inFlight chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	lock     sync.Mutex
+	report   *IngestionReport
+}
+
+func newIngestionProbe(config *executionmodel.IngestionProbeConfig) (*ingestionProbe, error) {
+	client, err := query.NewClient(config.Backend, config.Endpoint, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	maxInFlight := config.MaxInFlight
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ingestionProbe{
+		client:   client,
+		config:   config,
+		random:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		inFlight: make(chan struct{}, maxInFlight),
+		ctx:      ctx,
+		cancel:   cancel,
+		report:   &IngestionReport{Config: config},
+	}, nil
+}
+
+//observe chooses traces to poll from the records of a result package. A trace is finished once its root span is reported.
+func (p *ingestionProbe) observe(records []*Record) {
+	now := time.Now()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, r := range records {
+		if r.ParentID != 0 || !r.Sampled {
+			continue
+		}
+		p.report.Candidates++
+		if p.random.Float64() >= p.config.Ratio {
+			continue
+		}
+		traceID, err := query.ParseTraceID(r.TraceID)
+		if err != nil {
+			continue
+		}
+		select {
+		case p.inFlight <- struct{}{}:
+		default:
+			p.report.Skipped++
+			continue
+		}
+		trace := &ProbedTrace{
+			TraceID:     traceID.String(),
+			Service:     r.Service,
+			Unit:        r.Unit,
+			FinishTime:  time.Unix(0, r.FinishTime),
+			ReceiveTime: now,
+		}
+		p.report.Probed++
+		p.report.Traces = append(p.report.Traces, trace)
+		p.wg.Add(1)
+		go p.poll(trace, traceID, r.SpanID)
+	}
+}
+
+//poll queries a trace until the backend returns its root span, or the timeout expires.
+func (p *ingestionProbe) poll(trace *ProbedTrace, traceID query.TraceID, rootSpanID uint64) {
+	defer p.wg.Done()
+	defer func() { <-p.inFlight }()
+	deadline := time.Now().Add(p.config.Timeout)
+	for {
+		spans, err := p.client.Trace(p.ctx, traceID)
+		observed := time.Now()
+		p.lock.Lock()
+		trace.Queries++
+		if err != nil && !errors.Is(err, query.ErrTraceNotFound) && p.ctx.Err() == nil {
+			trace.Error = err.Error()
+			p.report.QueryErrors++
+		}
+		for _, s := range spans {
+			if s.SpanID == rootSpanID {
+				trace.VisibleAt = &observed
+				trace.LatencyMs = float64(observed.Sub(trace.FinishTime)) / 1e6
+				trace.Error = ""
+			}
+		}
+		p.lock.Unlock()
+		if trace.VisibleAt != nil || observed.Add(p.config.Interval).After(deadline) {
+			return
+		}
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-time.After(p.config.Interval):
+		}
+	}
+}
+
+//stop cancels polling of all traces, e.g. when the run is aborted.
+func (p *ingestionProbe) stop() {
+	p.cancel()
+}
+
+//close waits until all probed traces are visible or timed out, and returns the report.
+func (p *ingestionProbe) close() *IngestionReport {
+	p.wg.Wait()
+	p.cancel()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	report := p.report
+	report.Histogram = make([]*LatencyBucket, len(ingestionBuckets)+1)
+	for i := range report.Histogram {
+		report.Histogram[i] = &LatencyBucket{}
+		if i < len(ingestionBuckets) {
+			report.Histogram[i].UpperBoundMs = ingestionBuckets[i]
+		}
+	}
+	var latencies []float64
+	for _, t := range report.Traces {
+		if t.VisibleAt == nil {
+			report.TimedOut++
+			continue
+		}
+		report.Visible++
+		if t.Queries == 1 {
+			report.VisibleOnFirstQuery++
+		}
+		latencies = append(latencies, t.LatencyMs)
+		bucket := len(ingestionBuckets)
+		for i, bound := range ingestionBuckets {
+			if t.LatencyMs <= bound {
+				bucket = i
+				break
+			}
+		}
+		report.Histogram[bucket].Traces++
+	}
+	report.Latency = newLatencyStats(latencies)
+	return report
+}
+
+//Write writes the report to the result directory.
+func (r *IngestionReport) Write(resultDir string) error {
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(resultDir, IngestionFileName), data, 0600)
+}
+
+//WriteText writes a summary of the report and the histogram of latencies.
+func (r *IngestionReport) WriteText(out io.Writer) {
+	l := r.Latency
+	fmt.Fprintf(out, "Probed %d of %d finished traces, %d visible, %d timed out, %d skipped, %d query errors.\n", r.Probed, r.Candidates, r.Visible, r.TimedOut, r.Skipped, r.QueryErrors)
+	if r.VisibleOnFirstQuery > 0 {
+		fmt.Fprintf(out, "%d traces were visible on the first query, their latency is an upper bound.\n", r.VisibleOnFirstQuery)
+	}
+	fmt.Fprintf(out, "Visibility latency ms: mean %.2f, p50 %.2f, p90 %.2f, p95 %.2f, p99 %.2f, max %.2f\n", l.Mean, l.P50, l.P90, l.P95, l.P99, l.Max)
+	for _, b := range r.Histogram {
+		bound := "+Inf"
+		if b.UpperBoundMs > 0 {
+			bound = fmt.Sprintf("%.0f", b.UpperBoundMs)
+		}
+		fmt.Fprintf(out, "  <= %6s ms: %d\n", bound, b.Traces)
+	}
+}
+
+//probingResultWriter passes the records of each result package to the probe, after writing them.
+type probingResultWriter struct {
+	ResultWriter
+	probe *ingestionProbe
+}
+
+func (w *probingResultWriter) Write(workerID string, records []*Record) error {
+	if err := w.ResultWriter.Write(workerID, records); err != nil {
+		return err
+	}
+	w.probe.observe(records)
+	return nil
+}
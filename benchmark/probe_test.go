@@ -0,0 +1,100 @@
+package benchmark
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/query"
+)
+
+//newTestProbe creates a probe which polls a fake jaeger query API, returning the given spans after the delay.
+func newTestProbe(t *testing.T, spans []*query.Span, delay time.Duration, ratio float64, maxInFlight int) *ingestionProbe {
+	t.Helper()
+	server := httptest.NewServer(query.NewFakeServer(spans, 0, delay))
+	t.Cleanup(server.Close)
+	probe, err := newIngestionProbe(&executionmodel.IngestionProbeConfig{
+		Backend:     "jaeger",
+		Endpoint:    server.URL,
+		Ratio:       ratio,
+		Timeout:     500 * time.Millisecond,
+		Interval:    20 * time.Millisecond,
+		MaxInFlight: maxInFlight,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return probe
+}
+
+//probeRecords returns the root spans of a trace known to the query API and of one which is never visible, and spans which aren't probed.
+func probeRecords() ([]*query.Span, []*Record) {
+	finished := time.Now().Truncate(time.Microsecond)
+	visible := query.TraceID{High: 1, Low: 2}
+	span := &query.Span{TraceID: visible, SpanID: 10, Service: "frontend", Operation: "root", StartTime: finished.Add(-5 * time.Millisecond), Duration: 5 * time.Millisecond}
+	records := []*Record{
+		{Service: "frontend", Unit: "root", TraceID: visible.String(), SpanID: 10, FinishTime: finished.UnixNano(), Sampled: true},
+		{Service: "frontend", Unit: "root", TraceID: query.TraceID{Low: 3}.String(), SpanID: 11, FinishTime: finished.UnixNano(), Sampled: true},
+		//children and unsampled traces aren't candidates
+		{Service: "backend", Unit: "b", TraceID: visible.String(), SpanID: 12, ParentID: 10, FinishTime: finished.UnixNano(), Sampled: true},
+		{Service: "frontend", Unit: "root", TraceID: query.TraceID{Low: 4}.String(), SpanID: 13, FinishTime: finished.UnixNano()},
+	}
+	return []*query.Span{span}, records
+}
+
+func TestIngestionProbeMeasuresVisibility(t *testing.T) {
+	delay := 100 * time.Millisecond
+	spans, records := probeRecords()
+	probe := newTestProbe(t, spans, delay, 1, 10)
+	probe.observe(records)
+	report := probe.close()
+	if report.Candidates != 2 || report.Probed != 2 || report.Visible != 1 || report.TimedOut != 1 || report.Skipped != 0 || report.QueryErrors != 0 {
+		t.Fatalf("expected one visible and one timed out trace of two candidates, got %+v", report)
+	}
+	var trace *ProbedTrace
+	for _, probed := range report.Traces {
+		if probed.VisibleAt != nil {
+			trace = probed
+		}
+	}
+	//the backend returns the trace after the delay, i.e. after several queries
+	if trace.TraceID != spans[0].TraceID.String() || trace.LatencyMs < float64(delay/time.Millisecond) || trace.Queries < 2 || report.VisibleOnFirstQuery != 0 {
+		t.Errorf("expected the trace to be visible after %v, got %+v", delay, trace)
+	}
+	if report.Latency.Max != trace.LatencyMs {
+		t.Errorf("expected the latency of the visible trace, got %+v", report.Latency)
+	}
+	buckets := 0
+	for _, bucket := range report.Histogram {
+		buckets += bucket.Traces
+		if bucket.Traces > 0 && bucket.UpperBoundMs < trace.LatencyMs {
+			t.Errorf("expected a latency of %.2fms to be in a bucket with a larger bound, got %v", trace.LatencyMs, bucket.UpperBoundMs)
+		}
+	}
+	if buckets != 1 || len(report.Histogram) != len(ingestionBuckets)+1 {
+		t.Errorf("expected one trace in %d buckets, got %d in %d", len(ingestionBuckets)+1, buckets, len(report.Histogram))
+	}
+	out := &bytes.Buffer{}
+	report.WriteText(out)
+	if !strings.Contains(out.String(), "Probed 2 of 2 finished traces, 1 visible, 1 timed out") {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+}
+
+func TestIngestionProbeLimitsTracesInFlight(t *testing.T) {
+	spans, records := probeRecords()
+	probe := newTestProbe(t, spans, time.Hour, 1, 1)
+	probe.observe(records)
+	probe.stop()
+	if report := probe.close(); report.Candidates != 2 || report.Probed != 1 || report.Skipped != 1 {
+		t.Errorf("expected the second trace to be skipped, got %+v", report)
+	}
+	probe = newTestProbe(t, spans, 0, 0, 10)
+	probe.observe(records)
+	if report := probe.close(); report.Candidates != 2 || report.Probed != 0 || len(report.Traces) != 0 {
+		t.Errorf("expected no traces to be probed with a ratio of 0, got %+v", report)
+	}
+}
@@ -0,0 +1,482 @@
+package benchmark
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gocarina/gocsv"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+
+	//registers the pure go "sqlite" driver, so results can be written without cgo
+	_ "modernc.org/sqlite"
+)
+
+//ResultWriter writes the records received from all workers of a run. Write is called concurrently for different workers, but sequentially for the same worker.
+type ResultWriter interface {
+	Write(workerID string, records []*Record) error
+	//Close flushes and closes all files of the run; it is called once after all workers finished.
+	Close() error
+}
+
+type resultWriterFactory func(resultDir string) (ResultWriter, error)
+
+//resultReader reads all records written by the writer of the same format.
+type resultReader func(resultDir string) ([]*Record, error)
+
+var resultWriters map[string]resultWriterFactory
+var resultReaders map[string]resultReader
+
+func init() {
+	resultWriters = map[string]resultWriterFactory{
+		"csv":     newFileResultWriterFactory(".csv", newCSVFileWriter),
+		"jsonl":   newFileResultWriterFactory(".jsonl", newJSONLinesFileWriter),
+		"parquet": newFileResultWriterFactory(".parquet", newParquetFileWriter),
+		"sqlite":  newSQLiteResultWriter,
+	}
+	resultReaders = map[string]resultReader{
+		"csv":     newFileResultReader(".csv", readCSVFile),
+		"jsonl":   newFileResultReader(".jsonl", readJSONLinesFile),
+		"parquet": newFileResultReader(".parquet", readParquetFile),
+		"sqlite":  readSQLite,
+	}
+}
+
+//DefaultResultFormat is used if a benchmark doesn't configure a result format.
+const DefaultResultFormat = "csv"
+
+//NewResultWriter creates a writer for the given format, which writes to files in resultDir.
+func NewResultWriter(format string, resultDir string) (ResultWriter, error) {
+	if format == "" {
+		format = DefaultResultFormat
+	}
+	factory, ok := resultWriters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown result format %s, available formats are: %s", format, strings.Join(ResultFormats(), ", "))
+	}
+	return factory(resultDir)
+}
+
+//ReadResults reads the records of all workers of a run. The format is taken from the manifest of the run, or detected from the result files, if there is no manifest.
+func ReadResults(resultDir string) ([]*Record, error) {
+	format, err := detectResultFormat(resultDir)
+	if err != nil {
+		return nil, err
+	}
+	read, ok := resultReaders[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown result format %s", format)
+	}
+	return read(resultDir)
+}
+
+func detectResultFormat(resultDir string) (string, error) {
+	if manifest, err := ReadManifest(resultDir); err == nil {
+		if manifest.ResultFormat == "" {
+			return DefaultResultFormat, nil
+		}
+		return strings.ToLower(manifest.ResultFormat), nil
+	}
+	if _, err := os.Stat(filepath.Join(resultDir, "results.db")); err == nil {
+		return "sqlite", nil
+	}
+	for _, format := range []string{"parquet", "jsonl", "csv"} {
+		files, _ := filepath.Glob(filepath.Join(resultDir, "*."+format))
+		if len(files) > 0 {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("no results found in %s", resultDir)
+}
+
+//ResultFormats returns the names of all result formats in alphabetical order.
+func ResultFormats() []string {
+	formats := make([]string, 0, len(resultWriters))
+	for format := range resultWriters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+//fileWriter writes records of a single worker to a file.
+type fileWriter interface {
+	Write(records []*Record) error
+	Close() error
+}
+
+//fileResultWriter writes the records of each worker to a separate file, named after the worker.
+type fileResultWriter struct {
+	resultDir string
+	extension string
+	open      func(*os.File) (fileWriter, error)
+	lock      sync.Mutex
+	files     map[string]fileWriter
+}
+
+func newFileResultWriterFactory(extension string, open func(*os.File) (fileWriter, error)) resultWriterFactory {
+	return func(resultDir string) (ResultWriter, error) {
+		return &fileResultWriter{
+			resultDir: resultDir,
+			extension: extension,
+			open:      open,
+			files:     make(map[string]fileWriter),
+		}, nil
+	}
+}
+
+func (w *fileResultWriter) Write(workerID string, records []*Record) error {
+	file, err := w.file(workerID)
+	if err != nil {
+		return err
+	}
+	return file.Write(records)
+}
+
+//file returns the writer of a worker, the file is created on the first write.
+func (w *fileResultWriter) file(workerID string) (fileWriter, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if file, ok := w.files[workerID]; ok {
+		return file, nil
+	}
+	handle, err := os.Create(filepath.Join(w.resultDir, workerID+w.extension))
+	if err != nil {
+		return nil, err
+	}
+	file, err := w.open(handle)
+	if err != nil {
+		handle.Close()
+		return nil, err
+	}
+	w.files[workerID] = file
+	return file, nil
+}
+
+func (w *fileResultWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var firstErr error
+	for workerID, file := range w.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("couldn't close results of worker %s: %v", workerID, err)
+		}
+	}
+	w.files = make(map[string]fileWriter)
+	return firstErr
+}
+
+type csvFileWriter struct {
+	file       *os.File
+	writer     *gocsv.SafeCSVWriter
+	firstWrite bool
+}
+
+func newCSVFileWriter(file *os.File) (fileWriter, error) {
+	return &csvFileWriter{
+		file:       file,
+		writer:     CSVWriterToFile(file),
+		firstWrite: true,
+	}, nil
+}
+
+func (w *csvFileWriter) Write(records []*Record) error {
+	if w.firstWrite {
+		w.firstWrite = false
+		return gocsv.MarshalCSV(records, w.writer)
+	}
+	return gocsv.MarshalCSVWithoutHeaders(records, w.writer)
+}
+
+func (w *csvFileWriter) Close() error {
+	return w.file.Close()
+}
+
+//jsonLinesFileWriter writes one JSON object per record and line.
+type jsonLinesFileWriter struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+func newJSONLinesFileWriter(file *os.File) (fileWriter, error) {
+	return &jsonLinesFileWriter{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+func (w *jsonLinesFileWriter) Write(records []*Record) error {
+	for _, record := range records {
+		if err := w.encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *jsonLinesFileWriter) Close() error {
+	return w.file.Close()
+}
+
+//parquetRecord is the parquet schema of Record. The parquet library can't marshal uint64, so IDs are converted to int64 and annotated as unsigned.
+type parquetRecord struct {
+	Service     string `parquet:"name=Service, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Unit        string `parquet:"name=Unit, type=BYTE_ARRAY, convertedtype=UTF8"`
+	TraceNumber int64  `parquet:"name=TraceNumber, type=INT64"`
+	SpanNumber  int64  `parquet:"name=SpanNumber, type=INT64"`
+	TraceID     string `parquet:"name=TraceID, type=BYTE_ARRAY, convertedtype=UTF8"`
+	SpanID      int64  `parquet:"name=SpanID, type=INT64, convertedtype=UINT_64"`
+	ParentID    int64  `parquet:"name=ParentID, type=INT64, convertedtype=UINT_64"`
+	StartTime   int64  `parquet:"name=StartTime, type=INT64"`
+	FinishTime  int64  `parquet:"name=FinishTime, type=INT64"`
+	Sampled     bool   `parquet:"name=Sampled, type=BOOLEAN"`
+	Status      string `parquet:"name=Status, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Error       string `parquet:"name=Error, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+type parquetFileWriter struct {
+	file   *os.File
+	writer *writer.ParquetWriter
+}
+
+func newParquetFileWriter(file *os.File) (fileWriter, error) {
+	pw, err := writer.NewParquetWriterFromWriter(file, new(parquetRecord), 1)
+	if err != nil {
+		return nil, err
+	}
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &parquetFileWriter{
+		file:   file,
+		writer: pw,
+	}, nil
+}
+
+func (w *parquetFileWriter) Write(records []*Record) error {
+	for _, r := range records {
+		record := &parquetRecord{
+			Service:     r.Service,
+			Unit:        r.Unit,
+			TraceNumber: r.TraceNumber,
+			SpanNumber:  r.SpanNumber,
+			TraceID:     r.TraceID,
+			SpanID:      int64(r.SpanID),
+			ParentID:    int64(r.ParentID),
+			StartTime:   r.StartTime,
+			FinishTime:  r.FinishTime,
+			Sampled:     r.Sampled,
+			Status:      r.Status,
+			Error:       r.Error,
+		}
+		if err := w.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Close writes the footer of the parquet file, a file without it is unreadable.
+func (w *parquetFileWriter) Close() error {
+	if err := w.writer.WriteStop(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+func newFileResultReader(extension string, readFile func(*os.File) ([]*Record, error)) resultReader {
+	return func(resultDir string) ([]*Record, error) {
+		files, err := filepath.Glob(filepath.Join(resultDir, "*"+extension))
+		if err != nil {
+			return nil, err
+		}
+		records := make([]*Record, 0)
+		for _, name := range files {
+			file, err := os.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			fileRecords, err := readFile(file)
+			file.Close()
+			if err != nil {
+				return nil, fmt.Errorf("couldn't read %s: %v", name, err)
+			}
+			records = append(records, fileRecords...)
+		}
+		return records, nil
+	}
+}
+
+func readCSVFile(file *os.File) ([]*Record, error) {
+	records := make([]*Record, 0)
+	//workers without results may have left an empty file
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		return records, nil
+	}
+	if err := gocsv.UnmarshalFile(file, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func readJSONLinesFile(file *os.File) ([]*Record, error) {
+	records := make([]*Record, 0)
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		record := &Record{}
+		if err := decoder.Decode(record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func readParquetFile(file *os.File) ([]*Record, error) {
+	pr, err := reader.NewParquetReader(&parquetFileReader{File: file}, new(parquetRecord), 1)
+	if err != nil {
+		return nil, err
+	}
+	defer pr.ReadStop()
+	rows := make([]parquetRecord, pr.GetNumRows())
+	if err := pr.Read(&rows); err != nil {
+		return nil, err
+	}
+	records := make([]*Record, len(rows))
+	for i, r := range rows {
+		records[i] = &Record{
+			Service:     r.Service,
+			Unit:        r.Unit,
+			TraceNumber: r.TraceNumber,
+			SpanNumber:  r.SpanNumber,
+			TraceID:     r.TraceID,
+			SpanID:      uint64(r.SpanID),
+			ParentID:    uint64(r.ParentID),
+			StartTime:   r.StartTime,
+			FinishTime:  r.FinishTime,
+			Sampled:     r.Sampled,
+			Status:      r.Status,
+			Error:       r.Error,
+		}
+	}
+	return records, nil
+}
+
+//parquetFileReader adapts an open file to the source interface of the parquet reader, which reopens the file for each column.
+type parquetFileReader struct {
+	*os.File
+}
+
+func (f *parquetFileReader) Open(name string) (source.ParquetFile, error) {
+	if name == "" {
+		name = f.Name()
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetFileReader{File: file}, nil
+}
+
+func (f *parquetFileReader) Create(name string) (source.ParquetFile, error) {
+	return nil, fmt.Errorf("parquet results are read only")
+}
+
+//sqliteResultWriter writes the records of all workers to the table spans of a single database file.
+type sqliteResultWriter struct {
+	db *sql.DB
+}
+
+//sqliteSchema has the columns of Record. SpanID and ParentID are unsigned in Record, but stored as signed integers with the same bits.
+const sqliteSchema = `CREATE TABLE spans (
+	Worker TEXT NOT NULL,
+	Service TEXT NOT NULL,
+	Unit TEXT NOT NULL,
+	TraceNumber INTEGER,
+	SpanNumber INTEGER,
+	TraceID TEXT NOT NULL,
+	SpanID INTEGER NOT NULL,
+	ParentID INTEGER NOT NULL,
+	StartTime INTEGER NOT NULL,
+	FinishTime INTEGER NOT NULL,
+	Sampled BOOLEAN NOT NULL,
+	Status TEXT NOT NULL,
+	Error TEXT NOT NULL
+);
+CREATE INDEX spans_trace ON spans (TraceID);`
+
+const sqliteInsert = `INSERT INTO spans (Worker, Service, Unit, TraceNumber, SpanNumber, TraceID, SpanID, ParentID, StartTime, FinishTime, Sampled, Status, Error)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+func newSQLiteResultWriter(resultDir string) (ResultWriter, error) {
+	db, err := sql.Open("sqlite", filepath.Join(resultDir, "results.db"))
+	if err != nil {
+		return nil, err
+	}
+	//sqlite allows a single writer only, so writes of all workers share one connection
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("couldn't create table: %v", err)
+	}
+	return &sqliteResultWriter{db: db}, nil
+}
+
+//Write inserts the records in a single transaction.
+func (w *sqliteResultWriter) Write(workerID string, records []*Record) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(sqliteInsert)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range records {
+		_, err := stmt.Exec(workerID, r.Service, r.Unit, r.TraceNumber, r.SpanNumber, r.TraceID, int64(r.SpanID), int64(r.ParentID), r.StartTime, r.FinishTime, r.Sampled, r.Status, r.Error)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (w *sqliteResultWriter) Close() error {
+	return w.db.Close()
+}
+
+func readSQLite(resultDir string) ([]*Record, error) {
+	db, err := sql.Open("sqlite", filepath.Join(resultDir, "results.db"))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT Service, Unit, TraceNumber, SpanNumber, TraceID, SpanID, ParentID, StartTime, FinishTime, Sampled, Status, Error FROM spans")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := make([]*Record, 0)
+	for rows.Next() {
+		r := &Record{}
+		var spanID, parentID int64
+		err := rows.Scan(&r.Service, &r.Unit, &r.TraceNumber, &r.SpanNumber, &r.TraceID, &spanID, &parentID, &r.StartTime, &r.FinishTime, &r.Sampled, &r.Status, &r.Error)
+		if err != nil {
+			return nil, err
+		}
+		r.SpanID = uint64(spanID)
+		r.ParentID = uint64(parentID)
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,86 @@
+package benchmark
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+//resultRecords returns records which use the full range of IDs, and errors with characters that need quoting.
+func resultRecords(service string) []*Record {
+	return []*Record{
+		{Service: service, Unit: "root", TraceNumber: 1, SpanNumber: 1, TraceID: "0000000000000000000000000000000a", SpanID: math.MaxUint64, StartTime: 1000, FinishTime: 2000, Sampled: true, Status: "OK"},
+		{Service: service, Unit: "b", TraceNumber: 1, SpanNumber: 2, TraceID: "0000000000000000000000000000000a", SpanID: 1 << 63, ParentID: math.MaxUint64, StartTime: 1100, FinishTime: 1900, Status: "Unavailable",
+			Error: "call to backend/b failed: \"connection refused\", retrying\nlater"},
+		{Service: service, Unit: "root", TraceNumber: 2, SpanNumber: 1, TraceID: "ffffffffffffffffffffffffffffffff", SpanID: 1, StartTime: -1, FinishTime: 0, Status: "OK"},
+	}
+}
+
+func sortRecords(records []*Record) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Service != records[j].Service {
+			return records[i].Service < records[j].Service
+		}
+		if records[i].TraceNumber != records[j].TraceNumber {
+			return records[i].TraceNumber < records[j].TraceNumber
+		}
+		return records[i].SpanNumber < records[j].SpanNumber
+	})
+}
+
+func TestResultWritersRoundTrip(t *testing.T) {
+	for _, format := range ResultFormats() {
+		dir := t.TempDir()
+		writer, err := NewResultWriter(format, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var expected []*Record
+		//workers write several batches each, the file of a worker is created with the first batch
+		for _, service := range []string{"frontend", "backend"} {
+			records := resultRecords(service)
+			for _, batch := range [][]*Record{records[:1], records[1:]} {
+				if err := writer.Write("worker-"+service, batch); err != nil {
+					t.Fatalf("%s: %v", format, err)
+				}
+			}
+			expected = append(expected, records...)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		//without a manifest, the format is detected from the files
+		actual, err := ReadResults(dir)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		sortRecords(expected)
+		sortRecords(actual)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: records changed by writing and reading them", format)
+			for i := range actual {
+				if i < len(expected) && !reflect.DeepEqual(actual[i], expected[i]) {
+					t.Errorf("%s: expected %+v, got %+v", format, expected[i], actual[i])
+				}
+			}
+		}
+	}
+}
+
+func TestResultWriterFormats(t *testing.T) {
+	if _, err := NewResultWriter("xml", t.TempDir()); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+	writer, err := NewResultWriter("", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	if _, ok := writer.(*fileResultWriter); !ok || writer.(*fileResultWriter).extension != "."+DefaultResultFormat {
+		t.Errorf("expected %s results without a format, got %T", DefaultResultFormat, writer)
+	}
+	if _, err := ReadResults(t.TempDir()); err == nil {
+		t.Errorf("expected an error for a directory without results")
+	}
+}
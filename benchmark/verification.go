@@ -0,0 +1,354 @@
+package benchmark
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dominik-/t-race/query"
+)
+
+//VerificationConfig controls how the traces of a run are queried from the backend.
+type VerificationConfig struct {
+	//Timeout is the time after which verification stops querying traces which are still incomplete. Incomplete traces are queried again every Interval.
+	Timeout     time.Duration
+	Interval    time.Duration
+	Concurrency int
+	//MaxTraces limits the number of verified traces, which are then evenly spread over the run. 0 verifies all sampled traces.
+	MaxTraces int
+}
+
+//VerificationReport compares the sampled spans of a run with the spans stored by the backend, i.e. what the SUT actually made available for queries.
+type VerificationReport struct {
+	//SampledTraces is the number of traces with sampled spans in the results, of which Traces were verified.
+	SampledTraces   int `json:"sampledTraces"`
+	Traces          int `json:"traces"`
+	Found           int `json:"found"`
+	Complete        int `json:"complete"`
+	ExpectedSpans   int `json:"expectedSpans"`
+	MissingSpans    int `json:"missingSpans"`
+	UnexpectedSpans int `json:"unexpectedSpans"`
+	//SpanCountMismatches counts traces for which the backend returned a different number of spans than expected.
+	SpanCountMismatches int `json:"spanCountMismatches"`
+	//QueryErrors counts traces whose last query failed with an error other than not found.
+	QueryErrors int `json:"queryErrors"`
+	//IngestionDelay is the time from the end of the last span of a trace until a query first returned the complete trace. It is only meaningful if verification
+	//starts right after the run, otherwise it is dominated by the time in between.
+	IngestionDelay     LatencyStats         `json:"ingestionDelay"`
+	MissingByOperation []*OperationMissing  `json:"missingByOperation"`
+	Incomplete         []*TraceVerification `json:"incomplete"`
+}
+
+//OperationMissing counts the missing spans of an operation, i.e. a unit or the client span of a call to a unit.
+type OperationMissing struct {
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+	Expected  int    `json:"expected"`
+	Missing   int    `json:"missing"`
+}
+
+//TraceVerification is the result of querying a single trace.
+type TraceVerification struct {
+	TraceID         string         `json:"traceId"`
+	RootService     string         `json:"rootService,omitempty"`
+	RootUnit        string         `json:"rootUnit,omitempty"`
+	ExpectedSpans   int            `json:"expectedSpans"`
+	BackendSpans    int            `json:"backendSpans"`
+	Missing         []*MissingSpan `json:"missing,omitempty"`
+	UnexpectedSpans int            `json:"unexpectedSpans"`
+	Complete        bool           `json:"complete"`
+	//IngestionDelayMs is only set for complete traces.
+	IngestionDelayMs float64 `json:"ingestionDelayMs,omitempty"`
+	Queries          int     `json:"queries"`
+	Error            string  `json:"error,omitempty"`
+}
+
+//MissingSpan is an expected span, which the backend didn't return.
+type MissingSpan struct {
+	SpanID    string `json:"spanId"`
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+}
+
+//expectedTrace contains the spans the backend should return for a trace.
+type expectedTrace struct {
+	id         query.TraceID
+	spans      []*query.Span
+	rootUnit   string
+	rootSvc    string
+	lastFinish time.Time
+}
+
+//ExpectedSpans returns the spans a backend should store for the sampled records of a run: the span of each unit invocation, and the client span of each call,
+//which workers send to the backend, but don't report. Service, start and duration of client spans aren't known and are taken from the span of the called unit.
+func ExpectedSpans(records []*Record) ([]*query.Span, error) {
+	traces, err := expectedTraces(records)
+	if err != nil {
+		return nil, err
+	}
+	var spans []*query.Span
+	for _, t := range traces {
+		spans = append(spans, t.spans...)
+	}
+	return spans, nil
+}
+
+//expectedTraces groups the sampled records by trace, ordered by the start of their first span.
+func expectedTraces(records []*Record) ([]*expectedTrace, error) {
+	byTrace := make(map[string][]*Record)
+	var order []string
+	for _, r := range records {
+		if !r.Sampled {
+			continue
+		}
+		if _, ok := byTrace[r.TraceID]; !ok {
+			order = append(order, r.TraceID)
+		}
+		byTrace[r.TraceID] = append(byTrace[r.TraceID], r)
+	}
+	traces := make([]*expectedTrace, 0, len(order))
+	firstStart := make(map[*expectedTrace]int64, len(order))
+	for _, traceID := range order {
+		id, err := query.ParseTraceID(traceID)
+		if err != nil {
+			return nil, err
+		}
+		trace := &expectedTrace{id: id}
+		traceRecords := byTrace[traceID]
+		recorded := make(map[uint64]bool, len(traceRecords))
+		for _, r := range traceRecords {
+			recorded[r.SpanID] = true
+		}
+		clientSpans := make(map[uint64]bool)
+		first := traceRecords[0].StartTime
+		for _, r := range traceRecords {
+			span := &query.Span{
+				TraceID:   id,
+				SpanID:    r.SpanID,
+				ParentID:  r.ParentID,
+				Service:   r.Service,
+				Operation: r.Unit,
+				StartTime: time.Unix(0, r.StartTime),
+				Duration:  time.Duration(r.FinishTime - r.StartTime),
+			}
+			trace.spans = append(trace.spans, span)
+			if r.ParentID == 0 {
+				trace.rootSvc, trace.rootUnit = r.Service, r.Unit
+			} else if !recorded[r.ParentID] && !clientSpans[r.ParentID] {
+				clientSpans[r.ParentID] = true
+				trace.spans = append(trace.spans, &query.Span{
+					TraceID:   id,
+					SpanID:    r.ParentID,
+					Operation: "invoke-" + r.Unit,
+					StartTime: span.StartTime,
+					Duration:  span.Duration,
+				})
+			}
+			if finish := time.Unix(0, r.FinishTime); finish.After(trace.lastFinish) {
+				trace.lastFinish = finish
+			}
+			if r.StartTime < first {
+				first = r.StartTime
+			}
+		}
+		firstStart[trace] = first
+		traces = append(traces, trace)
+	}
+	sort.SliceStable(traces, func(i, j int) bool {
+		return firstStart[traces[i]] < firstStart[traces[j]]
+	})
+	return traces, nil
+}
+
+//Verify queries the sampled traces of a run from the backend and compares them with the expected spans. Traces are queried concurrently, and queried again
+//until they're complete or the timeout expires.
+func Verify(ctx context.Context, records []*Record, client query.Client, config VerificationConfig) (*VerificationReport, error) {
+	traces, err := expectedTraces(records)
+	if err != nil {
+		return nil, err
+	}
+	report := &VerificationReport{SampledTraces: len(traces)}
+	if config.MaxTraces > 0 && config.MaxTraces < len(traces) {
+		selected := make([]*expectedTrace, config.MaxTraces)
+		for i := range selected {
+			selected[i] = traces[i*len(traces)/config.MaxTraces]
+		}
+		traces = selected
+	}
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	deadline := time.Now().Add(config.Timeout)
+	results := make([]*TraceVerification, len(traces))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				results[index] = verifyTrace(ctx, client, traces[index], deadline, config.Interval)
+			}
+		}()
+	}
+	for i := range traces {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	report.Traces = len(traces)
+	missingByOperation := make(map[string]*OperationMissing)
+	var operations []string
+	var delays []float64
+	for i, result := range results {
+		for _, span := range traces[i].spans {
+			key := span.Service + "/" + span.Operation
+			if _, ok := missingByOperation[key]; !ok {
+				missingByOperation[key] = &OperationMissing{Service: span.Service, Operation: span.Operation}
+				operations = append(operations, key)
+			}
+			missingByOperation[key].Expected++
+		}
+		for _, span := range result.Missing {
+			missingByOperation[span.Service+"/"+span.Operation].Missing++
+		}
+		report.ExpectedSpans += result.ExpectedSpans
+		report.MissingSpans += len(result.Missing)
+		report.UnexpectedSpans += result.UnexpectedSpans
+		if result.BackendSpans > 0 {
+			report.Found++
+		}
+		if result.BackendSpans != result.ExpectedSpans {
+			report.SpanCountMismatches++
+		}
+		if result.Error != "" {
+			report.QueryErrors++
+		}
+		if result.Complete {
+			report.Complete++
+			delays = append(delays, result.IngestionDelayMs)
+		} else {
+			report.Incomplete = append(report.Incomplete, result)
+		}
+	}
+	report.IngestionDelay = newLatencyStats(delays)
+	sort.Strings(operations)
+	for _, key := range operations {
+		report.MissingByOperation = append(report.MissingByOperation, missingByOperation[key])
+	}
+	return report, ctx.Err()
+}
+
+func verifyTrace(ctx context.Context, client query.Client, trace *expectedTrace, deadline time.Time, interval time.Duration) *TraceVerification {
+	result := &TraceVerification{
+		TraceID:       trace.id.String(),
+		RootService:   trace.rootSvc,
+		RootUnit:      trace.rootUnit,
+		ExpectedSpans: len(trace.spans),
+	}
+	for {
+		result.Queries++
+		spans, err := client.Trace(ctx, trace.id)
+		observed := time.Now()
+		if err != nil && !errors.Is(err, query.ErrTraceNotFound) {
+			result.Error = err.Error()
+		} else {
+			result.Error = ""
+			compareSpans(result, trace.spans, spans)
+			if result.Complete {
+				result.IngestionDelayMs = float64(observed.Sub(trace.lastFinish)) / 1e6
+				return result
+			}
+		}
+		if observed.Add(interval).After(deadline) {
+			return result
+		}
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(interval):
+		}
+	}
+}
+
+//compareSpans sets the span counts and missing spans of a trace in result.
+func compareSpans(result *TraceVerification, expected, actual []*query.Span) {
+	found := make(map[uint64]bool, len(actual))
+	for _, span := range actual {
+		found[span.SpanID] = true
+	}
+	known := make(map[uint64]bool, len(expected))
+	result.Missing = nil
+	for _, span := range expected {
+		known[span.SpanID] = true
+		if !found[span.SpanID] {
+			result.Missing = append(result.Missing, &MissingSpan{
+				SpanID:    fmt.Sprintf("%016x", span.SpanID),
+				Service:   span.Service,
+				Operation: span.Operation,
+			})
+		}
+	}
+	result.UnexpectedSpans = 0
+	for id := range found {
+		if !known[id] {
+			result.UnexpectedSpans++
+		}
+	}
+	result.BackendSpans = len(actual)
+	result.Complete = len(result.Missing) == 0
+}
+
+//maxListedTraces is the number of incomplete traces listed by WriteText.
+const maxListedTraces = 20
+
+//WriteText writes a human-readable version of the verification report.
+func (r *VerificationReport) WriteText(out io.Writer) error {
+	fmt.Fprintf(out, "Verified %d of %d sampled traces\n\n", r.Traces, r.SampledTraces)
+	fmt.Fprintf(out, "Found traces:          %d (%s)\n", r.Found, percent(r.Found, r.Traces))
+	fmt.Fprintf(out, "Complete traces:       %d (%s)\n", r.Complete, percent(r.Complete, r.Traces))
+	fmt.Fprintf(out, "Missing spans:         %d of %d (%s)\n", r.MissingSpans, r.ExpectedSpans, percent(r.MissingSpans, r.ExpectedSpans))
+	fmt.Fprintf(out, "Unexpected spans:      %d\n", r.UnexpectedSpans)
+	fmt.Fprintf(out, "Span count mismatches: %d\n", r.SpanCountMismatches)
+	fmt.Fprintf(out, "Query errors:          %d\n", r.QueryErrors)
+	d := r.IngestionDelay
+	fmt.Fprintf(out, "Ingestion delay ms:    mean %.2f, p50 %.2f, p90 %.2f, p95 %.2f, p99 %.2f, max %.2f\n\n", d.Mean, d.P50, d.P90, d.P95, d.P99, d.Max)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tOPERATION\tEXPECTED\tMISSING")
+	for _, o := range r.MissingByOperation {
+		service := o.Service
+		if service == "" {
+			service = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d (%s)\n", service, o.Operation, o.Expected, o.Missing, percent(o.Missing, o.Expected))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(r.Incomplete) == 0 {
+		return nil
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INCOMPLETE TRACE\tROOT SERVICE\tROOT UNIT\tEXPECTED\tBACKEND\tMISSING\tUNEXPECTED\tERROR")
+	for i, t := range r.Incomplete {
+		if i == maxListedTraces {
+			break
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n", t.TraceID, t.RootService, t.RootUnit, t.ExpectedSpans, t.BackendSpans, len(t.Missing), t.UnexpectedSpans, t.Error)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(r.Incomplete) > maxListedTraces {
+		fmt.Fprintf(out, "... and %d more, use --format json to list all\n", len(r.Incomplete)-maxListedTraces)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/query"
+)
+
+//testRecords returns the records of n traces, in which frontend/root calls backend/b. The client span of the call isn't recorded, so each trace has 3 expected spans.
+func testRecords(n int, finish time.Time) []*Record {
+	var records []*Record
+	for i := 0; i < n; i++ {
+		traceID := fmt.Sprintf("%032x", i+1)
+		root := uint64(i*10 + 1)
+		call := root + 1
+		start := finish.Add(-10 * time.Millisecond).UnixNano()
+		records = append(records,
+			&Record{Service: "frontend", Unit: "root", TraceID: traceID, SpanID: root, StartTime: start, FinishTime: finish.UnixNano(), Sampled: true},
+			&Record{Service: "backend", Unit: "b", TraceID: traceID, SpanID: root + 2, ParentID: call, StartTime: start + int64(time.Millisecond), FinishTime: finish.UnixNano(), Sampled: true},
+		)
+	}
+	//unsampled traces aren't expected in the backend
+	records = append(records, &Record{Service: "frontend", Unit: "root", TraceID: fmt.Sprintf("%032x", 999), SpanID: 9990, StartTime: finish.UnixNano(), FinishTime: finish.UnixNano()})
+	return records
+}
+
+func verify(t *testing.T, records []*Record, server *query.FakeServer, config VerificationConfig) *VerificationReport {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client, err := query.NewClient("jaeger", httpServer.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Verify(context.Background(), records, client, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestVerifyCompleteTraces(t *testing.T) {
+	records := testRecords(4, time.Now())
+	spans, err := ExpectedSpans(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 12 {
+		t.Fatalf("expected 3 spans per sampled trace, got %d", len(spans))
+	}
+	report := verify(t, records, query.NewFakeServer(spans, 0, 0), VerificationConfig{Timeout: time.Second, Interval: 10 * time.Millisecond, Concurrency: 2})
+	if report.SampledTraces != 4 || report.Traces != 4 || report.Found != 4 || report.Complete != 4 {
+		t.Errorf("expected 4 found and complete traces, got %+v", report)
+	}
+	if report.ExpectedSpans != 12 || report.MissingSpans != 0 || report.UnexpectedSpans != 0 || report.SpanCountMismatches != 0 || report.QueryErrors != 0 {
+		t.Errorf("expected no missing or unexpected spans, got %+v", report)
+	}
+}
+
+func TestVerifyReportsMissingSpansAndMismatches(t *testing.T) {
+	records := testRecords(3, time.Now())
+	spans, err := ExpectedSpans(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored []*query.Span
+	for _, s := range spans {
+		//the backend lost the span of b in the first trace
+		if s.TraceID.Low == 1 && s.Operation == "b" {
+			continue
+		}
+		stored = append(stored, s)
+	}
+	//and stores a span nobody sent in the second trace
+	stored = append(stored, &query.Span{TraceID: query.TraceID{Low: 2}, SpanID: 12345, Service: "other", Operation: "x", StartTime: time.Now().Add(-time.Second), Duration: time.Millisecond})
+	report := verify(t, records, query.NewFakeServer(stored, 0, 0), VerificationConfig{Timeout: 100 * time.Millisecond, Interval: 20 * time.Millisecond, Concurrency: 3})
+	if report.Found != 3 || report.Complete != 2 {
+		t.Errorf("expected 3 found and 2 complete traces, got %d and %d", report.Found, report.Complete)
+	}
+	if report.MissingSpans != 1 || report.UnexpectedSpans != 1 || report.SpanCountMismatches != 2 {
+		t.Errorf("expected 1 missing, 1 unexpected span and 2 span count mismatches, got %d, %d and %d", report.MissingSpans, report.UnexpectedSpans, report.SpanCountMismatches)
+	}
+	if len(report.Incomplete) != 1 || len(report.Incomplete[0].Missing) != 1 || report.Incomplete[0].Missing[0].Operation != "b" || report.Incomplete[0].Queries < 2 {
+		t.Errorf("expected the first trace to be incomplete after several queries, missing the span of b, got %+v", report.Incomplete)
+	}
+	for _, m := range report.MissingByOperation {
+		expectedMissing := 0
+		if m.Service == "backend" && m.Operation == "b" {
+			expectedMissing = 1
+		}
+		if m.Expected != 3 || m.Missing != expectedMissing {
+			t.Errorf("expected %d of 3 spans of %s/%s to be missing, got %d of %d", expectedMissing, m.Service, m.Operation, m.Missing, m.Expected)
+		}
+	}
+}
+
+func TestVerifyMeasuresIngestionDelay(t *testing.T) {
+	delay := 300 * time.Millisecond
+	records := testRecords(2, time.Now())
+	spans, err := ExpectedSpans(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := verify(t, records, query.NewFakeServer(spans, 0, delay), VerificationConfig{Timeout: 5 * time.Second, Interval: 20 * time.Millisecond, Concurrency: 2})
+	if report.Complete != 2 {
+		t.Fatalf("expected 2 complete traces, got %d", report.Complete)
+	}
+	min := float64(delay / time.Millisecond)
+	if report.IngestionDelay.P50 < min || report.IngestionDelay.Max > min+1000 {
+		t.Errorf("expected ingestion delay of about %.0fms, got %+v", min, report.IngestionDelay)
+	}
+	for _, result := range report.Incomplete {
+		t.Errorf("expected no incomplete traces, got %+v", result)
+	}
+}
+
+func TestVerifyMaxTraces(t *testing.T) {
+	records := testRecords(10, time.Now())
+	spans, err := ExpectedSpans(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := verify(t, records, query.NewFakeServer(spans, 0, 0), VerificationConfig{Timeout: time.Second, Interval: 10 * time.Millisecond, MaxTraces: 3})
+	if report.SampledTraces != 10 || report.Traces != 3 || report.Complete != 3 {
+		t.Errorf("expected 3 of 10 traces to be verified, got %d of %d", report.Traces, report.SampledTraces)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/dominik-/t-race/benchmark"
+	"github.com/spf13/cobra"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [results directory]",
+	Short: "Analyzes the results of a benchmark run.",
+	Long: `Reads the records of all workers of a run, in any result format, and reconstructs traces by their trace ID. Reports latency percentiles, achieved vs. target throughput,
+sampled and error ratios per service and unit, and the distribution of spans per trace. Targets and the completeness of traces are only reported if the run has a manifest.`,
+	Args: cobra.ExactArgs(1),
+	Run:  AnalyzeResults,
+}
+
+var (
+	analyzeFormat string
+	analyzeOutput string
+)
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+	analyzeCmd.Flags().StringVar(&analyzeFormat, "format", "text", "Format of the report. Can be text, json or html.")
+	analyzeCmd.Flags().StringVarP(&analyzeOutput, "output", "o", "", "File to write the report to. Defaults to stdout.")
+}
+
+func AnalyzeResults(cmd *cobra.Command, args []string) {
+	resultDir := args[0]
+	records, err := benchmark.ReadResults(resultDir)
+	if err != nil {
+		log.Fatalf("Couldn't read results: %v", err)
+	}
+	manifest, err := benchmark.ReadManifest(resultDir)
+	if err != nil {
+		log.Printf("No manifest found, targets won't be reported: %v", err)
+		manifest = nil
+	}
+	report, err := benchmark.Analyze(records, manifest, resultDir)
+	if err != nil {
+		log.Fatalf("Couldn't analyze results: %v", err)
+	}
+	var out io.Writer = os.Stdout
+	if analyzeOutput != "" {
+		file, err := os.Create(analyzeOutput)
+		if err != nil {
+			log.Fatalf("Couldn't create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	switch strings.ToLower(analyzeFormat) {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "\t")
+		err = encoder.Encode(report)
+	case "html":
+		err = report.WriteHTML(out)
+	case "text":
+		err = report.WriteText(out)
+	default:
+		log.Fatalf("Unknown report format %s, use text, json or html.", analyzeFormat)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't write report: %v", err)
+	}
+}
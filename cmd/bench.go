@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
+	"math/rand"
+	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/dominik-/t-race/benchmark"
 	"github.com/dominik-/t-race/executionmodel"
 	"github.com/dominik-/t-race/provider"
+	"github.com/dominik-/t-race/query"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -26,8 +32,24 @@ var (
 	runtime         int64
 	resultDirPrefix string
 	deploymentFile  string
+	dryRun          bool
+	planFormat      string
+	resultFormat    string
+	probe           probeFlags
+	loadProfileFile string
+	seed            int64
 )
 
+//probeFlags are the settings of the ingestion probe, which are shared by bench and run-local.
+type probeFlags struct {
+	endpoint    string
+	backend     string
+	ratio       float64
+	timeout     time.Duration
+	interval    time.Duration
+	maxInFlight int
+}
+
 func init() {
 	rootCmd.AddCommand(benchCmd)
 	cobra.OnInitialize(initBenchmarkConfig)
@@ -37,11 +59,32 @@ func init() {
 	benchCmd.Flags().Int64P("baselineTP", "t", 10, "The target throughput per second, that arrives at the root component.")
 	benchCmd.Flags().String("resultDirPrefix", "results-", "Prefix for the directory, to which results are written. Defaults to \"results-\". The start time is always appended.")
 	benchCmd.Flags().StringP("deploymentFile", "d", "deployment.json", "File that contains a static deployment of workers and sinks.")
+	benchCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Only print the plan of the benchmark, i.e. allocation of services to workers and sinks and expected rates, without contacting any worker.")
+	benchCmd.Flags().String("planFormat", "text", "Output format of the plan printed by --dry-run. Can be text or json.")
+	benchCmd.Flags().String("resultFormat", benchmark.DefaultResultFormat, "Format of result files. Can be "+strings.Join(benchmark.ResultFormats(), ", ")+".")
+	benchCmd.Flags().String("probeQuery", "", "Address of the query API of the SUT, e.g. http://localhost:16686. If set, a share of finished traces is polled during the run to measure how long they take to become visible.")
+	benchCmd.Flags().String("probeBackend", query.DefaultBackend, "Query API used by the ingestion probe. Available: "+strings.Join(query.Backends(), ", ")+".")
+	benchCmd.Flags().Float64("probeRatio", 0.01, "Share of finished, sampled traces polled by the ingestion probe.")
+	benchCmd.Flags().Duration("probeTimeout", 30*time.Second, "Time after which the ingestion probe stops polling a trace, which isn't visible.")
+	benchCmd.Flags().Duration("probeInterval", 250*time.Millisecond, "Interval in which the ingestion probe polls a trace.")
+	benchCmd.Flags().Int("probeMaxInFlight", 100, "Maximum number of traces polled by the ingestion probe at the same time.")
+	benchCmd.Flags().String("loadProfile", "", "YAML file with a load profile, which changes the baseline throughput over the run in ramp, step, steps, spike and hold stages.")
+	benchCmd.Flags().Int64("seed", 0, "Seed of work times, random tag values and arrivals of all workers. Runs with the same seed repeat them. 0 chooses a random seed, which is recorded in the manifest.")
 	bindToViper("services", benchCmd)
 	bindToViper("runtime", benchCmd)
 	bindToViper("baselineTP", benchCmd)
 	bindToViper("resultDirPrefix", benchCmd)
 	bindToViper("deploymentFile", benchCmd)
+	bindToViper("planFormat", benchCmd)
+	bindToViper("resultFormat", benchCmd)
+	bindToViper("probeQuery", benchCmd)
+	bindToViper("probeBackend", benchCmd)
+	bindToViper("probeRatio", benchCmd)
+	bindToViper("probeTimeout", benchCmd)
+	bindToViper("probeInterval", benchCmd)
+	bindToViper("probeMaxInFlight", benchCmd)
+	bindToViper("loadProfile", benchCmd)
+	bindToViper("seed", benchCmd)
 }
 
 func ExecuteBenchmark(cmd *cobra.Command, args []string) {
@@ -49,11 +92,21 @@ func ExecuteBenchmark(cmd *cobra.Command, args []string) {
 		Throughput:      baseThroughput,
 		Runtime:         runtime,
 		ResultDirPrefix: resultDirPrefix,
+		ResultFormat:    resultFormat,
+		ServiceFile:     serviceFile,
+		DeploymentFile:  deploymentFile,
+		IngestionProbe:  probe.config(),
+		LoadProfile:     readLoadProfile(loadProfileFile, baseThroughput, runtime),
+		Seed:            chooseSeed(seed),
 	}
+	checkResultFormat(resultFormat)
 	architecture, err := executionmodel.ParseArchitectureDescription(serviceFile)
 	if err != nil {
 		log.Fatalf("Parsing of service descriptor file failed: %v", err)
 	}
+	if errs := executionmodel.ValidateArchitecture(architecture, validationOptions()); errs != nil {
+		log.Fatalf("Service descriptor file is invalid:\n%v", errs)
+	}
 	log.Println("Parsed service descriptions successfully.")
 	log.Printf("Architecture description is: %+v\n", architecture)
 	s, _ := json.MarshalIndent(architecture, "", "\t")
@@ -64,8 +117,16 @@ func ExecuteBenchmark(cmd *cobra.Command, args []string) {
 	}
 	log.Println("Parsed static deployment successfully.")
 	prov.CreateEnvironments(architecture.Environments)
-	prov.AllocateServices(architecture.Services)
-	prov.AllocateSinks(architecture.Sinks)
+	if err := prov.AllocateServices(architecture.Services); err != nil {
+		log.Fatalf("Error allocating services to workers: %v", err)
+	}
+	if err := prov.AllocateSinks(architecture.Sinks); err != nil {
+		log.Fatalf("Error allocating sinks: %v", err)
+	}
+	if dryRun {
+		printPlan(architecture, prov, config)
+		return
+	}
 
 	log.Printf("Architecture with allocated provider resources: %v\n", architecture)
 	s, _ = json.MarshalIndent(architecture, "", "\t")
@@ -74,6 +135,81 @@ func ExecuteBenchmark(cmd *cobra.Command, args []string) {
 	b.StartBenchmark()
 }
 
+//printPlan prints the plan of a benchmark to stdout in the configured planFormat.
+func printPlan(architecture *executionmodel.Architecture, prov *provider.StaticProvider, config *executionmodel.BenchmarkConfig) {
+	configs := executionmodel.MapArchitectureToWorkers(*architecture, *config, prov.SinkMap, prov.SvcMap)
+	plan := benchmark.NewPlan(architecture, configs, prov.SvcMap, prov.WorkerMap, config)
+	switch strings.ToLower(planFormat) {
+	case "json":
+		s, err := json.MarshalIndent(plan, "", "\t")
+		if err != nil {
+			log.Fatalf("Couldn't marshal plan: %v", err)
+		}
+		fmt.Println(string(s))
+	case "text":
+		if err := plan.WriteText(os.Stdout); err != nil {
+			log.Fatalf("Couldn't write plan: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown plan format %s, use text or json.", planFormat)
+	}
+}
+
+//checkResultFormat exits if results can't be written in the given format, so a run doesn't fail after workers were prepared.
+func checkResultFormat(format string) {
+	for _, f := range benchmark.ResultFormats() {
+		if strings.EqualFold(f, format) {
+			return
+		}
+	}
+	log.Fatalf("Unknown result format %s, use one of: %s", format, strings.Join(benchmark.ResultFormats(), ", "))
+}
+
+//chooseSeed returns the seed of a run, or a random seed if none is set, so every run can be repeated with the seed from its manifest.
+func chooseSeed(seed int64) int64 {
+	for seed == 0 {
+		seed = rand.New(rand.NewSource(time.Now().UnixNano())).Int63()
+	}
+	log.Printf("Seed of the run is %d.", seed)
+	return seed
+}
+
+//readLoadProfile parses and checks a load profile, or returns nil if no file is set.
+func readLoadProfile(file string, throughput int64, runtime int64) *executionmodel.LoadProfile {
+	if file == "" {
+		return nil
+	}
+	profile, err := executionmodel.ParseLoadProfile(file)
+	if err != nil {
+		log.Fatalf("Error reading load profile: %v", err)
+	}
+	if _, err := profile.Points(float64(throughput)); err != nil {
+		log.Fatalf("Invalid load profile: %v", err)
+	}
+	if profile.Duration() > float64(runtime) {
+		log.Printf("Load profile takes %.0f seconds, but the runtime is only %d seconds. Later stages are not executed.", profile.Duration(), runtime)
+	}
+	return profile
+}
+
+//config returns the configuration of the ingestion probe, or nil if no query API is set.
+func (p probeFlags) config() *executionmodel.IngestionProbeConfig {
+	if p.endpoint == "" {
+		return nil
+	}
+	if _, err := query.NewClient(p.backend, p.endpoint, p.timeout); err != nil {
+		log.Fatalf("Invalid ingestion probe: %v", err)
+	}
+	return &executionmodel.IngestionProbeConfig{
+		Backend:     p.backend,
+		Endpoint:    p.endpoint,
+		Ratio:       p.ratio,
+		Timeout:     p.timeout,
+		Interval:    p.interval,
+		MaxInFlight: p.maxInFlight,
+	}
+}
+
 func initBenchmarkConfig() {
 	configFileDir, configFileName := filepath.Split(cfgFile)
 	fileNameNoExt := configFileName[:len(configFileName)-len(filepath.Ext(configFileName))]
@@ -95,4 +231,16 @@ func initBenchmarkConfig() {
 	runtime = viper.GetInt64("runtime")
 	resultDirPrefix = viper.GetString("resultDirPrefix")
 	deploymentFile = viper.GetString("deploymentFile")
+	planFormat = viper.GetString("planFormat")
+	resultFormat = viper.GetString("resultFormat")
+	probe = probeFlags{
+		endpoint:    viper.GetString("probeQuery"),
+		backend:     viper.GetString("probeBackend"),
+		ratio:       viper.GetFloat64("probeRatio"),
+		timeout:     viper.GetDuration("probeTimeout"),
+		interval:    viper.GetDuration("probeInterval"),
+		maxInFlight: viper.GetInt("probeMaxInFlight"),
+	}
+	loadProfileFile = viper.GetString("loadProfile")
+	seed = viper.GetInt64("seed")
 }
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/dominik-/t-race/benchmark"
+	"github.com/dominik-/t-race/query"
+	"github.com/spf13/cobra"
+)
+
+var fakeQueryCmd = &cobra.Command{
+	Use:   "fake-query [results directory]",
+	Short: "Serves the sampled spans of a run through a fake jaeger query API.",
+	Long: `Serves the spans a backend should store for the sampled records of a run through the jaeger query API, to test verification without a tracing system.
+The fake backend can drop a share of spans and return spans only after a delay.`,
+	Args: cobra.ExactArgs(1),
+	Run:  ServeFakeQuery,
+}
+
+var (
+	fakeQueryAddress   string
+	fakeQueryDropRatio float64
+	fakeQueryDelay     time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(fakeQueryCmd)
+	fakeQueryCmd.Flags().StringVarP(&fakeQueryAddress, "listen", "l", "localhost:16686", "Address to serve the query API on.")
+	fakeQueryCmd.Flags().Float64Var(&fakeQueryDropRatio, "dropRatio", 0, "Share of spans which are never returned.")
+	fakeQueryCmd.Flags().DurationVar(&fakeQueryDelay, "delay", 0, "Delay after the end of a span, or the start of the server, until the span is returned.")
+}
+
+func ServeFakeQuery(cmd *cobra.Command, args []string) {
+	records, err := benchmark.ReadResults(args[0])
+	if err != nil {
+		log.Fatalf("Couldn't read results: %v", err)
+	}
+	spans, err := benchmark.ExpectedSpans(records)
+	if err != nil {
+		log.Fatalf("Couldn't convert results to spans: %v", err)
+	}
+	server := query.NewFakeServer(spans, fakeQueryDropRatio, fakeQueryDelay)
+	log.Printf("Serving %d spans on http://%s/api/traces/", len(spans), fakeQueryAddress)
+	log.Fatal(http.ListenAndServe(fakeQueryAddress, server))
+}
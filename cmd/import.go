@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/importer"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import [trace or dependency files]",
+	Short: "Infers a service descriptor file from recorded traces or a dependency graph.",
+	Long: `Reads traces exported from Jaeger, Zipkin (v2 JSON) or OpenTelemetry (OTLP JSON) and infers an architecture: services and their operations become units,
+calls between operations become successors, which are synchronous if the caller waited for the callee, root units get ratios from the observed frequency of entry points,
+and work templates are fitted to the self-times of operations.
+With --format dependencies or edges, the architecture is inferred from a dependency graph with call counts instead, i.e. the response of /api/dependencies of jaeger
+or a CSV file with caller,callee,calls edges, e.g. exported from a service mesh. Ratios are derived from the call counts and all units get placeholder work.
+The service descriptor file is written to stdout or the output file, a summary of the import to stderr.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  ImportArchitecture,
+}
+
+var (
+	importFormat       string
+	importOutput       string
+	importName         string
+	importMinCallShare float64
+	importSinkProvider string
+	importSinkAddress  string
+	importPlaceholder  time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFormat, "format", importer.AutoFormat, "Format of the files. Can be "+strings.Join(append([]string{importer.AutoFormat}, importer.TraceFormats()...), ", ")+" for traces, or "+strings.Join(importer.DependencyFormats(), ", ")+" for dependency graphs.")
+	importCmd.Flags().StringVarP(&importOutput, "output", "o", "", "File to write the service descriptor to. Defaults to stdout.")
+	importCmd.Flags().StringVar(&importName, "name", "imported", "Name of the architecture.")
+	importCmd.Flags().Float64Var(&importMinCallShare, "minCallShare", 0.5, "Share of the invocations of a unit, which have to make a call, so the call becomes a successor.")
+	importCmd.Flags().StringVar(&importSinkProvider, "sinkProvider", "jaeger", "Provider of the sink, which all services send spans to.")
+	importCmd.Flags().StringVar(&importSinkAddress, "sinkAddress", "localhost:6831", "Address of the sink, which all services send spans to.")
+	importCmd.Flags().DurationVar(&importPlaceholder, "placeholderWork", time.Millisecond, "Constant work of each unit imported from a dependency graph.")
+}
+
+func ImportArchitecture(cmd *cobra.Command, args []string) {
+	if importer.IsDependencyFormat(importFormat) {
+		importDependencies(args)
+		return
+	}
+	spans, err := importer.ReadTraceFiles(args, importFormat)
+	if err != nil {
+		log.Fatalf("Couldn't read traces: %v", err)
+	}
+	architecture, report, err := importer.InferFromTraces(spans, importOptions())
+	if err != nil {
+		log.Fatalf("Couldn't infer architecture: %v", err)
+	}
+	report.WriteText(os.Stderr)
+	writeImportedArchitecture(architecture)
+}
+
+//importDependencies infers the architecture from dependency graphs.
+func importDependencies(files []string) {
+	dependencies, err := importer.ReadDependencyFiles(files, importFormat)
+	if err != nil {
+		log.Fatalf("Couldn't read dependencies: %v", err)
+	}
+	architecture, report, err := importer.InferFromDependencies(dependencies, importOptions())
+	if err != nil {
+		log.Fatalf("Couldn't infer architecture: %v", err)
+	}
+	report.WriteText(os.Stderr)
+	writeImportedArchitecture(architecture)
+}
+
+func importOptions() importer.Options {
+	return importer.Options{
+		Name:            importName,
+		MinCallShare:    importMinCallShare,
+		SinkProvider:    importSinkProvider,
+		SinkAddress:     importSinkAddress,
+		PlaceholderWork: float64(importPlaceholder) / float64(time.Microsecond),
+	}
+}
+
+//writeImportedArchitecture validates the architecture, so problems show up before a run, and writes it to the output.
+func writeImportedArchitecture(architecture *executionmodel.Architecture) {
+	for _, e := range executionmodel.ValidateArchitecture(architecture, validationOptions()) {
+		log.Printf("Imported architecture is invalid: %v", e)
+	}
+	var out io.Writer = os.Stdout
+	if importOutput != "" {
+		file, err := os.Create(importOutput)
+		if err != nil {
+			log.Fatalf("Couldn't create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := importer.WriteArchitecture(architecture, out); err != nil {
+		log.Fatalf("Couldn't write service descriptor: %v", err)
+	}
+}
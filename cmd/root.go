@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/dominik-/t-race/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,9 +15,10 @@ func bindToViper(flagName string, cmd *cobra.Command) {
 }
 
 var rootCmd = &cobra.Command{
-	Use:   "t-race",
-	Short: "Benchmarking tool for distributed tracing systems",
-	Long:  `t-race is a distributed workload generator for distributed tracing systems.`,
+	Use:     "t-race",
+	Short:   "Benchmarking tool for distributed tracing systems",
+	Long:    `t-race is a distributed workload generator for distributed tracing systems.`,
+	Version: version.String(),
 }
 
 func Execute() {
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dominik-/t-race/benchmark"
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/query"
+	"github.com/dominik-/t-race/worker"
+	"github.com/spf13/cobra"
+)
+
+var runLocalCmd = &cobra.Command{
+	Use:   "run-local",
+	Short: "Runs a t-race benchmark with all workers inside this process.",
+	Long: `Runs a t-race benchmark with one worker per service inside the coordinator process. Workers communicate via in-memory connections, so neither worker processes
+nor a deployment file are needed. Sinks are reached at the address given in the service descriptor file, or at --sinkAddress.`,
+	Run: RunLocal,
+}
+
+var (
+	localServiceFile     string
+	localRuntime         int64
+	localBaseThroughput  int64
+	localResultDirPrefix string
+	localSinkAddress     string
+	localSamplingType    string
+	localSamplingParam   float64
+	localResultFormat    string
+	localProbe           probeFlags
+	localLoadProfileFile string
+	localSeed            int64
+)
+
+func init() {
+	rootCmd.AddCommand(runLocalCmd)
+	runLocalCmd.Flags().StringVarP(&localServiceFile, "services", "s", "services.yaml", "Service descriptor file name. Must be a YAML file.")
+	runLocalCmd.Flags().Int64VarP(&localRuntime, "runtime", "r", 60, "The runtime of the benchmark in seconds.")
+	runLocalCmd.Flags().Int64VarP(&localBaseThroughput, "baselineTP", "t", 10, "The target throughput per second, that arrives at the root component.")
+	runLocalCmd.Flags().StringVar(&localResultDirPrefix, "resultDirPrefix", "results-", "Prefix for the directory, to which results are written. The start time is always appended.")
+	runLocalCmd.Flags().StringVar(&localSinkAddress, "sinkAddress", "localhost:6831", "Address of sinks, which don't have an address in the service descriptor file.")
+	runLocalCmd.Flags().StringVar(&localSamplingType, "samplingType", "probabilistic", "Sampling strategy type of all workers. Depends on tracer. For Jaeger: const, remote, probabilistic, ratelimiting, lowerbound")
+	runLocalCmd.Flags().Float64Var(&localSamplingParam, "samplingParam", 0.1, "Parameter for sampling type. Depends on type.")
+	runLocalCmd.Flags().StringVar(&localProbe.endpoint, "probeQuery", "", "Address of the query API of the SUT, e.g. http://localhost:16686. If set, a share of finished traces is polled during the run to measure how long they take to become visible.")
+	runLocalCmd.Flags().StringVar(&localProbe.backend, "probeBackend", query.DefaultBackend, "Query API used by the ingestion probe. Available: "+strings.Join(query.Backends(), ", ")+".")
+	runLocalCmd.Flags().Float64Var(&localProbe.ratio, "probeRatio", 0.01, "Share of finished, sampled traces polled by the ingestion probe.")
+	runLocalCmd.Flags().DurationVar(&localProbe.timeout, "probeTimeout", 30*time.Second, "Time after which the ingestion probe stops polling a trace, which isn't visible.")
+	runLocalCmd.Flags().DurationVar(&localProbe.interval, "probeInterval", 250*time.Millisecond, "Interval in which the ingestion probe polls a trace.")
+	runLocalCmd.Flags().IntVar(&localProbe.maxInFlight, "probeMaxInFlight", 100, "Maximum number of traces polled by the ingestion probe at the same time.")
+	runLocalCmd.Flags().StringVar(&localLoadProfileFile, "loadProfile", "", "YAML file with a load profile, which changes the baseline throughput over the run in ramp, step, steps, spike and hold stages.")
+	runLocalCmd.Flags().Int64Var(&localSeed, "seed", 0, "Seed of work times, random tag values and arrivals of all workers. Runs with the same seed repeat them. 0 chooses a random seed, which is recorded in the manifest.")
+	runLocalCmd.Flags().StringVar(&localResultFormat, "resultFormat", benchmark.DefaultResultFormat, "Format of result files. Can be "+strings.Join(benchmark.ResultFormats(), ", ")+".")
+}
+
+func RunLocal(cmd *cobra.Command, args []string) {
+	config := &executionmodel.BenchmarkConfig{
+		Throughput:      localBaseThroughput,
+		Runtime:         localRuntime,
+		ResultDirPrefix: localResultDirPrefix,
+		ResultFormat:    localResultFormat,
+		ServiceFile:     localServiceFile,
+		IngestionProbe:  localProbe.config(),
+		LoadProfile:     readLoadProfile(localLoadProfileFile, localBaseThroughput, localRuntime),
+		Seed:            chooseSeed(localSeed),
+	}
+	checkResultFormat(localResultFormat)
+	architecture, err := executionmodel.ParseArchitectureDescription(localServiceFile)
+	if err != nil {
+		log.Fatalf("Parsing of service descriptor file failed: %v", err)
+	}
+	if errs := executionmodel.ValidateArchitecture(architecture, validationOptions()); errs != nil {
+		log.Fatalf("Service descriptor file is invalid:\n%v", errs)
+	}
+	services := make([]string, len(architecture.Services))
+	for i, svc := range architecture.Services {
+		services[i] = svc.Identifier
+	}
+	cluster := worker.NewLocalCluster(services, localSamplingType, localSamplingParam)
+	defer cluster.Stop()
+	serviceMap := make(map[string]string, len(services))
+	workerMap := make(map[string]string, len(services))
+	for _, svc := range services {
+		serviceMap[svc] = cluster.ServiceAddress(svc)
+		workerMap[svc] = cluster.BenchmarkAddress(svc)
+	}
+	sinkMap := make(map[string]string, len(architecture.Sinks))
+	for _, sink := range architecture.Sinks {
+		sinkMap[sink.Identifier] = sink.Address
+		if sink.Address == "" {
+			sinkMap[sink.Identifier] = localSinkAddress
+		}
+	}
+	log.Printf("Started %d local workers.", len(services))
+	b := benchmark.Setup(architecture, serviceMap, workerMap, sinkMap, config, cluster.DialOption())
+	b.StartBenchmark()
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dominik-/t-race/executionmodel"
+	"github.com/dominik-/t-race/worker"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [service file]",
+	Short: "Validates a service descriptor file.",
+	Long: `Validates a service descriptor file without contacting any workers. Reports dangling references, cycles in the unit graph, duplicate identifiers, unknown work types,
+sink providers, propagation formats and arrival processes, invalid work parameters, missing sinks, invalid ratios, arrivals, virtual users and concurrency limits, and inconsistent inputs. Exits with a non-zero status if the architecture is invalid.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  ValidateServiceFile,
+}
+
+var validateAsJSON bool
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().BoolVar(&validateAsJSON, "json", false, "Print validation errors as JSON instead of text.")
+}
+
+//validationOptions returns the work types, sink providers and propagation formats supported by workers of this build, and checks work parameters like workers do.
+func validationOptions() executionmodel.ValidationOptions {
+	return executionmodel.ValidationOptions{
+		WorkTypes:          worker.DistributionTypes(),
+		SinkProviders:      worker.TracerBackendNames(),
+		PropagationFormats: worker.PropagationFormatNames(),
+		ArrivalTypes:       worker.ArrivalTypes(),
+		OverloadPolicies:   worker.OverloadPolicies(),
+		CheckWork:          worker.ValidateDistribution,
+	}
+}
+
+func ValidateServiceFile(cmd *cobra.Command, args []string) {
+	file := serviceFile
+	if len(args) > 0 {
+		file = args[0]
+	}
+	architecture, err := executionmodel.ParseArchitectureDescription(file)
+	if err != nil {
+		log.Fatalf("Parsing of service descriptor file failed: %v", err)
+	}
+	errs := executionmodel.ValidateArchitecture(architecture, validationOptions())
+	if validateAsJSON {
+		if errs == nil {
+			errs = executionmodel.ValidationErrors{}
+		}
+		s, _ := json.MarshalIndent(errs, "", "\t")
+		fmt.Println(string(s))
+	} else if len(errs) == 0 {
+		fmt.Printf("%s is valid.\n", file)
+	} else {
+		fmt.Printf("%s is invalid, found %d errors:\n", file, len(errs))
+		for _, e := range errs {
+			fmt.Printf("  %v\n", e)
+		}
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/dominik-/t-race/benchmark"
+	"github.com/dominik-/t-race/query"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [results directory]",
+	Short: "Verifies that the sampled traces of a run are stored in the tracing backend.",
+	Long: `Reads the records of a run and queries each sampled trace from the query API of the tracing backend. Reports found and complete traces, missing and unexpected
+spans, traces with a different number of spans than expected, and the ingestion delay of traces. Expected spans are the spans of all units and the client spans of all calls.
+Incomplete traces are queried again until the timeout expires. Start verification right after the run to measure the ingestion delay.
+Only the HTTP API of jaeger-query (port 16686) is supported, its gRPC API (port 16685) is out of scope.`,
+	Args: cobra.ExactArgs(1),
+	Run:  VerifyResults,
+}
+
+var (
+	verifyBackend      string
+	verifyEndpoint     string
+	verifyConfig       benchmark.VerificationConfig
+	verifyQueryTimeout time.Duration
+	verifyFormat       string
+	verifyOutput       string
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyBackend, "backend", query.DefaultBackend, "Query API of the tracing backend. Available: "+strings.Join(query.Backends(), ", ")+".")
+	verifyCmd.Flags().StringVarP(&verifyEndpoint, "query", "q", "http://localhost:16686", "Address of the HTTP query API, e.g. of jaeger-query.")
+	verifyCmd.Flags().DurationVar(&verifyConfig.Timeout, "timeout", 30*time.Second, "Time after which incomplete traces aren't queried again.")
+	verifyCmd.Flags().DurationVar(&verifyConfig.Interval, "interval", time.Second, "Interval in which incomplete traces are queried again.")
+	verifyCmd.Flags().IntVar(&verifyConfig.Concurrency, "concurrency", 8, "Number of concurrent queries.")
+	verifyCmd.Flags().IntVar(&verifyConfig.MaxTraces, "maxTraces", 0, "Maximum number of traces to verify, evenly spread over the run. 0 verifies all sampled traces.")
+	verifyCmd.Flags().DurationVar(&verifyQueryTimeout, "queryTimeout", 10*time.Second, "Timeout of a single query.")
+	verifyCmd.Flags().StringVar(&verifyFormat, "format", "text", "Format of the report. Can be text or json.")
+	verifyCmd.Flags().StringVarP(&verifyOutput, "output", "o", "", "File to write the report to. Defaults to stdout.")
+}
+
+func VerifyResults(cmd *cobra.Command, args []string) {
+	format := strings.ToLower(verifyFormat)
+	if format != "text" && format != "json" {
+		log.Fatalf("Unknown report format %s, use text or json.", verifyFormat)
+	}
+	records, err := benchmark.ReadResults(args[0])
+	if err != nil {
+		log.Fatalf("Couldn't read results: %v", err)
+	}
+	client, err := query.NewClient(verifyBackend, verifyEndpoint, verifyQueryTimeout)
+	if err != nil {
+		log.Fatalf("Couldn't create query client: %v", err)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	report, err := benchmark.Verify(ctx, records, client, verifyConfig)
+	if report == nil {
+		log.Fatalf("Couldn't verify traces: %v", err)
+	}
+	if err != nil {
+		log.Printf("Verification was interrupted, the report is incomplete: %v", err)
+	}
+	var out io.Writer = os.Stdout
+	if verifyOutput != "" {
+		file, err := os.Create(verifyOutput)
+		if err != nil {
+			log.Fatalf("Couldn't create output file: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "\t")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(out)
+	}
+	if err != nil {
+		log.Fatalf("Couldn't write report: %v", err)
+	}
+}
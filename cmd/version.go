@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/dominik-/t-race/version"
 	"github.com/spf13/cobra"
 )
 
@@ -13,8 +14,8 @@ func init() {
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "t-race version info.",
-	Long:  `Version of t-race.`,
+	Long:  `Version of t-race, as recorded in run manifests and reported by workers. Set at build time with -ldflags "-X github.com/dominik-/t-race/version.Version=...".`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("t-race benchmarking tool v0.9.1")
+		fmt.Println("t-race benchmarking tool " + version.String())
 	},
 }
@@ -31,6 +31,7 @@ func init() {
 	workerCmd.Flags().Float64Var(&samplingParam, "samplingParam", 0.1, "Parameter for sampling type. Depends on type.")
 	workerCmd.Flags().IntVarP(&metricsPort, "metricsPort", "m", 9000, "Port for the endpoint to scrape prometheus metrics from. The default /metrics path is used.")
 	workerCmd.Flags().BoolVar(&exportMetrics, "exportMetrics", true, "Whether to collect prometheus metrics or not.")
+	workerCmd.Flags().DurationVar(&callTimeout, "callTimeout", worker.DefaultCallTimeout, "Timeout of calls to successors at other workers. Failed calls are recorded in the results.")
 	viper.SetEnvPrefix("worker")
 	viper.AutomaticEnv()
 	bindToViper("benchmarkPort", workerCmd)
@@ -39,6 +40,7 @@ func init() {
 	bindToViper("samplingParam", workerCmd)
 	bindToViper("metricsPort", workerCmd)
 	bindToViper("exportMetrics", workerCmd)
+	bindToViper("callTimeout", workerCmd)
 }
 
 var (
@@ -49,12 +51,13 @@ var (
 	samplingParam float64
 	metricsPort   int
 	exportMetrics bool
+	callTimeout   time.Duration
 )
 
 func StartWorker(cmd *cobra.Command, args []string) {
 	sigTermRecv := make(chan os.Signal, 1)
 	signal.Notify(sigTermRecv, syscall.SIGINT, syscall.SIGTERM)
-	shutdown := worker.StartWorkerProcess(benchmarkPort, servicePort, metricsPort, exportMetrics, samplingType, samplingParam)
+	shutdown := worker.StartWorkerProcess(benchmarkPort, servicePort, metricsPort, exportMetrics, samplingType, samplingParam, callTimeout)
 	//wait for external signal to shut down
 	<-sigTermRecv
 	shutdown <- true
@@ -84,4 +87,5 @@ func initViperConfigWorker() {
 	samplingParam = viper.GetFloat64("samplingParam")
 	metricsPort = viper.GetInt("metricsPort")
 	exportMetrics = viper.GetBool("exportMetrics")
+	callTimeout = viper.GetDuration("callTimeout")
 }
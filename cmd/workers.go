@@ -32,6 +32,7 @@ func init() {
 	workersCmd.Flags().Float64Var(&samplingParam, "samplingParam", 0.1, "Parameter for sampling type. Depends on type.")
 	workersCmd.Flags().IntVarP(&metricsPort, "metricsPort", "m", 9000, "Port for the endpoint to scrape prometheus metrics from. The default /metrics path is used.")
 	workersCmd.Flags().BoolVar(&exportMetrics, "exportMetrics", true, "Whether to collect prometheus metrics or not.")
+	workersCmd.Flags().DurationVar(&callTimeout, "callTimeout", worker.DefaultCallTimeout, "Timeout of calls to successors at other workers. Failed calls are recorded in the results.")
 	viper.SetEnvPrefix("workers")
 	viper.AutomaticEnv()
 	bindToViper("workerCount", workersCmd)
@@ -41,6 +42,7 @@ func init() {
 	bindToViper("samplingParam", workersCmd)
 	bindToViper("metricsPort", workersCmd)
 	bindToViper("exportMetrics", workersCmd)
+	bindToViper("callTimeout", workersCmd)
 }
 
 var (
@@ -53,7 +55,7 @@ func StartWorkers(cmd *cobra.Command, args []string) {
 	shutdownHooks := make([]chan bool, workerCount)
 	fmt.Printf("Starting %d workers...\n", workerCount)
 	for i := 0; i < workerCount; i++ {
-		shutdownHooks[i] = worker.StartWorkerProcess(benchmarkPort+i, servicePort+i, metricsPort+i, exportMetrics, samplingType, samplingParam)
+		shutdownHooks[i] = worker.StartWorkerProcess(benchmarkPort+i, servicePort+i, metricsPort+i, exportMetrics, samplingType, samplingParam, callTimeout)
 	}
 	//wait for external signal to shut down
 	fmt.Println("All workers running. Waiting for user interrupt.")
@@ -86,6 +88,7 @@ func initViperConfigWorkers() {
 	servicePort = viper.GetInt("servicePort")
 	samplingType = viper.GetString("samplingType")
 	samplingParam = viper.GetFloat64("samplingParam")
-	metricsPort = viper.GetInt("prometheusPort")
-	exportMetrics = viper.GetBool("exportPrometheus")
+	metricsPort = viper.GetInt("metricsPort")
+	exportMetrics = viper.GetBool("exportMetrics")
+	callTimeout = viper.GetDuration("callTimeout")
 }
@@ -0,0 +1,183 @@
+package executionmodel
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//MaxEmpiricalPoints limits the points of the CDF sent to workers. Larger sample files are reduced to this many evenly spaced quantiles.
+const MaxEmpiricalPoints = 10000
+
+//EmpiricalCDF is the distribution of observed work times in microseconds, as points of a piecewise linear CDF. Values are ascending,
+//Probabilities are the share of samples up to each value and ascend from 0 to 1.
+type EmpiricalCDF struct {
+	Values        []float64 `json:"values"`
+	Probabilities []float64 `json:"probabilities"`
+}
+
+//EmpiricalBucket counts the samples between the upper bound of the previous bucket, or 0 for the first bucket, and UpperBound.
+type EmpiricalBucket struct {
+	UpperBound float64 `json:"upperBound"`
+	Count      float64 `json:"count"`
+}
+
+//empiricalFile is the JSON format of empirical work. It contains either samples or histogram buckets.
+type empiricalFile struct {
+	Samples []float64          `json:"samples"`
+	Buckets []*EmpiricalBucket `json:"buckets"`
+}
+
+//LoadEmpiricalCDF reads observed work times from a JSON or CSV file. JSON files contain an array of samples, or an object with "samples" or
+//"buckets" of histograms. CSV files contain a sample per line, or the upper bound and count of a bucket per line. A header line is skipped.
+func LoadEmpiricalCDF(file string) (*EmpiricalCDF, error) {
+	data, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+	var samples []float64
+	var buckets []*EmpiricalBucket
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		samples, buckets, err = readEmpiricalJSON(data)
+	} else {
+		samples, buckets, err = readEmpiricalCSV(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %v", file, err)
+	}
+	if buckets != nil {
+		return NewHistogramCDF(buckets)
+	}
+	return NewSampleCDF(samples)
+}
+
+func readEmpiricalJSON(r io.Reader) ([]float64, []*EmpiricalBucket, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var samples []float64
+	if err := json.Unmarshal(data, &samples); err == nil {
+		return samples, nil, nil
+	}
+	file := &empiricalFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, nil, err
+	}
+	if file.Samples != nil && file.Buckets != nil {
+		return nil, nil, fmt.Errorf("file contains both samples and buckets, use only one of them")
+	}
+	return file.Samples, file.Buckets, nil
+}
+
+func readEmpiricalCSV(r io.Reader) ([]float64, []*EmpiricalBucket, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	var samples []float64
+	var buckets []*EmpiricalBucket
+	for i, record := range records {
+		values := make([]float64, len(record))
+		for j, field := range record {
+			values[j], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if i == 0 {
+				//header
+				continue
+			}
+			return nil, nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		switch len(values) {
+		case 1:
+			samples = append(samples, values[0])
+		case 2:
+			buckets = append(buckets, &EmpiricalBucket{UpperBound: values[0], Count: values[1]})
+		default:
+			return nil, nil, fmt.Errorf("line %d has %d columns, use a sample or the upper bound and count of a bucket", i+1, len(values))
+		}
+	}
+	if samples != nil && buckets != nil {
+		return nil, nil, fmt.Errorf("file contains both samples and buckets, use only one of them")
+	}
+	return samples, buckets, nil
+}
+
+//NewSampleCDF creates the CDF of samples, which interpolates linearly between sorted samples.
+func NewSampleCDF(samples []float64) (*EmpiricalCDF, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("empirical work needs at least one sample")
+	}
+	sorted := append([]float64{}, samples...)
+	for _, s := range sorted {
+		if s < 0 || math.IsInf(s, 0) || math.IsNaN(s) {
+			return nil, fmt.Errorf("samples must be finite and not negative, got %f", s)
+		}
+	}
+	sort.Float64s(sorted)
+	if len(sorted) == 1 {
+		return &EmpiricalCDF{Values: []float64{sorted[0], sorted[0]}, Probabilities: []float64{0, 1}}, nil
+	}
+	points := len(sorted)
+	if points > MaxEmpiricalPoints {
+		points = MaxEmpiricalPoints
+	}
+	cdf := &EmpiricalCDF{Values: make([]float64, points), Probabilities: make([]float64, points)}
+	for i := range cdf.Values {
+		p := float64(i) / float64(points-1)
+		//position of the quantile p between the samples, which are the points of the CDF if they aren't reduced
+		position := p * float64(len(sorted)-1)
+		lower := int(position)
+		value := sorted[lower]
+		if lower+1 < len(sorted) {
+			value += (position - float64(lower)) * (sorted[lower+1] - sorted[lower])
+		}
+		cdf.Values[i] = value
+		cdf.Probabilities[i] = p
+	}
+	return cdf, nil
+}
+
+//NewHistogramCDF creates the CDF of histogram buckets, which assumes samples are uniformly distributed within each bucket.
+func NewHistogramCDF(buckets []*EmpiricalBucket) (*EmpiricalCDF, error) {
+	total := 0.0
+	for i, b := range buckets {
+		if b.Count < 0 || math.IsInf(b.Count, 0) || math.IsNaN(b.Count) {
+			return nil, fmt.Errorf("bucket %d has invalid count %f", i+1, b.Count)
+		}
+		if math.IsInf(b.UpperBound, 0) || math.IsNaN(b.UpperBound) {
+			return nil, fmt.Errorf("bucket %d has no finite upper bound, samples above the last bound can't be interpolated", i+1)
+		}
+		if b.UpperBound < 0 || (i > 0 && b.UpperBound <= buckets[i-1].UpperBound) {
+			return nil, fmt.Errorf("upper bounds of buckets must be ascending and not negative, bucket %d has %f", i+1, b.UpperBound)
+		}
+		total += b.Count
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("empirical work needs buckets with at least one sample")
+	}
+	cdf := &EmpiricalCDF{Values: []float64{0}, Probabilities: []float64{0}}
+	cumulative := 0.0
+	for _, b := range buckets {
+		cumulative += b.Count
+		//a bucket with upper bound 0 is a jump of the CDF at 0
+		cdf.Values = append(cdf.Values, b.UpperBound)
+		cdf.Probabilities = append(cdf.Probabilities, cumulative/total)
+	}
+	return cdf, nil
+}
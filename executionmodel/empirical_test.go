@@ -0,0 +1,135 @@
+package executionmodel
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+//writeFile writes a file to dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadEmpiricalCDF(t *testing.T) {
+	samples := &EmpiricalCDF{Values: []float64{10, 20, 30}, Probabilities: []float64{0, 0.5, 1}}
+	buckets := &EmpiricalCDF{Values: []float64{0, 100, 200}, Probabilities: []float64{0, 0.25, 1}}
+	dir := t.TempDir()
+	for name, test := range map[string]struct {
+		content  string
+		expected *EmpiricalCDF
+	}{
+		"array.json":   {`[30, 10, 20]`, samples},
+		"samples.json": {`{"samples": [20, 30, 10]}`, samples},
+		"buckets.json": {`{"buckets": [{"upperBound": 100, "count": 1}, {"upperBound": 200, "count": 3}]}`, buckets},
+		"samples.csv":  {"duration_us\n30\n# a comment\n10\n20\n", samples},
+		"buckets.CSV":  {"le,count\n100, 1\n200, 3\n", buckets},
+		"single.csv":   {"42\n", &EmpiricalCDF{Values: []float64{42, 42}, Probabilities: []float64{0, 1}}},
+		//buckets with upper bound 0 are a jump of the CDF at 0
+		"zero.json": {`{"buckets": [{"upperBound": 0, "count": 1}, {"upperBound": 10, "count": 1}]}`, &EmpiricalCDF{Values: []float64{0, 0, 10}, Probabilities: []float64{0, 0.5, 1}}},
+	} {
+		cdf, err := LoadEmpiricalCDF(writeFile(t, dir, name, test.content))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !reflect.DeepEqual(cdf, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", name, test.expected, cdf)
+		}
+	}
+}
+
+func TestLoadEmpiricalCDFRejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"both.json":       `{"samples": [1], "buckets": [{"upperBound": 1, "count": 1}]}`,
+		"both.csv":        "1\n2,1\n",
+		"columns.csv":     "1,2,3\n",
+		"invalid.csv":     "1\nabc\n",
+		"empty.json":      `[]`,
+		"negative.json":   `[1, -1]`,
+		"descending.json": `{"buckets": [{"upperBound": 200, "count": 1}, {"upperBound": 100, "count": 1}]}`,
+		"nocount.csv":     "100,0\n200,0\n",
+		"negcount.csv":    "100,-1\n200,2\n",
+		"syntax.json":     `{"samples": [1,`,
+	} {
+		if cdf, err := LoadEmpiricalCDF(writeFile(t, dir, name, content)); err == nil {
+			t.Errorf("%s: expected an error, got %+v", name, cdf)
+		}
+	}
+	if _, err := LoadEmpiricalCDF(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
+
+func TestSampleCDFIsReducedToQuantiles(t *testing.T) {
+	samples := make([]float64, 2*MaxEmpiricalPoints+1)
+	for i := range samples {
+		samples[i] = float64(len(samples) - 1 - i)
+	}
+	cdf, err := NewSampleCDF(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cdf.Values) != MaxEmpiricalPoints || len(cdf.Probabilities) != MaxEmpiricalPoints {
+		t.Fatalf("expected %d points, got %d values and %d probabilities", MaxEmpiricalPoints, len(cdf.Values), len(cdf.Probabilities))
+	}
+	//the samples are evenly spaced, so are their quantiles
+	for i := range cdf.Values {
+		p := float64(i) / float64(MaxEmpiricalPoints-1)
+		if math.Abs(cdf.Probabilities[i]-p) > 1e-12 || math.Abs(cdf.Values[i]-p*float64(len(samples)-1)) > 1e-6 {
+			t.Fatalf("expected point %d at %f with probability %f, got %f with %f", i, p*float64(len(samples)-1), p, cdf.Values[i], cdf.Probabilities[i])
+		}
+	}
+}
+
+func TestParserLoadsEmpiricalWork(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "work"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "work"), "latencies.csv", "10\n30\n20\n")
+	yamlFile := writeFile(t, dir, "architecture.yaml", `name: empirical
+services:
+  - id: frontend
+    sinkRef: agent1
+    units:
+      - id: root
+        work: measured
+        ratio: 1.0
+sinks:
+  - id: agent1
+    type: provided
+    hostport: localhost:6831
+workTemplates:
+  - id: measured
+    type: empirical
+    file: work/latencies.csv
+    params:
+      scale: 1000
+`)
+	architecture, err := ParseArchitectureDescription(yamlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &EmpiricalCDF{Values: []float64{10, 20, 30}, Probabilities: []float64{0, 0.5, 1}}
+	if cdf := architecture.WorkTemplates[0].Empirical; !reflect.DeepEqual(cdf, expected) {
+		t.Fatalf("expected the CDF %+v of the file relative to the architecture, got %+v", expected, cdf)
+	}
+	//the CDF is shipped to the worker within the work of the unit
+	configs := MapArchitectureToWorkers(*architecture, BenchmarkConfig{Throughput: 10, Runtime: 1}, map[string]string{}, map[string]string{})
+	work := configs["frontend"].Units[0].WorkBefore
+	if work.DistType != "empirical" || work.Parameters["scale"] != 1000 || !reflect.DeepEqual(work.Empirical.Values, expected.Values) || !reflect.DeepEqual(work.Empirical.Probabilities, expected.Probabilities) {
+		t.Errorf("expected the empirical work to be sent to the worker, got %v", work)
+	}
+
+	writeFile(t, filepath.Join(dir, "work"), "latencies.csv", "10\nslow\n")
+	if _, err := ParseArchitectureDescription(yamlFile); err == nil {
+		t.Errorf("expected an error for an invalid work file")
+	}
+}
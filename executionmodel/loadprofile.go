@@ -0,0 +1,120 @@
+package executionmodel
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dominik-/t-race/api"
+	"gopkg.in/yaml.v3"
+)
+
+//Stage types of a LoadProfile.
+const (
+	//RampStage changes the throughput linearly to the target over the duration.
+	RampStage = "ramp"
+	//StepStage sets the throughput to the target and holds it for the duration.
+	StepStage = "step"
+	//StepsStage changes the throughput to the target in equal steps, which are held for an equal share of the duration.
+	StepsStage = "steps"
+	//SpikeStage sets the throughput to the target for the duration, then returns to the throughput before the spike.
+	SpikeStage = "spike"
+	//HoldStage keeps the current throughput for the duration.
+	HoldStage = "hold"
+)
+
+//LoadProfile changes the baseline throughput over a run, e.g. to find the throughput at which a collector saturates in a single run.
+//The stages are executed in order, after the last stage the throughput is kept until the end of the run.
+type LoadProfile struct {
+	//Start is the throughput before the first stage. Defaults to the baseline throughput.
+	Start  *float64     `yaml:"start" json:"start,omitempty"`
+	Stages []*LoadStage `yaml:"stages" json:"stages"`
+}
+
+//LoadStage is a phase of a LoadProfile. Duration is in seconds, Target is a baseline throughput, i.e. it is multiplied with the ratio of each unit.
+type LoadStage struct {
+	Type     string  `yaml:"type" json:"type"`
+	Duration float64 `yaml:"duration" json:"duration"`
+	Target   float64 `yaml:"target" json:"target,omitempty"`
+	//Steps is the number of steps of a steps stage.
+	Steps int `yaml:"steps" json:"steps,omitempty"`
+}
+
+//ParseLoadProfile reads a load profile from a YAML file.
+func ParseLoadProfile(file string) (*LoadProfile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	profile := &LoadProfile{}
+	if err := yaml.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("couldn't parse load profile: %v", err)
+	}
+	return profile, nil
+}
+
+//Duration returns the time in seconds until the last stage ends.
+func (p *LoadProfile) Duration() float64 {
+	duration := 0.0
+	for _, stage := range p.Stages {
+		duration += stage.Duration
+	}
+	return duration
+}
+
+//Points converts the stages to the points of the profile sent to workers. Jumps are described by two points with the same offset.
+func (p *LoadProfile) Points(baseline float64) (*api.LoadProfile, error) {
+	level := baseline
+	if p.Start != nil {
+		level = *p.Start
+	}
+	if level < 0 {
+		return nil, fmt.Errorf("start throughput of load profile must not be negative, got %f", level)
+	}
+	offset := 0.0
+	points := []*api.LoadPoint{{OffsetSeconds: offset, Throughput: level}}
+	add := func(throughput float64) {
+		points = append(points, &api.LoadPoint{OffsetSeconds: offset, Throughput: throughput})
+	}
+	for i, stage := range p.Stages {
+		if stage.Duration < 0 {
+			return nil, fmt.Errorf("stage %d of load profile has negative duration %f", i+1, stage.Duration)
+		}
+		if stage.Target < 0 {
+			return nil, fmt.Errorf("stage %d of load profile has negative target %f", i+1, stage.Target)
+		}
+		switch strings.ToLower(stage.Type) {
+		case RampStage:
+			offset += stage.Duration
+			level = stage.Target
+			add(level)
+		case StepStage:
+			level = stage.Target
+			add(level)
+			offset += stage.Duration
+			add(level)
+		case StepsStage:
+			if stage.Steps < 1 {
+				return nil, fmt.Errorf("stage %d of load profile needs at least one step, got %d", i+1, stage.Steps)
+			}
+			from := level
+			for step := 1; step <= stage.Steps; step++ {
+				level = from + (stage.Target-from)*float64(step)/float64(stage.Steps)
+				add(level)
+				offset += stage.Duration / float64(stage.Steps)
+				add(level)
+			}
+		case SpikeStage:
+			add(stage.Target)
+			offset += stage.Duration
+			add(stage.Target)
+			add(level)
+		case HoldStage:
+			offset += stage.Duration
+			add(level)
+		default:
+			return nil, fmt.Errorf("stage %d of load profile has unknown type %s, use %s, %s, %s, %s or %s", i+1, stage.Type, RampStage, StepStage, StepsStage, SpikeStage, HoldStage)
+		}
+	}
+	return &api.LoadProfile{Points: points}, nil
+}
@@ -0,0 +1,87 @@
+package executionmodel
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+//pointList flattens the points of a profile to offset, throughput pairs.
+func pointList(t *testing.T, profile *LoadProfile, baseline float64) []float64 {
+	t.Helper()
+	points, err := profile.Points(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []float64
+	for _, point := range points.Points {
+		list = append(list, point.OffsetSeconds, point.Throughput)
+	}
+	return list
+}
+
+func TestLoadProfilePoints(t *testing.T) {
+	start := 20.0
+	for _, test := range []struct {
+		name     string
+		profile  *LoadProfile
+		expected []float64
+	}{
+		{"ramp from baseline", &LoadProfile{Stages: []*LoadStage{{Type: "ramp", Duration: 10, Target: 100}}},
+			[]float64{0, 10, 10, 100}},
+		{"step from start", &LoadProfile{Start: &start, Stages: []*LoadStage{{Type: "step", Duration: 5, Target: 50}, {Type: "hold", Duration: 5}}},
+			[]float64{0, 20, 0, 50, 5, 50, 10, 50}},
+		{"steps", &LoadProfile{Stages: []*LoadStage{{Type: "Steps", Duration: 6, Target: 40, Steps: 3}}},
+			[]float64{0, 10, 0, 20, 2, 20, 2, 30, 4, 30, 4, 40, 6, 40}},
+		{"spike", &LoadProfile{Stages: []*LoadStage{{Type: "ramp", Duration: 10, Target: 30}, {Type: "spike", Duration: 2, Target: 300}, {Type: "hold", Duration: 8}}},
+			[]float64{0, 10, 10, 30, 10, 300, 12, 300, 12, 30, 20, 30}},
+	} {
+		if actual := pointList(t, test.profile, 10); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected points %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestLoadProfilePointsRejectsInvalidStages(t *testing.T) {
+	negative := -1.0
+	for name, profile := range map[string]*LoadProfile{
+		"negative start":    {Start: &negative},
+		"negative duration": {Stages: []*LoadStage{{Type: "hold", Duration: -1}}},
+		"negative target":   {Stages: []*LoadStage{{Type: "ramp", Duration: 1, Target: -1}}},
+		"no steps":          {Stages: []*LoadStage{{Type: "steps", Duration: 1, Target: 1}}},
+		"unknown type":      {Stages: []*LoadStage{{Type: "wave", Duration: 1}}},
+	} {
+		if _, err := profile.Points(10); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadProfileIntegral(t *testing.T) {
+	//ramp from 10 to 100 in 10s, spike to 300 for 2s, hold 100
+	profile := &LoadProfile{Stages: []*LoadStage{{Type: "ramp", Duration: 10, Target: 100}, {Type: "spike", Duration: 2, Target: 300}, {Type: "hold", Duration: 8}}}
+	points, err := profile.Points(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		t, traces, throughput float64
+	}{
+		{0, 0, 10},
+		{5, 5 * (10 + 55) / 2.0, 55},
+		{10, 550, 300},
+		{11, 850, 300},
+		{12, 1150, 100},
+		{30, 1150 + 18*100, 100},
+	} {
+		if traces := points.Integral(test.t); math.Abs(traces-test.traces) > 1e-9 {
+			t.Errorf("expected %.1f traces after %.0fs, got %.1f", test.traces, test.t, traces)
+		}
+		if throughput := points.ThroughputAt(test.t); math.Abs(throughput-test.throughput) > 1e-9 {
+			t.Errorf("expected throughput %.1f after %.0fs, got %.1f", test.throughput, test.t, throughput)
+		}
+	}
+	if mean := points.MeanThroughput(20); math.Abs(mean-(1150+800)/20.0) > 1e-9 {
+		t.Errorf("expected mean throughput %.2f over 20s, got %.2f", (1150+800)/20.0, mean)
+	}
+}
@@ -8,12 +8,30 @@ import (
 
 func MapArchitectureToWorkers(d Architecture, b BenchmarkConfig, sinkAddresses, serviceAddresses map[string]string) map[string]*api.WorkerConfiguration {
 	workers := make(map[string]*api.WorkerConfiguration, len(d.Services))
+	sinkProviders := make(map[string]string, len(d.Sinks))
+	for _, sink := range d.Sinks {
+		sinkProviders[sink.Identifier] = sink.Provider
+	}
+	loadProfile := toLoadProfile(b)
 	for _, svc := range d.Services {
+		propagation := d.Propagation
+		if svc.Propagation != "" {
+			propagation = svc.Propagation
+		}
+		concurrency := d.Concurrency
+		if svc.Concurrency != nil {
+			concurrency = svc.Concurrency
+		}
 		workers[svc.Identifier] = &api.WorkerConfiguration{
 			WorkerId:         "worker-" + svc.Identifier,
 			SinkHostPort:     sinkAddresses[svc.SinkRef],
+			SinkProvider:     sinkProviders[svc.SinkRef],
+			Propagation:      propagation,
 			TargetThroughput: b.Throughput,
 			RuntimeSeconds:   b.Runtime,
+			LoadProfile:      loadProfile,
+			Concurrency:      toConcurrency(concurrency),
+			Seed:             b.Seed,
 			ServiceName:      svc.Identifier,
 			Units:            make([]*api.Unit, 0),
 		}
@@ -44,6 +62,10 @@ func MapArchitectureToWorkers(d Architecture, b BenchmarkConfig, sinkAddresses,
 					Sync:      successor.Sync,
 				}
 			}
+			arrival := d.Arrival
+			if unit.Arrival != nil {
+				arrival = unit.Arrival
+			}
 			apiUnit := &api.Unit{
 				Identifier:      unit.Identifier,
 				RelType:         api.RelationshipType(unit.Rel),
@@ -52,6 +74,9 @@ func MapArchitectureToWorkers(d Architecture, b BenchmarkConfig, sinkAddresses,
 				Inputs:          inputs,
 				ThroughputRatio: unit.ThroughputRatio,
 				Successors:      successors,
+				Arrival:         toArrival(arrival),
+				Users:           toUsers(unit.Users),
+				Concurrency:     toConcurrency(unit.Concurrency),
 			}
 			workers[svc.Identifier].Units = append(workers[svc.Identifier].Units, apiUnit)
 		}
@@ -85,9 +110,68 @@ func toKVTemplate(template []*KeyValueTemplate) []*api.KeyValueTemplate {
 	return templates
 }
 
+//toLoadProfile returns the points of the load profile of the benchmark. Invalid profiles are rejected when they're loaded, so they're treated like no profile here.
+func toLoadProfile(b BenchmarkConfig) *api.LoadProfile {
+	if b.LoadProfile == nil {
+		return nil
+	}
+	profile, err := b.LoadProfile.Points(float64(b.Throughput))
+	if err != nil {
+		return nil
+	}
+	return profile
+}
+
+func toConcurrency(c *Concurrency) *api.Concurrency {
+	if c == nil {
+		return nil
+	}
+	return &api.Concurrency{
+		Limit:  c.Limit,
+		Policy: c.Policy,
+		Queue:  c.Queue,
+	}
+}
+
+func toUsers(u *Users) *api.Users {
+	if u == nil {
+		return nil
+	}
+	return &api.Users{
+		Count:     u.Count,
+		ThinkTime: toWork(u.ThinkTime),
+	}
+}
+
+func toArrival(a *Arrival) *api.Arrival {
+	if a == nil {
+		return nil
+	}
+	return &api.Arrival{
+		Type:       a.Type,
+		Parameters: a.Params,
+	}
+}
+
 func toWork(wu *Work) *api.Work {
-	return &api.Work{
+	if wu == nil {
+		return nil
+	}
+	work := &api.Work{
 		DistType:   wu.Type,
 		Parameters: wu.Params,
 	}
+	if wu.Empirical != nil {
+		work.Empirical = &api.EmpiricalDistribution{
+			Values:        wu.Empirical.Values,
+			Probabilities: wu.Empirical.Probabilities,
+		}
+	}
+	for _, c := range wu.Components {
+		work.Components = append(work.Components, &api.MixtureComponent{
+			Weight: c.Weight,
+			Work:   toWork(c.Work),
+		})
+	}
+	return work
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/dominik-/t-race/api"
 	"github.com/golang/protobuf/ptypes"
@@ -13,10 +14,16 @@ import (
 //(logical references to deployment environments, which are used by sequences and sinks to learn about collocation)
 type Architecture struct {
 	Name          string     `yaml:"name"`
-	Services      []*Service `yaml:"services,flow"`
-	Sinks         []*Sink    `yaml:"sinks,flow"`
-	WorkTemplates []*Work    `yaml:"workTemplates,flow"`
+	Services      []*Service `yaml:"services"`
+	Sinks         []*Sink    `yaml:"sinks"`
+	WorkTemplates []*Work    `yaml:"workTemplates"`
 	Environments  []string   `yaml:"-"`
+	//Propagation is the default format to propagate trace context between services: uber, w3c, b3 or b3multi. Empty uses the native format of each sink provider.
+	Propagation string `yaml:"propagation,omitempty"`
+	//Arrival is the default arrival process of root units. Empty starts traces in fixed intervals.
+	Arrival *Arrival `yaml:"arrival,omitempty"`
+	//Concurrency is the default limit of concurrent invocations of each worker. Empty doesn't limit workers.
+	Concurrency *Concurrency `yaml:"concurrency,omitempty"`
 }
 
 //Service wraps a set of execution units, as they would be executed by a microservice.
@@ -27,23 +34,33 @@ type Service struct {
 	EnvironmentRef string `yaml:"envRef"`
 	//SinkRef is a reference to a sink, i.e. an endpoint, which the worker executing this sequence sends its traces to.
 	SinkRef string `yaml:"sinkRef"`
+	//Propagation overrides the propagation format of the architecture for this service. Only context in this format is understood on incoming calls.
+	Propagation string `yaml:"propagation,omitempty"`
+	//Concurrency overrides the limit of concurrent invocations of the architecture for the worker of this service.
+	Concurrency *Concurrency `yaml:"concurrency,omitempty"`
 	//Units are wrappers around timed events and calls to other units.
-	Units []*Unit `yaml:"units,flow"`
+	Units []*Unit `yaml:"units"`
 }
 
 //Unit is a wrapper around some work to be done and a call to another sequence.
 type Unit struct {
 	Identifier string           `yaml:"id"`
-	Rel        RelationshipType `yaml:"rel"`
+	Rel        RelationshipType `yaml:"rel,omitempty"`
 	//Local work to be done before a call to successors is done. String to match defined Work types.
-	WorkRef         string     `yaml:"work"`
+	WorkRef         string     `yaml:"work,omitempty"`
 	WorkTemplate    *Work      `yaml:"-"`
-	SuccessorRefs   []*UnitRef `yaml:"successors,flow"`
-	InputRefs       []*UnitRef `yaml:"inputs,flow"`
-	Context         *Context   `yaml:"context"`
-	ThroughputRatio float64    `yaml:"ratio"`
-	IsRoot          bool       `yaml:"-"`
-	Sync            bool       `yaml:"-"`
+	SuccessorRefs   []*UnitRef `yaml:"successors,omitempty"`
+	InputRefs       []*UnitRef `yaml:"inputs,omitempty"`
+	Context         *Context   `yaml:"context,omitempty"`
+	ThroughputRatio float64    `yaml:"ratio,omitempty"`
+	//Arrival overrides the arrival process of the architecture for this unit. Only root units, i.e. units with a ratio, have an arrival process.
+	Arrival *Arrival `yaml:"arrival,omitempty"`
+	//Users drive the unit in a closed loop instead of a ratio and an arrival process.
+	Users *Users `yaml:"users,omitempty"`
+	//Concurrency limits the concurrent invocations of the unit, which are started by its generator or asynchronous calls.
+	Concurrency *Concurrency `yaml:"concurrency,omitempty"`
+	IsRoot      bool         `yaml:"-"`
+	Sync        bool         `yaml:"-"`
 }
 
 //UnitRef is a simple wrapper type for mapping request-response vs. fire-and-forget-type interactions.
@@ -57,28 +74,64 @@ type UnitRef struct {
 type Work struct {
 	Identifier string             `yaml:"id"`
 	Type       string             `yaml:"type"`
-	Params     map[string]float64 `yaml:"params"`
+	Params     map[string]float64 `yaml:"params,omitempty"`
+	//Components are the weighted work templates of a mixture, e.g. a fast cache hit and a slow cache miss.
+	Components []*WorkComponent `yaml:"components,omitempty"`
+	//File contains samples or histogram buckets of empirical work, relative to the service descriptor file. It is loaded into Empirical by the parser.
+	File      string        `yaml:"file,omitempty"`
+	Empirical *EmpiricalCDF `yaml:"-" json:"-"`
+}
+
+//WorkComponent references a work template, which is sampled with a probability proportional to Weight.
+type WorkComponent struct {
+	WorkRef string  `yaml:"work,omitempty"`
+	Weight  float64 `yaml:"weight"`
+	Work    *Work   `yaml:"-"`
+}
+
+//Arrival describes when traces arrive at a root unit, e.g. in fixed intervals (constant), as Poisson process (poisson), in bursts (onoff),
+//as Markov-modulated Poisson process (mmpp) or with a sinusoidal rate (diurnal). Params depend on the type.
+type Arrival struct {
+	Type   string             `yaml:"type"`
+	Params map[string]float64 `yaml:"params,omitempty"`
+}
+
+//Users are virtual users, which invoke a unit in a closed loop: each user invokes the unit, waits until its synchronous calls are finished, waits for a think time
+//and repeats. The think time is sampled from the work template referenced by ThinkTimeRef, without a reference users repeat immediately.
+type Users struct {
+	Count        int64  `yaml:"count"`
+	ThinkTimeRef string `yaml:"thinkTime,omitempty"`
+	ThinkTime    *Work  `yaml:"-"`
+}
+
+//Concurrency limits the number of concurrent invocations of a worker or unit. If the limit is reached, further invocations are dropped (drop),
+//wait for a free slot if fewer than Queue invocations are waiting already (queue), or make the generator wait for a free slot (block).
+type Concurrency struct {
+	Limit  int64  `yaml:"limit"`
+	Policy string `yaml:"policy"`
+	Queue  int64  `yaml:"queue,omitempty"`
 }
 
 //Context is a wrapper around observable (meta-)data generated by an execution unit.
 type Context struct {
 	Identifier string              `yaml:"id"`
-	Tags       []*KeyValueTemplate `yaml:"tags,flow"`
-	Logs       []*KeyValueTemplate `yaml:"logs,flow"`
-	Baggage    []*KeyValueTemplate `yaml:"baggage,flow"`
+	Tags       []*KeyValueTemplate `yaml:"tags,omitempty"`
+	Logs       []*KeyValueTemplate `yaml:"logs,omitempty"`
+	Baggage    []*KeyValueTemplate `yaml:"baggage,omitempty"`
 }
 
 //KeyValueTemplate is a container for key-value pairs, which can be described either by their length or a static string. If length is above 0, it is prioritized over static strings.
 type KeyValueTemplate struct {
-	KeyStatic   string `yaml:"keyStatic"`
-	KeyLength   int64  `yaml:"keyLength"`
-	ValueStatic string `yaml:"valueStatic"`
-	ValueLength int64  `yaml:"valueLength"`
+	KeyStatic   string `yaml:"keyStatic,omitempty"`
+	KeyLength   int64  `yaml:"keyLength,omitempty"`
+	ValueStatic string `yaml:"valueStatic,omitempty"`
+	ValueLength int64  `yaml:"valueLength,omitempty"`
 }
 
 //Sink is a wrapper around a backend sequence of a tracing system (something like a proxy/agent, storage/collector, stream pipeline or w/e).
 type Sink struct {
-	Identifier     string `yaml:"id"`
+	Identifier string `yaml:"id"`
+	//Provider selects the tracer backend workers use to send traces to this sink, e.g. jaeger (default), otlp-grpc or otlp-http.
 	Provider       string `yaml:"provider"`
 	Address        string `yaml:"address"`
 	EnvironmentRef string `yaml:"envRef"`
@@ -114,6 +167,11 @@ func (r RelationshipType) String() string {
 	return relationshipTypeNames[r]
 }
 
+//MarshalYAML writes the lowercase name, which is read by UnmarshalYAML.
+func (r RelationshipType) MarshalYAML() (interface{}, error) {
+	return strings.ToLower(r.String()), nil
+}
+
 func (r *RelationshipType) UnmarshalYAML(unmarshal func(value interface{}) error) error {
 	var stringValue string
 	err := unmarshal(&stringValue)
@@ -145,6 +203,31 @@ type BenchmarkConfig struct {
 	Throughput      int64
 	ResultDirPrefix string
 	Runtime         int64
+	//ResultFormat selects the writer of results, e.g. csv, jsonl, parquet or sqlite.
+	ResultFormat string
+	//ServiceFile and DeploymentFile are the files the run was created from. They're only recorded in the manifest of the run.
+	ServiceFile    string
+	DeploymentFile string
+	//IngestionProbe polls the query API of the SUT for traces during the run. It is disabled if nil.
+	IngestionProbe *IngestionProbeConfig
+	//LoadProfile changes the throughput over the run. Throughput is kept for the whole run if nil.
+	LoadProfile *LoadProfile
+	//Seed is passed to all workers, which derive the seeds of their units from it. Runs with the same seed sample the same work times and random tag values.
+	Seed int64
+}
+
+//IngestionProbeConfig configures the probe, which measures how long finished traces take to become visible in the query API of the SUT.
+type IngestionProbeConfig struct {
+	//Backend and Endpoint select the query API, e.g. jaeger at http://localhost:16686.
+	Backend  string `json:"backend"`
+	Endpoint string `json:"endpoint"`
+	//Ratio is the share of finished, sampled traces which are probed.
+	Ratio float64 `json:"ratio"`
+	//Timeout is the time after which a trace, which isn't visible yet, isn't polled anymore. Interval is the time between polls of a trace.
+	Timeout  time.Duration `json:"timeout"`
+	Interval time.Duration `json:"interval"`
+	//MaxInFlight limits the number of traces polled at the same time. Further traces are skipped.
+	MaxInFlight int `json:"maxInFlight"`
 }
 
 type Record struct {
@@ -1,8 +1,10 @@
 package executionmodel
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,9 +17,31 @@ func ParseArchitectureDescription(yamlFile string) (*Architecture, error) {
 		return nil, err
 	}
 	validateArchitectureAndResolveRefs(architecture)
+	if err := loadEmpiricalWork(architecture, filepath.Dir(yamlFile)); err != nil {
+		return nil, err
+	}
 	return architecture, nil
 }
 
+//loadEmpiricalWork loads the files of work templates. Relative paths are resolved from the directory of the service descriptor file.
+func loadEmpiricalWork(architecture *Architecture, dir string) error {
+	for _, w := range architecture.WorkTemplates {
+		if w.File == "" {
+			continue
+		}
+		file := w.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		cdf, err := LoadEmpiricalCDF(file)
+		if err != nil {
+			return fmt.Errorf("couldn't load work template %s: %v", w.Identifier, err)
+		}
+		w.Empirical = cdf
+	}
+	return nil
+}
+
 func readFromYamlFile(file string) (*Architecture, error) {
 	fileHandle, err := os.Open(file)
 	if err != nil {
@@ -64,6 +88,12 @@ func validateArchitectureAndResolveRefs(architecture *Architecture) {
 	for _, w := range architecture.WorkTemplates {
 		workUnitIDMap[w.Identifier] = w
 	}
+	//references to non-existing work and cycles of mixtures are reported by ValidateArchitecture
+	for _, w := range architecture.WorkTemplates {
+		for _, c := range w.Components {
+			c.Work = workUnitIDMap[c.WorkRef]
+		}
+	}
 
 	envRefs := make([]string, 0)
 	for key := range envMap {
@@ -101,13 +131,13 @@ func validateArchitectureAndResolveRefs(architecture *Architecture) {
 			//		sucessor.Successor = referencedUnit
 			//	}
 			//}
-			if unit.WorkRef != "" {
-				referencedWork, exists := workUnitIDMap[unit.WorkRef]
-				if !exists {
-					log.Fatalf("Reference to non-existing work id (%s) found in architecture: error in sequence %s. Aborting.", unit.WorkRef, s.Identifier)
-				}
+			//references to non-existing work are reported by ValidateArchitecture
+			if referencedWork, exists := workUnitIDMap[unit.WorkRef]; exists {
 				unit.WorkTemplate = referencedWork
 			}
+			if unit.Users != nil {
+				unit.Users.ThinkTime = workUnitIDMap[unit.Users.ThinkTimeRef]
+			}
 		}
 	}
 	for _, unit := range allUnitsMap {
@@ -116,7 +146,7 @@ func validateArchitectureAndResolveRefs(architecture *Architecture) {
 		}
 	}
 	// (multiple roots could use multiple target throughputs and lead to possible contention between requests from different roots)...
-	//TODO check for loops in the unit graph here?
+	//loops in the unit graph are reported by ValidateArchitecture
 }
 
 //AddServicesToEnvMap is a helper function which recursively traverses services and adds them to a map grouped by Environments assigned to each of them. The EnvRef is an identifier for a deployment environment where multiple services might be co-located.
@@ -0,0 +1,488 @@
+package executionmodel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dominik-/t-race/api"
+)
+
+//ValidationErrorKind classifies errors found by ValidateArchitecture.
+type ValidationErrorKind string
+
+const (
+	DanglingReference   ValidationErrorKind = "dangling-reference"
+	Cycle               ValidationErrorKind = "cycle"
+	DuplicateIdentifier ValidationErrorKind = "duplicate-identifier"
+	UnknownWorkType     ValidationErrorKind = "unknown-work-type"
+	InvalidWork         ValidationErrorKind = "invalid-work"
+	MissingSink         ValidationErrorKind = "missing-sink"
+	UnknownProvider     ValidationErrorKind = "unknown-provider"
+	UnknownPropagation  ValidationErrorKind = "unknown-propagation"
+	InconsistentInputs  ValidationErrorKind = "inconsistent-inputs"
+	InvalidRatio        ValidationErrorKind = "invalid-ratio"
+	UnknownArrivalType  ValidationErrorKind = "unknown-arrival-type"
+	InvalidArrival      ValidationErrorKind = "invalid-arrival"
+	InvalidUsers        ValidationErrorKind = "invalid-users"
+	InvalidConcurrency  ValidationErrorKind = "invalid-concurrency"
+)
+
+//ValidationError describes a single problem of an architecture. Service and Unit are empty if the error doesn't refer to a service or unit.
+type ValidationError struct {
+	Kind    ValidationErrorKind `json:"kind"`
+	Service string              `json:"service,omitempty"`
+	Unit    string              `json:"unit,omitempty"`
+	Message string              `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	location := ""
+	if e.Service != "" {
+		location = "service " + e.Service
+		if e.Unit != "" {
+			location += ", unit " + e.Unit
+		}
+		location += ": "
+	}
+	return fmt.Sprintf("%s[%s] %s", location, e.Kind, e.Message)
+}
+
+//ValidationErrors is the list of all problems found in an architecture.
+type ValidationErrors []*ValidationError
+
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, err := range v {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+//ValidationOptions contains the names of work types, sink providers, propagation formats, arrival processes and overload policies known to workers.
+//Empty lists skip the respective check. Work types are case-sensitive, all other names are not.
+type ValidationOptions struct {
+	WorkTypes          []string
+	SinkProviders      []string
+	PropagationFormats []string
+	ArrivalTypes       []string
+	OverloadPolicies   []string
+	//CheckWork returns an error if workers can't sample from a work template, e.g. because of invalid parameters. Nil skips the check.
+	CheckWork func(*api.Work) error
+}
+
+//ValidateArchitecture checks an architecture for dangling references, cycles in the unit graph and in mixtures, duplicate identifiers, unknown work types, invalid work
+//parameters, sink providers, propagation formats and arrival processes, missing sinks, invalid ratios, arrival processes of units without load, invalid virtual users and concurrency limits, and inputs which are
+//inconsistent with successors. Returns nil if the architecture is valid.
+func ValidateArchitecture(architecture *Architecture, options ValidationOptions) ValidationErrors {
+	v := &validator{
+		architecture: architecture,
+		options:      options,
+		services:     make(map[string]*Service),
+		units:        make(map[unitKey]*Unit),
+		sinks:        make(map[string]*Sink),
+		works:        make(map[string]*Work),
+	}
+	v.collectIdentifiers()
+	v.checkSinks()
+	v.checkWork()
+	v.checkPropagation()
+	v.checkReferences()
+	v.checkRatios()
+	v.checkArrivals()
+	v.checkUsers()
+	v.checkConcurrency()
+	v.checkInputs()
+	v.checkCycles()
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}
+
+type unitKey struct {
+	service string
+	unit    string
+}
+
+func (k unitKey) String() string {
+	return k.service + "/" + k.unit
+}
+
+type validator struct {
+	architecture *Architecture
+	options      ValidationOptions
+	services     map[string]*Service
+	units        map[unitKey]*Unit
+	sinks        map[string]*Sink
+	works        map[string]*Work
+	errors       ValidationErrors
+}
+
+func (v *validator) add(kind ValidationErrorKind, service, unit, format string, args ...interface{}) {
+	v.errors = append(v.errors, &ValidationError{
+		Kind:    kind,
+		Service: service,
+		Unit:    unit,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func contains(values []string, value string, caseSensitive bool) bool {
+	for _, v := range values {
+		if v == value || (!caseSensitive && strings.EqualFold(v, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) collectIdentifiers() {
+	for _, svc := range v.architecture.Services {
+		if _, exists := v.services[svc.Identifier]; exists {
+			v.add(DuplicateIdentifier, svc.Identifier, "", "service id %s is used more than once", svc.Identifier)
+		} else {
+			v.services[svc.Identifier] = svc
+		}
+		for _, unit := range svc.Units {
+			key := unitKey{svc.Identifier, unit.Identifier}
+			if _, exists := v.units[key]; exists {
+				v.add(DuplicateIdentifier, svc.Identifier, unit.Identifier, "unit id %s is used more than once in the service", unit.Identifier)
+			} else {
+				v.units[key] = unit
+			}
+		}
+	}
+	for _, sink := range v.architecture.Sinks {
+		if _, exists := v.sinks[sink.Identifier]; exists {
+			v.add(DuplicateIdentifier, "", "", "sink id %s is used more than once", sink.Identifier)
+		} else {
+			v.sinks[sink.Identifier] = sink
+		}
+	}
+	for _, work := range v.architecture.WorkTemplates {
+		if _, exists := v.works[work.Identifier]; exists {
+			v.add(DuplicateIdentifier, "", "", "work template id %s is used more than once", work.Identifier)
+		} else {
+			v.works[work.Identifier] = work
+		}
+	}
+}
+
+func (v *validator) checkSinks() {
+	if len(v.architecture.Sinks) == 0 {
+		v.add(MissingSink, "", "", "architecture doesn't define any sinks")
+	}
+	for _, sink := range v.architecture.Sinks {
+		if len(v.options.SinkProviders) > 0 && sink.Provider != "" && !contains(v.options.SinkProviders, sink.Provider, false) {
+			v.add(UnknownProvider, "", "", "sink %s uses unknown provider %s, known providers are: %s", sink.Identifier, sink.Provider, strings.Join(v.options.SinkProviders, ", "))
+		}
+	}
+	for _, svc := range v.architecture.Services {
+		if svc.SinkRef == "" {
+			v.add(MissingSink, svc.Identifier, "", "service doesn't reference a sink")
+		} else if _, exists := v.sinks[svc.SinkRef]; !exists {
+			v.add(MissingSink, svc.Identifier, "", "service references non-existing sink %s", svc.SinkRef)
+		}
+	}
+}
+
+func (v *validator) checkWork() {
+	if len(v.options.WorkTypes) > 0 {
+		for _, work := range v.architecture.WorkTemplates {
+			if !contains(v.options.WorkTypes, work.Type, true) {
+				v.add(UnknownWorkType, "", "", "work template %s uses unknown type %s, known types are: %s", work.Identifier, work.Type, strings.Join(v.options.WorkTypes, ", "))
+			}
+		}
+	}
+	v.checkMixtures()
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			if unit.WorkRef == "" {
+				continue
+			}
+			if _, exists := v.works[unit.WorkRef]; !exists {
+				v.add(DanglingReference, svc.Identifier, unit.Identifier, "unit references non-existing work template %s", unit.WorkRef)
+			}
+		}
+	}
+}
+
+//checkMixtures makes sure that the components of mixtures reference existing work templates with a positive weight and don't contain cycles.
+//The parameters of a work template are only checked if all templates it depends on are valid, since its components have to be resolved first.
+func (v *validator) checkMixtures() {
+	const (
+		unvisited = iota
+		inProgress
+		valid
+		invalid
+	)
+	state := make(map[string]int)
+	var visit func(work *Work) bool
+	visit = func(work *Work) bool {
+		state[work.Identifier] = inProgress
+		ok := true
+		for _, c := range work.Components {
+			if c.Weight <= 0 {
+				v.add(InvalidWork, "", "", "component %s of work template %s needs a positive weight, got %f", c.WorkRef, work.Identifier, c.Weight)
+				ok = false
+			}
+			component, exists := v.works[c.WorkRef]
+			if !exists {
+				v.add(DanglingReference, "", "", "work template %s references non-existing work template %s", work.Identifier, c.WorkRef)
+				ok = false
+				continue
+			}
+			switch state[component.Identifier] {
+			case unvisited:
+				ok = visit(component) && ok
+			case inProgress:
+				v.add(Cycle, "", "", "work template %s contains itself through component %s", work.Identifier, c.WorkRef)
+				ok = false
+			case invalid:
+				ok = false
+			}
+		}
+		//unknown types are reported by checkWork
+		if len(v.options.WorkTypes) > 0 && !contains(v.options.WorkTypes, work.Type, true) {
+			ok = false
+		}
+		if ok && v.options.CheckWork != nil {
+			if err := v.options.CheckWork(toWork(work)); err != nil {
+				v.add(InvalidWork, "", "", "work template %s is invalid: %v", work.Identifier, err)
+				ok = false
+			}
+		}
+		state[work.Identifier] = invalid
+		if ok {
+			state[work.Identifier] = valid
+		}
+		return ok
+	}
+	for _, work := range v.architecture.WorkTemplates {
+		if state[work.Identifier] == unvisited {
+			visit(work)
+		}
+	}
+}
+
+func (v *validator) checkPropagation() {
+	if len(v.options.PropagationFormats) == 0 {
+		return
+	}
+	if v.architecture.Propagation != "" && !contains(v.options.PropagationFormats, v.architecture.Propagation, false) {
+		v.add(UnknownPropagation, "", "", "architecture uses unknown propagation format %s, known formats are: %s", v.architecture.Propagation, strings.Join(v.options.PropagationFormats, ", "))
+	}
+	for _, svc := range v.architecture.Services {
+		if svc.Propagation != "" && !contains(v.options.PropagationFormats, svc.Propagation, false) {
+			v.add(UnknownPropagation, svc.Identifier, "", "service uses unknown propagation format %s, known formats are: %s", svc.Propagation, strings.Join(v.options.PropagationFormats, ", "))
+		}
+	}
+}
+
+//checkRef reports a dangling reference, if ref doesn't point to an existing unit. Returns whether the reference is valid.
+func (v *validator) checkRef(svc *Service, unit *Unit, ref *UnitRef, refType string) bool {
+	if ref.Service == "" || ref.Unit == "" {
+		v.add(DanglingReference, svc.Identifier, unit.Identifier, "%s must reference both svc and unit, got svc '%s' and unit '%s'", refType, ref.Service, ref.Unit)
+		return false
+	}
+	if _, exists := v.services[ref.Service]; !exists {
+		v.add(DanglingReference, svc.Identifier, unit.Identifier, "%s references non-existing service %s", refType, ref.Service)
+		return false
+	}
+	if _, exists := v.units[unitKey{ref.Service, ref.Unit}]; !exists {
+		v.add(DanglingReference, svc.Identifier, unit.Identifier, "%s references non-existing unit %s of service %s", refType, ref.Unit, ref.Service)
+		return false
+	}
+	return true
+}
+
+func (v *validator) checkReferences() {
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			for _, successor := range unit.SuccessorRefs {
+				v.checkRef(svc, unit, successor, "successor")
+			}
+			for _, input := range unit.InputRefs {
+				v.checkRef(svc, unit, input, "input")
+			}
+		}
+	}
+}
+
+//minRatio is the smallest ratio for which workers generate load on a unit.
+const minRatio = 0.000001
+
+//checkRatios makes sure that ratios aren't negative and at least one unit generates load, either by its ratio or by virtual users.
+func (v *validator) checkRatios() {
+	generating := false
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			if unit.ThroughputRatio < 0 {
+				v.add(InvalidRatio, svc.Identifier, unit.Identifier, "ratio must not be negative, got %f", unit.ThroughputRatio)
+			}
+			if unit.ThroughputRatio > minRatio || unit.Users != nil {
+				generating = true
+			}
+		}
+	}
+	if !generating {
+		v.add(InvalidRatio, "", "", "no unit has a ratio above %f or virtual users, so no load would be generated", minRatio)
+	}
+}
+
+//checkArrivals makes sure that arrival processes are known, and only configured for units which generate load.
+func (v *validator) checkArrivals() {
+	known := func(a *Arrival) bool {
+		return len(v.options.ArrivalTypes) == 0 || a.Type == "" || contains(v.options.ArrivalTypes, a.Type, false)
+	}
+	if a := v.architecture.Arrival; a != nil && !known(a) {
+		v.add(UnknownArrivalType, "", "", "architecture uses unknown arrival process %s, known processes are: %s", a.Type, strings.Join(v.options.ArrivalTypes, ", "))
+	}
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			if unit.Arrival == nil {
+				continue
+			}
+			if !known(unit.Arrival) {
+				v.add(UnknownArrivalType, svc.Identifier, unit.Identifier, "unit uses unknown arrival process %s, known processes are: %s", unit.Arrival.Type, strings.Join(v.options.ArrivalTypes, ", "))
+			}
+			if unit.ThroughputRatio <= minRatio {
+				v.add(InvalidArrival, svc.Identifier, unit.Identifier, "unit has an arrival process, but no ratio, so it doesn't generate load")
+			}
+		}
+	}
+}
+
+//checkUsers makes sure that units with virtual users have at least one user, an existing think time and neither a ratio nor an arrival process.
+func (v *validator) checkUsers() {
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			if unit.Users == nil {
+				continue
+			}
+			if unit.Users.Count < 1 {
+				v.add(InvalidUsers, svc.Identifier, unit.Identifier, "unit needs at least one virtual user, got %d", unit.Users.Count)
+			}
+			if unit.ThroughputRatio > minRatio {
+				v.add(InvalidUsers, svc.Identifier, unit.Identifier, "unit has virtual users and a ratio, use only one of them")
+			}
+			if unit.Arrival != nil {
+				v.add(InvalidUsers, svc.Identifier, unit.Identifier, "unit has virtual users and an arrival process, use only one of them")
+			}
+			if unit.Users.ThinkTimeRef != "" {
+				if _, exists := v.works[unit.Users.ThinkTimeRef]; !exists {
+					v.add(DanglingReference, svc.Identifier, unit.Identifier, "think time references non-existing work template %s", unit.Users.ThinkTimeRef)
+				}
+			}
+		}
+	}
+}
+
+//checkConcurrency makes sure that concurrency limits allow at least one invocation, use a known policy and don't have a negative queue.
+func (v *validator) checkConcurrency() {
+	check := func(c *Concurrency, service, unit, owner string) {
+		if c == nil {
+			return
+		}
+		if c.Limit < 1 {
+			v.add(InvalidConcurrency, service, unit, "%s concurrency limit must be at least 1, got %d", owner, c.Limit)
+		}
+		if c.Queue < 0 {
+			v.add(InvalidConcurrency, service, unit, "%s concurrency queue must not be negative, got %d", owner, c.Queue)
+		}
+		if len(v.options.OverloadPolicies) > 0 && c.Policy != "" && !contains(v.options.OverloadPolicies, c.Policy, false) {
+			v.add(InvalidConcurrency, service, unit, "%s uses unknown overload policy %s, known policies are: %s", owner, c.Policy, strings.Join(v.options.OverloadPolicies, ", "))
+		}
+	}
+	check(v.architecture.Concurrency, "", "", "architecture")
+	for _, svc := range v.architecture.Services {
+		check(svc.Concurrency, svc.Identifier, "", "service")
+		for _, unit := range svc.Units {
+			check(unit.Concurrency, svc.Identifier, unit.Identifier, "unit")
+		}
+	}
+}
+
+//checkInputs makes sure that inputs and successors describe the same edges. Inputs are optional, but if a unit declares inputs, all its predecessors have to be listed.
+func (v *validator) checkInputs() {
+	predecessors := make(map[unitKey]map[unitKey]bool)
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			for _, successor := range unit.SuccessorRefs {
+				target := unitKey{successor.Service, successor.Unit}
+				if predecessors[target] == nil {
+					predecessors[target] = make(map[unitKey]bool)
+				}
+				predecessors[target][unitKey{svc.Identifier, unit.Identifier}] = true
+			}
+		}
+	}
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			if len(unit.InputRefs) == 0 {
+				continue
+			}
+			key := unitKey{svc.Identifier, unit.Identifier}
+			inputs := make(map[unitKey]bool)
+			for _, input := range unit.InputRefs {
+				inputKey := unitKey{input.Service, input.Unit}
+				inputs[inputKey] = true
+				if _, exists := v.units[inputKey]; exists && !predecessors[key][inputKey] {
+					v.add(InconsistentInputs, svc.Identifier, unit.Identifier, "input %s doesn't list this unit as successor", inputKey)
+				}
+			}
+			for predecessor := range predecessors[key] {
+				if !inputs[predecessor] {
+					v.add(InconsistentInputs, svc.Identifier, unit.Identifier, "unit is successor of %s, which is missing in its inputs", predecessor)
+				}
+			}
+		}
+	}
+}
+
+//checkCycles does a depth-first search on the successor graph and reports each cycle once, by the unit where it was detected.
+func (v *validator) checkCycles() {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[unitKey]int)
+	path := make([]unitKey, 0)
+	var visit func(key unitKey)
+	visit = func(key unitKey) {
+		state[key] = inProgress
+		path = append(path, key)
+		for _, successor := range v.units[key].SuccessorRefs {
+			next := unitKey{successor.Service, successor.Unit}
+			if _, exists := v.units[next]; !exists {
+				continue
+			}
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case inProgress:
+				cycle := make([]string, 0)
+				for i := len(path) - 1; i >= 0; i-- {
+					cycle = append([]string{path[i].String()}, cycle...)
+					if path[i] == next {
+						break
+					}
+				}
+				cycle = append(cycle, next.String())
+				v.add(Cycle, key.service, key.unit, "unit graph contains a cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = done
+	}
+	//iterate in order of the architecture, so results are deterministic
+	for _, svc := range v.architecture.Services {
+		for _, unit := range svc.Units {
+			key := unitKey{svc.Identifier, unit.Identifier}
+			if state[key] == unvisited {
+				visit(key)
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package executionmodel
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dominik-/t-race/api"
+)
+
+var testValidationOptions = ValidationOptions{
+	WorkTypes:          []string{"constant", "mixture"},
+	SinkProviders:      []string{"jaeger"},
+	PropagationFormats: []string{"w3c", "b3"},
+	ArrivalTypes:       []string{"poisson"},
+	OverloadPolicies:   []string{"drop", "queue"},
+	CheckWork: func(work *api.Work) error {
+		if work.DistType == "constant" && work.Parameters["value"] < 0 {
+			return fmt.Errorf("value must not be negative")
+		}
+		return nil
+	},
+}
+
+//validArchitecture returns an architecture in which frontend/root calls backend/b, which declares its input.
+func validArchitecture() *Architecture {
+	return &Architecture{
+		Name:        "test",
+		Propagation: "w3c",
+		Services: []*Service{{
+			Identifier: "frontend",
+			SinkRef:    "s1",
+			Units: []*Unit{{
+				Identifier:      "root",
+				WorkRef:         "w1",
+				ThroughputRatio: 1,
+				Arrival:         &Arrival{Type: "poisson"},
+				SuccessorRefs:   []*UnitRef{{Service: "backend", Unit: "b", Sync: true}},
+			}},
+		}, {
+			Identifier:  "backend",
+			SinkRef:     "s1",
+			Concurrency: &Concurrency{Limit: 10, Policy: "queue", Queue: 5},
+			Units: []*Unit{{
+				Identifier: "b",
+				WorkRef:    "w2",
+				InputRefs:  []*UnitRef{{Service: "frontend", Unit: "root"}},
+			}},
+		}},
+		Sinks: []*Sink{{Identifier: "s1", Provider: "jaeger", Address: "localhost:6831"}},
+		WorkTemplates: []*Work{
+			{Identifier: "w1", Type: "constant", Params: map[string]float64{"value": 100}},
+			{Identifier: "w2", Type: "mixture", Components: []*WorkComponent{{WorkRef: "w1", Weight: 1}}},
+		},
+	}
+}
+
+func TestValidateValidArchitecture(t *testing.T) {
+	if errs := ValidateArchitecture(validArchitecture(), testValidationOptions); errs != nil {
+		t.Errorf("expected no errors, got\n%v", errs)
+	}
+}
+
+func TestValidateArchitecture(t *testing.T) {
+	//each test breaks the valid architecture and expects errors with the given kind, service and unit
+	for _, test := range []struct {
+		name     string
+		breaks   func(a *Architecture, frontend, backend *Service)
+		expected []ValidationError
+	}{
+		{"dangling service", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units[0].SuccessorRefs = append(frontend.Units[0].SuccessorRefs, &UnitRef{Service: "db", Unit: "query"})
+		}, []ValidationError{{Kind: DanglingReference, Service: "frontend", Unit: "root"}}},
+		{"dangling unit", func(a *Architecture, frontend, backend *Service) {
+			backend.Units[0].InputRefs = append(backend.Units[0].InputRefs, &UnitRef{Service: "frontend", Unit: "login"})
+		}, []ValidationError{{Kind: DanglingReference, Service: "backend", Unit: "b"}}},
+		{"incomplete reference", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units[0].SuccessorRefs[0].Unit = ""
+		}, []ValidationError{{Kind: DanglingReference, Service: "frontend", Unit: "root"}, {Kind: InconsistentInputs, Service: "backend", Unit: "b"}}},
+		{"dangling work", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units[0].WorkRef = "w3"
+		}, []ValidationError{{Kind: DanglingReference, Service: "frontend", Unit: "root"}}},
+		{"dangling mixture component", func(a *Architecture, frontend, backend *Service) {
+			a.WorkTemplates[1].Components[0].WorkRef = "w3"
+		}, []ValidationError{{Kind: DanglingReference}}},
+		{"dangling think time", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units[0].ThroughputRatio = 0
+			frontend.Units[0].Arrival = nil
+			frontend.Units[0].Users = &Users{Count: 5, ThinkTimeRef: "w3"}
+		}, []ValidationError{{Kind: DanglingReference, Service: "frontend", Unit: "root"}}},
+		{"dangling sink", func(a *Architecture, frontend, backend *Service) {
+			backend.SinkRef = "s2"
+		}, []ValidationError{{Kind: MissingSink, Service: "backend"}}},
+		{"no sinks", func(a *Architecture, frontend, backend *Service) {
+			a.Sinks = nil
+		}, []ValidationError{{Kind: MissingSink}, {Kind: MissingSink, Service: "frontend"}, {Kind: MissingSink, Service: "backend"}}},
+		{"cycle", func(a *Architecture, frontend, backend *Service) {
+			backend.Units[0].SuccessorRefs = []*UnitRef{{Service: "frontend", Unit: "root"}}
+		}, []ValidationError{{Kind: Cycle, Service: "backend", Unit: "b"}}},
+		{"mixture cycle", func(a *Architecture, frontend, backend *Service) {
+			a.WorkTemplates[1].Components = append(a.WorkTemplates[1].Components, &WorkComponent{WorkRef: "w2", Weight: 1})
+		}, []ValidationError{{Kind: Cycle}}},
+		{"duplicate service", func(a *Architecture, frontend, backend *Service) {
+			a.Services = append(a.Services, &Service{Identifier: "backend", SinkRef: "s1"})
+		}, []ValidationError{{Kind: DuplicateIdentifier, Service: "backend"}}},
+		{"duplicate unit", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units = append(frontend.Units, &Unit{Identifier: "root"})
+		}, []ValidationError{{Kind: DuplicateIdentifier, Service: "frontend", Unit: "root"}}},
+		{"duplicate sink and work", func(a *Architecture, frontend, backend *Service) {
+			a.Sinks = append(a.Sinks, &Sink{Identifier: "s1", Provider: "jaeger"})
+			a.WorkTemplates = append(a.WorkTemplates, &Work{Identifier: "w1", Type: "constant"})
+		}, []ValidationError{{Kind: DuplicateIdentifier}, {Kind: DuplicateIdentifier}}},
+		{"input without successor", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units[0].SuccessorRefs = nil
+		}, []ValidationError{{Kind: InconsistentInputs, Service: "backend", Unit: "b"}}},
+		{"successor missing in inputs", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units = append(frontend.Units, &Unit{Identifier: "login", SuccessorRefs: []*UnitRef{{Service: "backend", Unit: "b"}}})
+		}, []ValidationError{{Kind: InconsistentInputs, Service: "backend", Unit: "b"}}},
+		{"unknown work type", func(a *Architecture, frontend, backend *Service) {
+			a.WorkTemplates[0].Type = "Constant"
+		}, []ValidationError{{Kind: UnknownWorkType}}},
+		{"invalid work", func(a *Architecture, frontend, backend *Service) {
+			a.WorkTemplates[0].Params["value"] = -1
+		}, []ValidationError{{Kind: InvalidWork}}},
+		{"non-positive mixture weight", func(a *Architecture, frontend, backend *Service) {
+			a.WorkTemplates[1].Components[0].Weight = 0
+		}, []ValidationError{{Kind: InvalidWork}}},
+		{"unknown provider", func(a *Architecture, frontend, backend *Service) {
+			a.Sinks[0].Provider = "xray"
+		}, []ValidationError{{Kind: UnknownProvider}}},
+		{"unknown propagation", func(a *Architecture, frontend, backend *Service) {
+			a.Propagation = "xray"
+			backend.Propagation = "B3"
+			frontend.Propagation = "uber"
+		}, []ValidationError{{Kind: UnknownPropagation}, {Kind: UnknownPropagation, Service: "frontend"}}},
+		{"negative ratio", func(a *Architecture, frontend, backend *Service) {
+			backend.Units[0].ThroughputRatio = -0.5
+		}, []ValidationError{{Kind: InvalidRatio, Service: "backend", Unit: "b"}}},
+		{"no load", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units[0].ThroughputRatio = 0
+			frontend.Units[0].Arrival = nil
+		}, []ValidationError{{Kind: InvalidRatio}}},
+		{"unknown arrival", func(a *Architecture, frontend, backend *Service) {
+			a.Arrival = &Arrival{Type: "bursty"}
+			frontend.Units[0].Arrival.Type = "onoff"
+		}, []ValidationError{{Kind: UnknownArrivalType}, {Kind: UnknownArrivalType, Service: "frontend", Unit: "root"}}},
+		{"arrival without ratio", func(a *Architecture, frontend, backend *Service) {
+			backend.Units[0].Arrival = &Arrival{Type: "poisson"}
+		}, []ValidationError{{Kind: InvalidArrival, Service: "backend", Unit: "b"}}},
+		{"invalid users", func(a *Architecture, frontend, backend *Service) {
+			frontend.Units[0].Users = &Users{Count: 0, ThinkTimeRef: "w1"}
+		}, []ValidationError{
+			{Kind: InvalidUsers, Service: "frontend", Unit: "root"},
+			{Kind: InvalidUsers, Service: "frontend", Unit: "root"},
+			{Kind: InvalidUsers, Service: "frontend", Unit: "root"},
+		}},
+		{"invalid concurrency", func(a *Architecture, frontend, backend *Service) {
+			a.Concurrency = &Concurrency{Limit: 0, Policy: "drop"}
+			backend.Concurrency.Queue = -1
+			backend.Units[0].Concurrency = &Concurrency{Limit: 1, Policy: "block"}
+		}, []ValidationError{
+			{Kind: InvalidConcurrency},
+			{Kind: InvalidConcurrency, Service: "backend"},
+			{Kind: InvalidConcurrency, Service: "backend", Unit: "b"},
+		}},
+	} {
+		architecture := validArchitecture()
+		test.breaks(architecture, architecture.Services[0], architecture.Services[1])
+		errs := ValidateArchitecture(architecture, testValidationOptions)
+		actual := make([]ValidationError, len(errs))
+		for i, err := range errs {
+			actual[i] = ValidationError{Kind: err.Kind, Service: err.Service, Unit: err.Unit}
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected errors %+v, got\n%v", test.name, test.expected, errs)
+		}
+	}
+}
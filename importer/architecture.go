@@ -0,0 +1,242 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dominik-/t-race/executionmodel"
+	"gopkg.in/yaml.v3"
+)
+
+//Identifiers of the single environment and sink of imported architectures.
+const (
+	importedEnvironment = "env01"
+	importedSink        = "sink01"
+)
+
+//unsafeIdentifier matches characters, which are replaced in identifiers of services and work templates, since they're used in file names of results.
+var unsafeIdentifier = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+//identifiers assigns unique identifiers to names, replacing characters which aren't safe in file names.
+type identifiers struct {
+	ids  map[string]string
+	used map[string]bool
+}
+
+func newIdentifiers() *identifiers {
+	return &identifiers{ids: make(map[string]string), used: make(map[string]bool)}
+}
+
+func (i *identifiers) get(name string) string {
+	if id, ok := i.ids[name]; ok {
+		return id
+	}
+	base := strings.Trim(unsafeIdentifier.ReplaceAllString(name, "-"), "-")
+	if base == "" {
+		base = "unknown"
+	}
+	id := base
+	for n := 2; i.used[id]; n++ {
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+	i.ids[name] = id
+	i.used[id] = true
+	return id
+}
+
+//build converts the graph to an architecture. Calls made by fewer than MinCallShare of the invocations of their caller, and calls which would close a cycle, are left out.
+func (g *graph) build(options Options) (*executionmodel.Architecture, *Report) {
+	report := &Report{}
+	if seconds := g.last.Sub(g.first).Seconds(); seconds > 0 && g.roots > 1 {
+		//n roots in the time from first to last root are n-1 intervals
+		report.BaselineThroughput = round(float64(g.roots-1)/seconds, 3)
+	}
+	keys := make([]unitKey, 0, len(g.units))
+	for key := range g.units {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].service != keys[b].service {
+			return keys[a].service < keys[b].service
+		}
+		return keys[a].operation < keys[b].operation
+	})
+	included := g.includedCalls(keys, options.MinCallShare, report)
+	architecture := &executionmodel.Architecture{
+		Name: options.Name,
+		Sinks: []*executionmodel.Sink{{
+			Identifier:     importedSink,
+			Provider:       options.SinkProvider,
+			Address:        options.SinkAddress,
+			EnvironmentRef: importedEnvironment,
+		}},
+	}
+	serviceIDs := newIdentifiers()
+	workIDs := newIdentifiers()
+	services := make(map[string]*executionmodel.Service)
+	for _, key := range keys {
+		u := g.units[key]
+		svc, ok := services[key.service]
+		if !ok {
+			svc = &executionmodel.Service{
+				Identifier:     serviceIDs.get(key.service),
+				EnvironmentRef: importedEnvironment,
+				SinkRef:        importedSink,
+			}
+			services[key.service] = svc
+			architecture.Services = append(architecture.Services, svc)
+		}
+		unit := &executionmodel.Unit{Identifier: unitIdentifier(key)}
+		unitReport := &UnitReport{Service: svc.Identifier, Unit: unit.Identifier, Invocations: u.invocations, Roots: u.roots}
+		if g.roots > 0 && u.roots > 0 {
+			unit.ThroughputRatio = round(float64(u.roots)/float64(g.roots), 6)
+			unitReport.Ratio = unit.ThroughputRatio
+		}
+		workID := workIDs.get(key.service + "-" + key.operation)
+		if work, distance := fitWork(workID, u.selfTimes); work != nil {
+			architecture.WorkTemplates = append(architecture.WorkTemplates, work)
+			unit.WorkRef = work.Identifier
+			unitReport.Work = work.Type
+			unitReport.Distance = round(distance, 4)
+		} else if len(u.selfTimes) == 0 && options.PlaceholderWork > 0 {
+			work := &executionmodel.Work{Identifier: workID, Type: "constant", Params: map[string]float64{"value": round(options.PlaceholderWork, 1)}}
+			architecture.WorkTemplates = append(architecture.WorkTemplates, work)
+			unit.WorkRef = work.Identifier
+			unitReport.Work = work.Type
+		}
+		for _, call := range included[key] {
+			unit.SuccessorRefs = append(unit.SuccessorRefs, &executionmodel.UnitRef{
+				Service: serviceIDs.get(call.to.service),
+				Unit:    unitIdentifier(call.to),
+				Sync:    call.sync,
+			})
+		}
+		svc.Units = append(svc.Units, unit)
+		report.Units = append(report.Units, unitReport)
+	}
+	return architecture, report
+}
+
+//unitIdentifier is the operation name of a unit, which is unique within its service.
+func unitIdentifier(key unitKey) string {
+	if key.operation == "" {
+		return "unknown"
+	}
+	return key.operation
+}
+
+type includedCall struct {
+	to   unitKey
+	sync bool
+}
+
+//includedCalls decides which calls become successors and reports all calls. Cycles are broken by a depth-first search in the order of units, which leaves out the call
+//that closes a cycle.
+func (g *graph) includedCalls(keys []unitKey, minShare float64, report *Report) map[unitKey][]*includedCall {
+	candidates := make(map[unitKey][]unitKey)
+	reports := make(map[[2]unitKey]*CallReport)
+	for _, key := range keys {
+		u := g.units[key]
+		callees := make([]unitKey, 0, len(u.calls))
+		for callee := range u.calls {
+			callees = append(callees, callee)
+		}
+		sort.Slice(callees, func(a, b int) bool { return callees[a].String() < callees[b].String() })
+		for _, callee := range callees {
+			c := u.calls[callee]
+			r := &CallReport{
+				From:               key.String(),
+				To:                 callee.String(),
+				Share:              round(float64(c.invocations)/float64(u.invocations), 4),
+				CallsPerInvocation: round(float64(c.calls)/float64(c.invocations), 2),
+				Sync:               c.sync*2 >= c.calls,
+				Included:           true,
+			}
+			if reason, ok := g.excluded[[2]unitKey{key, callee}]; ok {
+				r.Included = false
+				r.Reason = reason
+			} else if float64(c.invocations)/float64(u.invocations) < minShare {
+				r.Included = false
+				r.Reason = fmt.Sprintf("made by fewer than %.0f%% of invocations", minShare*100)
+			} else {
+				candidates[key] = append(candidates[key], callee)
+			}
+			reports[[2]unitKey{key, callee}] = r
+			report.Calls = append(report.Calls, r)
+		}
+	}
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[unitKey]int)
+	included := make(map[unitKey][]*includedCall)
+	var visit func(key unitKey)
+	visit = func(key unitKey) {
+		state[key] = inProgress
+		for _, callee := range candidates[key] {
+			r := reports[[2]unitKey{key, callee}]
+			if state[callee] == inProgress {
+				r.Included = false
+				r.Reason = "closes a cycle"
+				continue
+			}
+			if state[callee] == unvisited {
+				visit(callee)
+			}
+			included[key] = append(included[key], &includedCall{to: callee, sync: r.Sync})
+		}
+		state[key] = done
+	}
+	//roots first, so cycles are broken at the call which leads back towards the entry points
+	for _, roots := range []bool{true, false} {
+		for _, key := range keys {
+			if (g.units[key].roots > 0) == roots && state[key] == unvisited {
+				visit(key)
+			}
+		}
+	}
+	return included
+}
+
+//WriteArchitecture writes an architecture as service descriptor file.
+func WriteArchitecture(architecture *executionmodel.Architecture, out io.Writer) error {
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(architecture); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+//WriteText writes a summary of the import and lists the calls which were left out.
+func (r *Report) WriteText(out io.Writer) {
+	if r.Traces > 0 {
+		fmt.Fprintf(out, "Read %d spans of %d traces.\n", r.Spans, r.Traces)
+	}
+	if r.Dependencies > 0 {
+		fmt.Fprintf(out, "Read %d dependencies.\n", r.Dependencies)
+	}
+	if r.BaselineThroughput > 0 {
+		fmt.Fprintf(out, "Observed %.3f traces per second, use --baselineTP %.0f to reproduce the observed rates.\n", r.BaselineThroughput, r.BaselineThroughput)
+	}
+	fmt.Fprintf(out, "%-50s %12s %8s %10s %12s\n", "unit", "invocations", "ratio", "work", "KS distance")
+	for _, u := range r.Units {
+		work := u.Work
+		if work == "" {
+			work = "-"
+		}
+		fmt.Fprintf(out, "%-50s %12d %8.4f %10s %12.4f\n", u.Service+"/"+u.Unit, u.Invocations, u.Ratio, work, u.Distance)
+	}
+	for _, c := range r.Calls {
+		if !c.Included {
+			fmt.Fprintf(out, "Left out call %s -> %s (share %.2f, %.2f calls per invocation): %s.\n", c.From, c.To, c.Share, c.CallsPerInvocation, c.Reason)
+		} else if c.CallsPerInvocation > 1 {
+			fmt.Fprintf(out, "Call %s -> %s is made %.2f times per invocation, but successors are called once.\n", c.From, c.To, c.CallsPerInvocation)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dominik-/t-race/executionmodel"
+)
+
+//ServiceOperation is the operation of units in dependency graphs, which only know services.
+const ServiceOperation = "default"
+
+//Dependency is an edge of a dependency graph: the number of calls from an operation of the caller to an operation of the callee, within the time the graph covers.
+//Operations are ServiceOperation, if the graph only contains services.
+type Dependency struct {
+	CallerService   string
+	CallerOperation string
+	CalleeService   string
+	CalleeOperation string
+	Calls           int64
+}
+
+//dependencyReader parses all edges of a file in one format.
+type dependencyReader func(data []byte) ([]*Dependency, error)
+
+var dependencyReaderRegistry map[string]dependencyReader
+
+func init() {
+	dependencyReaderRegistry = map[string]dependencyReader{
+		"dependencies": readJaegerDependencies,
+		"edges":        readEdges,
+	}
+}
+
+//DependencyFormats returns the names of all dependency graph formats in alphabetical order. They aren't detected with AutoFormat.
+func DependencyFormats() []string {
+	names := make([]string, 0, len(dependencyReaderRegistry))
+	for name := range dependencyReaderRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//IsDependencyFormat returns whether format is the name of a dependency graph format.
+func IsDependencyFormat(format string) bool {
+	_, ok := dependencyReaderRegistry[strings.ToLower(format)]
+	return ok
+}
+
+//ReadDependencyFiles reads the edges of all files in the given format.
+func ReadDependencyFiles(files []string, format string) ([]*Dependency, error) {
+	reader, ok := dependencyReaderRegistry[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown dependency format %s, use one of: %s", format, strings.Join(DependencyFormats(), ", "))
+	}
+	var dependencies []*Dependency
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileDependencies, err := reader(data)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read %s as %s: %v", file, format, err)
+		}
+		dependencies = append(dependencies, fileDependencies...)
+	}
+	return dependencies, nil
+}
+
+//jaegerDependency is a link returned by /api/dependencies of the jaeger query API.
+type jaegerDependency struct {
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+	CallCount int64  `json:"callCount"`
+}
+
+//readJaegerDependencies reads responses of /api/dependencies, i.e. links in "data", or plain arrays of links.
+func readJaegerDependencies(data []byte) ([]*Dependency, error) {
+	var dependencies []*Dependency
+	err := decodeAll(data, func(decoder *json.Decoder) error {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		var links []*jaegerDependency
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+			if err := json.Unmarshal(raw, &links); err != nil {
+				return err
+			}
+		} else {
+			response := struct {
+				Data []*jaegerDependency `json:"data"`
+			}{}
+			if err := json.Unmarshal(raw, &response); err != nil {
+				return err
+			}
+			links = response.Data
+		}
+		for _, link := range links {
+			dependencies = append(dependencies, &Dependency{
+				CallerService:   link.Parent,
+				CallerOperation: ServiceOperation,
+				CalleeService:   link.Child,
+				CalleeOperation: ServiceOperation,
+				Calls:           link.CallCount,
+			})
+		}
+		return nil
+	})
+	return dependencies, err
+}
+
+//readEdges reads CSV files with the columns caller,callee,calls of service graphs, e.g. exported from a service mesh,
+//or callerService,callerOperation,calleeService,calleeOperation,calls. A header line and lines starting with # are skipped.
+func readEdges(data []byte) ([]*Dependency, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var dependencies []*Dependency
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return dependencies, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) != 3 && len(record) != 5 {
+			return nil, fmt.Errorf("line %d has %d columns, expected caller,callee,calls or callerService,callerOperation,calleeService,calleeOperation,calls", line, len(record))
+		}
+		calls, err := strconv.ParseInt(strings.TrimSpace(record[len(record)-1]), 10, 64)
+		if err != nil {
+			//the header has a name instead of a count
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("line %d has invalid call count %q", line, record[len(record)-1])
+		}
+		d := &Dependency{CallerOperation: ServiceOperation, CalleeOperation: ServiceOperation, Calls: calls}
+		if len(record) == 3 {
+			d.CallerService, d.CalleeService = record[0], record[1]
+		} else {
+			d.CallerService, d.CallerOperation, d.CalleeService, d.CalleeOperation = record[0], record[1], record[2], record[3]
+		}
+		dependencies = append(dependencies, d)
+	}
+}
+
+//InferFromDependencies infers an architecture from a dependency graph. Calls between the same units are summed up, and cycles are broken at their least frequent call.
+//Units without callers are root units; the invocations of a root unit are estimated as its largest number of calls to a successor, the invocations of other units as
+//the sum of their incoming calls. Ratios of root units and the share of invocations making a call are derived from these estimates. All calls are assumed to be
+//synchronous and units get placeholder work.
+func InferFromDependencies(dependencies []*Dependency, options Options) (*executionmodel.Architecture, *Report, error) {
+	g := newGraph()
+	calls := make(map[unitKey]map[unitKey]int64)
+	dependencyCount := 0
+	for _, d := range dependencies {
+		if d.Calls <= 0 {
+			continue
+		}
+		dependencyCount++
+		caller := unitKey{d.CallerService, d.CallerOperation}
+		callee := unitKey{d.CalleeService, d.CalleeOperation}
+		g.unit(caller)
+		g.unit(callee)
+		if calls[caller] == nil {
+			calls[caller] = make(map[unitKey]int64)
+		}
+		calls[caller][callee] += d.Calls
+	}
+	if len(g.units) == 0 {
+		return nil, nil, fmt.Errorf("no dependencies with calls found")
+	}
+	cyclic := breakCycles(calls)
+	called := make(map[unitKey]int64)
+	for caller, callees := range calls {
+		for callee, c := range callees {
+			if cyclic[[2]unitKey{caller, callee}] {
+				g.excluded[[2]unitKey{caller, callee}] = "closes a cycle"
+			} else {
+				called[callee] += c
+			}
+		}
+	}
+	for key, u := range g.units {
+		invocations := called[key]
+		if invocations == 0 {
+			for callee, c := range calls[key] {
+				if c > invocations && !cyclic[[2]unitKey{key, callee}] {
+					invocations = c
+				}
+			}
+			u.roots = int(invocations)
+			g.roots += u.roots
+		}
+		u.invocations = int(invocations)
+		for callee, c := range calls[key] {
+			u.calls[callee] = &callStats{invocations: int(min64(c, invocations)), calls: int(c), sync: int(c)}
+		}
+	}
+	architecture, report := g.build(options)
+	report.Dependencies = dependencyCount
+	return architecture, report, nil
+}
+
+//breakCycles returns the calls which close cycles. Calls are added to an acyclic graph in the order of their descending number, and left out if the callee already
+//reaches the caller.
+func breakCycles(calls map[unitKey]map[unitKey]int64) map[[2]unitKey]bool {
+	type edge struct {
+		from, to unitKey
+		calls    int64
+	}
+	var edges []edge
+	for caller, callees := range calls {
+		for callee, c := range callees {
+			edges = append(edges, edge{caller, callee, c})
+		}
+	}
+	sort.Slice(edges, func(a, b int) bool {
+		if edges[a].calls != edges[b].calls {
+			return edges[a].calls > edges[b].calls
+		}
+		return edges[a].from.String()+" "+edges[a].to.String() < edges[b].from.String()+" "+edges[b].to.String()
+	})
+	acyclic := make(map[unitKey][]unitKey)
+	var reaches func(from, to unitKey, visited map[unitKey]bool) bool
+	reaches = func(from, to unitKey, visited map[unitKey]bool) bool {
+		if from == to {
+			return true
+		}
+		visited[from] = true
+		for _, next := range acyclic[from] {
+			if !visited[next] && reaches(next, to, visited) {
+				return true
+			}
+		}
+		return false
+	}
+	cyclic := make(map[[2]unitKey]bool)
+	for _, e := range edges {
+		if reaches(e.to, e.from, make(map[unitKey]bool)) {
+			cyclic[[2]unitKey{e.from, e.to}] = true
+			continue
+		}
+		acyclic[e.from] = append(acyclic[e.from], e.to)
+	}
+	return cyclic
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestReadEdges(t *testing.T) {
+	data := []byte(`caller,callee,calls
+# exported from the mesh
+frontend,cart,900
+  frontend, catalog, 1000
+frontend,checkout,cart,add,12
+`)
+	dependencies, err := readEdges(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*Dependency{
+		{CallerService: "frontend", CallerOperation: ServiceOperation, CalleeService: "cart", CalleeOperation: ServiceOperation, Calls: 900},
+		{CallerService: "frontend", CallerOperation: ServiceOperation, CalleeService: "catalog", CalleeOperation: ServiceOperation, Calls: 1000},
+		{CallerService: "frontend", CallerOperation: "checkout", CalleeService: "cart", CalleeOperation: "add", Calls: 12},
+	}
+	if !reflect.DeepEqual(dependencies, expected) {
+		t.Errorf("expected %+v, got %+v", expected, dependencies)
+	}
+}
+
+func TestReadEdgesRejectsInvalidLines(t *testing.T) {
+	for name, data := range map[string]string{
+		"four columns":     "a,b,c,1\n",
+		"two columns":      "a,b,c\na,1\n",
+		"invalid count":    "a,b,1\na,c,many\n",
+		"header not first": "a,b,1\ncaller,callee,calls\n",
+	} {
+		if _, err := readEdges([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadJaegerDependencies(t *testing.T) {
+	expected := []*Dependency{
+		{CallerService: "frontend", CallerOperation: ServiceOperation, CalleeService: "cart", CalleeOperation: ServiceOperation, Calls: 900},
+		{CallerService: "cart", CallerOperation: ServiceOperation, CalleeService: "redis", CalleeOperation: ServiceOperation, Calls: 1800},
+	}
+	for name, data := range map[string]string{
+		"response": `{"data":[{"parent":"frontend","child":"cart","callCount":900},{"parent":"cart","child":"redis","callCount":1800}],"total":2}`,
+		"array":    `[{"parent":"frontend","child":"cart","callCount":900},{"parent":"cart","child":"redis","callCount":1800}]`,
+		"stream":   `{"data":[{"parent":"frontend","child":"cart","callCount":900}]} [{"parent":"cart","child":"redis","callCount":1800}]`,
+	} {
+		dependencies, err := readJaegerDependencies([]byte(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(dependencies, expected) {
+			t.Errorf("%s: expected %+v, got %+v", name, expected, dependencies)
+		}
+	}
+}
+
+func serviceDependency(caller, callee string, calls int64) *Dependency {
+	return &Dependency{CallerService: caller, CallerOperation: ServiceOperation, CalleeService: callee, CalleeOperation: ServiceOperation, Calls: calls}
+}
+
+func TestInferFromDependenciesBreaksCycles(t *testing.T) {
+	dependencies := []*Dependency{
+		serviceDependency("frontend", "cart", 900),
+		serviceDependency("frontend", "catalog", 1000),
+		serviceDependency("cart", "redis", 1800),
+		serviceDependency("admin", "catalog", 100),
+		//the rare call back to the frontend would make it a successor of catalog, leaving admin as the only root
+		serviceDependency("catalog", "frontend", 5),
+		serviceDependency("frontend", "catalog", 0),
+	}
+	architecture, report, err := InferFromDependencies(dependencies, Options{Name: "test", MinCallShare: 0.5, PlaceholderWork: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Dependencies != 5 {
+		t.Errorf("expected 5 dependencies with calls, got %d", report.Dependencies)
+	}
+	expected := []string{
+		"admin/default 0.09 -> catalog/default sync",
+		"cart/default 0.00 -> redis/default sync",
+		"catalog/default 0.00 ->",
+		"frontend/default 0.91 -> cart/default sync catalog/default sync",
+		"redis/default 0.00 ->",
+	}
+	if actual := summarize(architecture); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected units\n%v\ngot\n%v", expected, actual)
+	}
+	for _, call := range report.Calls {
+		cyclic := call.From == "catalog/default" && call.To == "frontend/default"
+		if cyclic != !call.Included || cyclic && call.Reason != "closes a cycle" {
+			t.Errorf("expected only the call from catalog to frontend to be left out for closing a cycle, got %+v", call)
+		}
+	}
+	for _, unit := range report.Units {
+		if unit.Service == "frontend" && (unit.Roots != 1000 || math.Abs(unit.Ratio-1000.0/1100) > 1e-4) {
+			t.Errorf("expected 1000 of 1100 root invocations in the frontend, got %+v", unit)
+		}
+	}
+}
+
+func TestBreakCyclesDropsLeastFrequentCall(t *testing.T) {
+	a, b, c := unitKey{"a", ServiceOperation}, unitKey{"b", ServiceOperation}, unitKey{"c", ServiceOperation}
+	calls := map[unitKey]map[unitKey]int64{
+		a: {b: 10},
+		b: {c: 8},
+		c: {a: 3, b: 20},
+	}
+	//b -> c closes the more frequent cycle c -> b -> c, without it c -> a doesn't close a cycle anymore
+	expected := map[[2]unitKey]bool{{b, c}: true}
+	if cyclic := breakCycles(calls); !reflect.DeepEqual(cyclic, expected) {
+		t.Errorf("expected cyclic calls %v, got %v", expected, cyclic)
+	}
+}
@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/dominik-/t-race/executionmodel"
+)
+
+//constantVariation is the coefficient of variation, below which self-times are emulated by constant work.
+const constantVariation = 0.05
+
+//candidate is a distribution with parameters, which is fitted to self-times.
+type candidate struct {
+	distribution string
+	params       map[string]float64
+	cdf          func(x float64) float64
+}
+
+//fitWork fits a work template to self-times in microseconds. It returns the distribution with the smallest Kolmogorov-Smirnov distance to the samples out of
+//exponential, lognormal and truncnormal, or constant work, if the samples hardly vary. Units without self-time of at least a microsecond don't get work.
+func fitWork(id string, samples []float64) (*executionmodel.Work, float64) {
+	if len(samples) == 0 {
+		return nil, 0
+	}
+	mean, stddev := moments(samples)
+	if mean < 1 {
+		return nil, 0
+	}
+	if len(samples) < 2 || stddev < constantVariation*mean {
+		return &executionmodel.Work{Identifier: id, Type: "constant", Params: map[string]float64{"value": round(mean, 1)}}, 0
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	logs := make([]float64, len(samples))
+	for i, x := range samples {
+		logs[i] = math.Log(math.Max(x, 1))
+	}
+	mu, sigma := moments(logs)
+	candidates := []*candidate{{
+		distribution: "exponential",
+		params:       map[string]float64{"mean": round(mean, 1)},
+		cdf:          func(x float64) float64 { return 1 - math.Exp(-x/mean) },
+	}, {
+		distribution: "truncnormal",
+		params:       map[string]float64{"mean": round(mean, 1), "stddev": round(stddev, 1)},
+		cdf: func(x float64) float64 {
+			lower := normalCDF(-mean / stddev)
+			return (normalCDF((x-mean)/stddev) - lower) / (1 - lower)
+		},
+	}}
+	if sigma > 0 {
+		candidates = append(candidates, &candidate{
+			distribution: "lognormal",
+			params:       map[string]float64{"mu": round(mu, 4), "sigma": round(sigma, 4)},
+			cdf:          func(x float64) float64 { return normalCDF((math.Log(math.Max(x, 1)) - mu) / sigma) },
+		})
+	}
+	var best *candidate
+	bestDistance := math.Inf(1)
+	for _, c := range candidates {
+		if distance := ksDistance(sorted, c.cdf); distance < bestDistance {
+			best, bestDistance = c, distance
+		}
+	}
+	return &executionmodel.Work{Identifier: id, Type: best.distribution, Params: best.params}, bestDistance
+}
+
+//moments returns mean and standard deviation of samples.
+func moments(samples []float64) (float64, float64) {
+	var sum, squares float64
+	for _, x := range samples {
+		sum += x
+	}
+	mean := sum / float64(len(samples))
+	for _, x := range samples {
+		squares += (x - mean) * (x - mean)
+	}
+	if len(samples) < 2 {
+		return mean, 0
+	}
+	return mean, math.Sqrt(squares / float64(len(samples)-1))
+}
+
+//ksDistance is the largest distance between the empirical CDF of sorted samples and cdf.
+func ksDistance(sorted []float64, cdf func(float64) float64) float64 {
+	n := float64(len(sorted))
+	var distance float64
+	for i, x := range sorted {
+		p := cdf(x)
+		distance = math.Max(distance, math.Max(math.Abs(float64(i+1)/n-p), math.Abs(p-float64(i)/n)))
+	}
+	return distance
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+}
@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFitWorkPicksFamily(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sample := func(next func() float64) []float64 {
+		samples := make([]float64, 2000)
+		for i := range samples {
+			samples[i] = next()
+		}
+		return samples
+	}
+	for _, test := range []struct {
+		family  string
+		samples []float64
+	}{
+		{"constant", sample(func() float64 { return 5000 + rng.Float64()*100 })},
+		{"constant", []float64{1234}},
+		{"exponential", sample(func() float64 { return rng.ExpFloat64() * 2000 })},
+		{"truncnormal", sample(func() float64 { return 10000 + rng.NormFloat64()*1000 })},
+		{"lognormal", sample(func() float64 { return math.Exp(7 + rng.NormFloat64()) })},
+	} {
+		work, distance := fitWork("w", test.samples)
+		if work == nil || work.Type != test.family {
+			t.Errorf("expected %s work, got %+v", test.family, work)
+			continue
+		}
+		if distance > 0.05 {
+			t.Errorf("%s: expected a close fit, got distance %.3f", test.family, distance)
+		}
+	}
+}
+
+func TestFitWorkWithoutSelfTime(t *testing.T) {
+	for _, samples := range [][]float64{nil, {0, 0.5, 0.2}} {
+		if work, _ := fitWork("w", samples); work != nil {
+			t.Errorf("expected no work for samples %v, got %+v", samples, work)
+		}
+	}
+}
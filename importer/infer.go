@@ -0,0 +1,321 @@
+package importer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dominik-/t-race/executionmodel"
+)
+
+//Options control how an architecture is inferred.
+type Options struct {
+	Name string
+	//MinCallShare is the share of invocations of a unit, which have to call a successor, so the call is added to the architecture.
+	//Units of t-race call all their successors on every invocation, so rare calls either have to be left out or always be made.
+	MinCallShare float64
+	//SinkProvider and SinkAddress configure the single sink of the architecture, which all services send their spans to.
+	SinkProvider string
+	SinkAddress  string
+	//PlaceholderWork is the constant work in microseconds of units without observed self-times, i.e. of units imported from dependency graphs.
+	PlaceholderWork float64
+}
+
+//Report summarizes the inferred architecture and the observations, which couldn't be represented in it.
+type Report struct {
+	Traces int `json:"traces"`
+	Spans  int `json:"spans"`
+	//Dependencies is the number of edges of dependency graphs with calls.
+	Dependencies int `json:"dependencies"`
+	//BaselineThroughput is the observed rate of traces per second, i.e. the baselineTP which reproduces the observed rates of root units.
+	//It is 0 if the traces don't span a measurable time.
+	BaselineThroughput float64       `json:"baselineThroughput"`
+	Units              []*UnitReport `json:"units"`
+	Calls              []*CallReport `json:"calls"`
+}
+
+//UnitReport describes a unit, i.e. an operation of a service, and the work fitted to its self-times.
+type UnitReport struct {
+	Service     string  `json:"service"`
+	Unit        string  `json:"unit"`
+	Invocations int     `json:"invocations"`
+	Roots       int     `json:"roots"`
+	Ratio       float64 `json:"ratio"`
+	//Work is the type of the distribution fitted to the self-times, empty if the unit has no measurable self-time.
+	Work string `json:"work,omitempty"`
+	//Distance is the Kolmogorov-Smirnov distance between the self-times and the fitted distribution.
+	Distance float64 `json:"distance,omitempty"`
+}
+
+//CallReport describes the calls of a unit to a successor. Excluded calls are observed, but not part of the architecture.
+type CallReport struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	//Share is the share of invocations of the caller, which made the call at least once.
+	Share              float64 `json:"share"`
+	CallsPerInvocation float64 `json:"callsPerInvocation"`
+	Sync               bool    `json:"sync"`
+	Included           bool    `json:"included"`
+	Reason             string  `json:"reason,omitempty"`
+}
+
+//unitKey identifies a unit by the service and operation of its spans.
+type unitKey struct {
+	service   string
+	operation string
+}
+
+func (k unitKey) String() string {
+	return k.service + "/" + k.operation
+}
+
+//graph is the intermediate model of an architecture, which is inferred from traces or dependencies.
+type graph struct {
+	units map[unitKey]*unitStats
+	//roots is the number of invocations of root units, spanning the time from first to last.
+	roots       int
+	first, last time.Time
+	//excluded are calls, which are left out for the given reason before building the architecture.
+	excluded map[[2]unitKey]string
+}
+
+type unitStats struct {
+	key         unitKey
+	invocations int
+	roots       int
+	//selfTimes are in microseconds
+	selfTimes []float64
+	calls     map[unitKey]*callStats
+}
+
+type callStats struct {
+	//invocations of the caller which made the call at least once
+	invocations int
+	calls       int
+	sync        int
+}
+
+func newGraph() *graph {
+	return &graph{units: make(map[unitKey]*unitStats), excluded: make(map[[2]unitKey]string)}
+}
+
+func (g *graph) unit(key unitKey) *unitStats {
+	u, ok := g.units[key]
+	if !ok {
+		u = &unitStats{key: key, calls: make(map[unitKey]*callStats)}
+		g.units[key] = u
+	}
+	return u
+}
+
+func (g *graph) root(u *unitStats, start time.Time) {
+	u.roots++
+	g.roots++
+	if g.first.IsZero() || start.Before(g.first) {
+		g.first = start
+	}
+	if start.After(g.last) {
+		g.last = start
+	}
+}
+
+//InferFromTraces infers an architecture from spans: spans of servers, consumers and internal operations become units, identified by service and operation name,
+//and calls between them, either directly or through client or producer spans, become successors.
+func InferFromTraces(spans []*Span, options Options) (*executionmodel.Architecture, *Report, error) {
+	if len(spans) == 0 {
+		return nil, nil, fmt.Errorf("no spans found")
+	}
+	traces := make(map[string][]*Span)
+	for _, s := range spans {
+		traces[s.TraceID] = append(traces[s.TraceID], s)
+	}
+	g := newGraph()
+	for _, trace := range traces {
+		g.addTrace(trace)
+	}
+	architecture, report := g.build(options)
+	report.Traces = len(traces)
+	report.Spans = len(spans)
+	return architecture, report, nil
+}
+
+//traceTree indexes the spans of a trace by their ID and parent.
+type traceTree struct {
+	spans    map[string]*Span
+	children map[string][]*Span
+}
+
+func (t *traceTree) parent(s *Span) *Span {
+	if s.ParentID == "" {
+		return nil
+	}
+	return t.spans[s.ParentID]
+}
+
+//isCall returns whether a span is the client side of a call: client and producer spans are calls, and so are spans without a kind, which are started
+//within an operation of their service and only have children in other services, such as the invoke spans of t-race.
+func (t *traceTree) isCall(s *Span) bool {
+	parent := t.parent(s)
+	if parent == nil {
+		return false
+	}
+	if s.Kind == KindClient || s.Kind == KindProducer {
+		return true
+	}
+	if s.Kind != KindUnspecified || parent.Service != s.Service || len(t.children[s.SpanID]) == 0 {
+		return false
+	}
+	for _, child := range t.children[s.SpanID] {
+		if child.Service == s.Service {
+			return false
+		}
+	}
+	return true
+}
+
+//callees returns the units below a call span, skipping nested call spans, e.g. of an HTTP client used by an RPC client.
+func (t *traceTree) callees(call *Span) []*Span {
+	var callees []*Span
+	for _, child := range t.children[call.SpanID] {
+		if t.isCall(child) {
+			callees = append(callees, t.callees(child)...)
+		} else {
+			callees = append(callees, child)
+		}
+	}
+	return callees
+}
+
+//isAsync returns whether the caller didn't wait for the callee: the callee follows from its parent, is called by a producer or consumer, or finishes after the call
+//(or the calling unit, for direct calls).
+func isAsync(caller, call, callee *Span) bool {
+	if callee.FollowsFrom || callee.Kind == KindConsumer {
+		return true
+	}
+	end := caller.End()
+	if call != nil {
+		if call.Kind == KindProducer || call.FollowsFrom {
+			return true
+		}
+		end = call.End()
+	}
+	return callee.End().After(end)
+}
+
+func (g *graph) addTrace(spans []*Span) {
+	tree := &traceTree{spans: make(map[string]*Span, len(spans)), children: make(map[string][]*Span)}
+	for _, s := range spans {
+		tree.spans[s.SpanID] = s
+	}
+	for _, s := range spans {
+		if tree.parent(s) != nil {
+			tree.children[s.ParentID] = append(tree.children[s.ParentID], s)
+		}
+	}
+	for _, s := range spans {
+		if tree.isCall(s) {
+			continue
+		}
+		u := g.unit(unitKey{s.Service, s.Operation})
+		u.invocations++
+		if tree.parent(s) == nil {
+			g.root(u, s.StartTime)
+		}
+		//intervals in which the unit waited for successors, which isn't part of its self-time
+		var waiting []interval
+		calls := make(map[unitKey]*callStats)
+		record := func(caller, call, callee *Span) bool {
+			key := unitKey{callee.Service, callee.Operation}
+			c, ok := calls[key]
+			if !ok {
+				c = &callStats{invocations: 1}
+				calls[key] = c
+			}
+			c.calls++
+			sync := !isAsync(caller, call, callee)
+			if sync {
+				c.sync++
+			}
+			return sync
+		}
+		for _, child := range tree.children[s.SpanID] {
+			if !tree.isCall(child) {
+				if record(s, nil, child) {
+					waiting = append(waiting, spanInterval(child))
+				}
+				continue
+			}
+			callees := tree.callees(child)
+			//calls without instrumented callees, e.g. to a database, are emulated as work of the unit
+			if len(callees) == 0 {
+				continue
+			}
+			for _, callee := range callees {
+				record(s, child, callee)
+			}
+			waiting = append(waiting, spanInterval(child))
+		}
+		for key, c := range calls {
+			total, ok := u.calls[key]
+			if !ok {
+				total = &callStats{}
+				u.calls[key] = total
+			}
+			total.invocations += c.invocations
+			total.calls += c.calls
+			total.sync += c.sync
+		}
+		self := s.Duration - covered(spanInterval(s), waiting)
+		u.selfTimes = append(u.selfTimes, float64(self)/float64(time.Microsecond))
+	}
+}
+
+type interval struct {
+	start, end time.Time
+}
+
+func spanInterval(s *Span) interval {
+	return interval{s.StartTime, s.End()}
+}
+
+//covered returns the length of the union of intervals within bounds.
+func covered(bounds interval, intervals []interval) time.Duration {
+	clipped := make([]interval, 0, len(intervals))
+	for _, i := range intervals {
+		if i.start.Before(bounds.start) {
+			i.start = bounds.start
+		}
+		if i.end.After(bounds.end) {
+			i.end = bounds.end
+		}
+		if i.end.After(i.start) {
+			clipped = append(clipped, i)
+		}
+	}
+	sort.Slice(clipped, func(a, b int) bool { return clipped[a].start.Before(clipped[b].start) })
+	var total time.Duration
+	var current *interval
+	for i := range clipped {
+		if current != nil && !clipped[i].start.After(current.end) {
+			if clipped[i].end.After(current.end) {
+				current.end = clipped[i].end
+			}
+			continue
+		}
+		if current != nil {
+			total += current.end.Sub(current.start)
+		}
+		current = &clipped[i]
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+	return total
+}
+
+//round rounds to the given number of decimals, so the generated file is readable.
+func round(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dominik-/t-race/executionmodel"
+)
+
+//The fixtures in testdata contain the same four traces in each format. web/GET /home (three traces) and web/POST /login (one trace) call users/getUser
+//synchronously through a client span and audit/consume asynchronously through a producer span. users/getUser calls back web/GET /home, which closes a cycle.
+var traceFixtures = map[string]string{
+	"jaeger": "testdata/traces.jaeger.json",
+	"zipkin": "testdata/traces.zipkin.json",
+	"otlp":   "testdata/traces.otlp.json",
+}
+
+//summarize lists the units of an architecture with their ratio and successors, e.g. "web/GET /home 0.75 -> audit/consume async users/getUser sync".
+func summarize(architecture *executionmodel.Architecture) []string {
+	var units []string
+	for _, service := range architecture.Services {
+		for _, unit := range service.Units {
+			summary := fmt.Sprintf("%s/%s %.2f ->", service.Identifier, unit.Identifier, unit.ThroughputRatio)
+			for _, successor := range unit.SuccessorRefs {
+				mode := "async"
+				if successor.Sync {
+					mode = "sync"
+				}
+				summary += fmt.Sprintf(" %s/%s %s", successor.Service, successor.Unit, mode)
+			}
+			units = append(units, summary)
+		}
+	}
+	sort.Strings(units)
+	return units
+}
+
+func inferFixture(t *testing.T, format string) (*executionmodel.Architecture, *Report) {
+	t.Helper()
+	spans, err := ReadTraceFiles([]string{traceFixtures[format]}, AutoFormat)
+	if err != nil {
+		t.Fatalf("%s: %v", format, err)
+	}
+	architecture, report, err := InferFromTraces(spans, Options{Name: "test", MinCallShare: 0.1, SinkProvider: "jaeger", SinkAddress: "localhost:6831"})
+	if err != nil {
+		t.Fatalf("%s: %v", format, err)
+	}
+	return architecture, report
+}
+
+func TestInferSameArchitectureFromEachFormat(t *testing.T) {
+	expected := []string{
+		"audit/consume 0.00 ->",
+		"users/getUser 0.00 ->",
+		"web/GET /home 0.75 -> audit/consume async users/getUser sync",
+		"web/POST /login 0.25 -> audit/consume async users/getUser sync",
+	}
+	var first *executionmodel.Architecture
+	for format := range traceFixtures {
+		architecture, report := inferFixture(t, format)
+		if report.Traces != 4 || report.Spans != 28 {
+			t.Errorf("%s: expected 28 spans of 4 traces, got %d of %d", format, report.Spans, report.Traces)
+		}
+		if actual := summarize(architecture); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected units\n%v\ngot\n%v", format, expected, actual)
+		}
+		if errs := executionmodel.ValidateArchitecture(architecture, executionmodel.ValidationOptions{}); len(errs) > 0 {
+			t.Errorf("%s: inferred architecture is invalid: %v", format, errs)
+		}
+		if first == nil {
+			first = architecture
+		} else if !reflect.DeepEqual(first.WorkTemplates, architecture.WorkTemplates) {
+			t.Errorf("%s: expected the same work as from the other formats", format)
+		}
+	}
+}
+
+func TestInferBreaksCycles(t *testing.T) {
+	for format := range traceFixtures {
+		_, report := inferFixture(t, format)
+		var excluded []string
+		for _, call := range report.Calls {
+			if !call.Included {
+				excluded = append(excluded, call.From+" -> "+call.To+": "+call.Reason)
+			}
+		}
+		expected := []string{"users/getUser -> web/GET /home: closes a cycle"}
+		if !reflect.DeepEqual(excluded, expected) {
+			t.Errorf("%s: expected excluded calls %v, got %v", format, expected, excluded)
+		}
+		for _, unit := range report.Units {
+			if unit.Service == "web" && unit.Unit == "GET /home" && (unit.Invocations != 7 || unit.Roots != 3) {
+				t.Errorf("%s: expected 7 invocations of web/GET /home, 3 of them roots, got %+v", format, unit)
+			}
+		}
+	}
+}
+
+func TestInferLeavesOutRareCalls(t *testing.T) {
+	spans, err := ReadTraceFiles([]string{traceFixtures["jaeger"]}, "jaeger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	//only 3 of 7 invocations of web/GET /home call its successors
+	architecture, _, err := InferFromTraces(spans, Options{Name: "test", MinCallShare: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, unit := range summarize(architecture) {
+		if unit == "web/GET /home 0.75 ->" {
+			return
+		}
+	}
+	t.Errorf("expected web/GET /home without successors, got %v", summarize(architecture))
+}
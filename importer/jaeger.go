@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+//jaegerExport is the format of the jaeger query API and of traces downloaded from the jaeger UI.
+type jaegerExport struct {
+	Data []*jaegerTrace `json:"data"`
+}
+
+type jaegerTrace struct {
+	TraceID   string                    `json:"traceID"`
+	Spans     []*jaegerSpan             `json:"spans"`
+	Processes map[string]*jaegerProcess `json:"processes"`
+}
+
+//jaegerSpan has start time and duration in microseconds.
+type jaegerSpan struct {
+	TraceID       string             `json:"traceID"`
+	SpanID        string             `json:"spanID"`
+	OperationName string             `json:"operationName"`
+	References    []*jaegerReference `json:"references"`
+	StartTime     int64              `json:"startTime"`
+	Duration      int64              `json:"duration"`
+	Tags          []*jaegerTag       `json:"tags"`
+	ProcessID     string             `json:"processID"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerTag struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+type jaegerProcess struct {
+	ServiceName string `json:"serviceName"`
+}
+
+func readJaeger(data []byte) ([]*Span, error) {
+	var spans []*Span
+	err := decodeAll(data, func(decoder *json.Decoder) error {
+		export := &jaegerExport{}
+		if err := decoder.Decode(export); err != nil {
+			return err
+		}
+		for _, trace := range export.Data {
+			for _, s := range trace.Spans {
+				spans = append(spans, s.toSpan(trace.Processes))
+			}
+		}
+		return nil
+	})
+	return spans, err
+}
+
+func (s *jaegerSpan) toSpan(processes map[string]*jaegerProcess) *Span {
+	span := &Span{
+		TraceID:   s.TraceID,
+		SpanID:    s.SpanID,
+		Operation: s.OperationName,
+		StartTime: time.Unix(0, s.StartTime*int64(time.Microsecond)),
+		Duration:  time.Duration(s.Duration) * time.Microsecond,
+	}
+	if process, ok := processes[s.ProcessID]; ok {
+		span.Service = process.ServiceName
+	}
+	for _, tag := range s.Tags {
+		if tag.Key == "span.kind" {
+			span.Kind = normalizeKind(fmt.Sprint(tag.Value))
+		}
+	}
+	//a span can have several references, the parent is the first reference to the same trace.
+	for _, ref := range s.References {
+		if ref.TraceID != s.TraceID {
+			continue
+		}
+		span.ParentID = ref.SpanID
+		span.FollowsFrom = ref.RefType == "FOLLOWS_FROM"
+		break
+	}
+	return span
+}
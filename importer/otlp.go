@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//otlpExport is the JSON encoding of an OTLP ExportTraceServiceRequest, e.g. as written by the file exporter of the OpenTelemetry collector.
+type otlpExport struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   *otlpResource    `json:"resource"`
+	ScopeSpans []*otlpScopeSpan `json:"scopeSpans"`
+	//InstrumentationLibrarySpans is the name of ScopeSpans before OTLP 0.15.
+	InstrumentationLibrarySpans []*otlpScopeSpan `json:"instrumentationLibrarySpans"`
+}
+
+type otlpResource struct {
+	Attributes []*otlpAttribute `json:"attributes"`
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpScopeSpan struct {
+	Spans []*otlpSpan `json:"spans"`
+}
+
+//otlpSpan has timestamps in nanoseconds, which are encoded as strings. The kind is encoded as number or as name of the enum value.
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId"`
+	Name              string          `json:"name"`
+	Kind              json.RawMessage `json:"kind"`
+	StartTimeUnixNano json.Number     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   json.Number     `json:"endTimeUnixNano"`
+}
+
+//otlpKinds are the values of the SpanKind enum of OTLP.
+var otlpKinds = map[string]string{
+	"1": KindInternal,
+	"2": KindServer,
+	"3": KindClient,
+	"4": KindProducer,
+	"5": KindConsumer,
+}
+
+func readOTLP(data []byte) ([]*Span, error) {
+	var spans []*Span
+	err := decodeAll(data, func(decoder *json.Decoder) error {
+		export := &otlpExport{}
+		if err := decoder.Decode(export); err != nil {
+			return err
+		}
+		for _, resourceSpans := range export.ResourceSpans {
+			service := ""
+			if resourceSpans.Resource != nil {
+				for _, attribute := range resourceSpans.Resource.Attributes {
+					if attribute.Key == "service.name" {
+						service = attribute.Value.StringValue
+					}
+				}
+			}
+			for _, scope := range append(resourceSpans.ScopeSpans, resourceSpans.InstrumentationLibrarySpans...) {
+				for _, s := range scope.Spans {
+					span, err := s.toSpan(service)
+					if err != nil {
+						return err
+					}
+					spans = append(spans, span)
+				}
+			}
+		}
+		return nil
+	})
+	return spans, err
+}
+
+func (s *otlpSpan) toSpan(service string) (*Span, error) {
+	start, err := strconv.ParseInt(string(s.StartTimeUnixNano), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("span %s has invalid start time %q", s.SpanID, s.StartTimeUnixNano)
+	}
+	end, err := strconv.ParseInt(string(s.EndTimeUnixNano), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("span %s has invalid end time %q", s.SpanID, s.EndTimeUnixNano)
+	}
+	name := strings.Trim(string(s.Kind), `"`)
+	kind, ok := otlpKinds[name]
+	if !ok {
+		kind = normalizeKind(name)
+	}
+	return &Span{
+		TraceID:   s.TraceID,
+		SpanID:    s.SpanID,
+		ParentID:  s.ParentSpanID,
+		Service:   service,
+		Operation: s.Name,
+		Kind:      kind,
+		StartTime: time.Unix(0, start),
+		Duration:  time.Duration(end - start),
+	}, nil
+}
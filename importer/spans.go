@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Span kinds, as far as they matter for inferring an architecture. Formats without kinds, or spans without a kind, use KindUnspecified.
+const (
+	KindUnspecified = ""
+	KindServer      = "server"
+	KindClient      = "client"
+	KindProducer    = "producer"
+	KindConsumer    = "consumer"
+	KindInternal    = "internal"
+)
+
+//AutoFormat detects the format of each file from its content.
+const AutoFormat = "auto"
+
+//Span is a span read from a trace file. IDs are kept as strings, since formats differ in their length and encoding.
+type Span struct {
+	TraceID   string
+	SpanID    string
+	ParentID  string
+	Service   string
+	Operation string
+	Kind      string
+	//FollowsFrom is set if the span references its parent as follows-from, i.e. the parent doesn't wait for it.
+	FollowsFrom bool
+	StartTime   time.Time
+	Duration    time.Duration
+}
+
+//End returns the time the span finished.
+func (s *Span) End() time.Time {
+	return s.StartTime.Add(s.Duration)
+}
+
+//traceReader parses all spans of a file in one format. Files may contain several JSON documents, e.g. one per line.
+type traceReader func(data []byte) ([]*Span, error)
+
+var traceReaderRegistry map[string]traceReader
+
+func init() {
+	traceReaderRegistry = map[string]traceReader{
+		"jaeger": readJaeger,
+		"zipkin": readZipkin,
+		"otlp":   readOTLP,
+	}
+}
+
+//TraceFormats returns the names of all trace formats in alphabetical order, without AutoFormat.
+func TraceFormats() []string {
+	names := make([]string, 0, len(traceReaderRegistry))
+	for name := range traceReaderRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//ReadTraceFiles reads the spans of all files in the given format, or in the format detected for each file with AutoFormat.
+func ReadTraceFiles(files []string, format string) ([]*Span, error) {
+	var spans []*Span
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileFormat := strings.ToLower(format)
+		if fileFormat == "" || fileFormat == AutoFormat {
+			if fileFormat, err = detectTraceFormat(data); err != nil {
+				return nil, fmt.Errorf("%s: %v", file, err)
+			}
+		}
+		reader, ok := traceReaderRegistry[fileFormat]
+		if !ok {
+			return nil, fmt.Errorf("unknown trace format %s, use %s or one of: %s", format, AutoFormat, strings.Join(TraceFormats(), ", "))
+		}
+		fileSpans, err := reader(data)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read %s as %s: %v", file, fileFormat, err)
+		}
+		spans = append(spans, fileSpans...)
+	}
+	return spans, nil
+}
+
+//detectTraceFormat looks at the first JSON document of a file: Zipkin files are arrays of spans, Jaeger exports have "data" and OTLP has "resourceSpans".
+func detectTraceFormat(data []byte) (string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return "zipkin", nil
+	}
+	var document map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(trimmed)).Decode(&document); err != nil {
+		return "", fmt.Errorf("couldn't detect trace format, file isn't JSON: %v", err)
+	}
+	if _, ok := document["resourceSpans"]; ok {
+		return "otlp", nil
+	}
+	if _, ok := document["data"]; ok {
+		return "jaeger", nil
+	}
+	return "", fmt.Errorf("couldn't detect trace format, expected a JSON array of zipkin spans or an object with data (jaeger) or resourceSpans (otlp)")
+}
+
+//decodeAll decodes each JSON document of data with decode, so files can contain one or several documents.
+func decodeAll(data []byte, decode func(*json.Decoder) error) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	for {
+		err := decode(decoder)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+//normalizeKind maps the span kinds of all formats, e.g. SERVER, SPAN_KIND_SERVER or 2 in OTLP, to the kinds used by the importer.
+func normalizeKind(kind string) string {
+	kind = strings.TrimPrefix(strings.ToLower(kind), "span_kind_")
+	switch kind {
+	case KindServer, KindClient, KindProducer, KindConsumer, KindInternal:
+		return kind
+	}
+	return KindUnspecified
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+//zipkinSpan is a span of the zipkin v2 API, with timestamp and duration in microseconds.
+type zipkinSpan struct {
+	TraceID       string          `json:"traceId"`
+	ID            string          `json:"id"`
+	ParentID      string          `json:"parentId"`
+	Name          string          `json:"name"`
+	Kind          string          `json:"kind"`
+	Timestamp     int64           `json:"timestamp"`
+	Duration      int64           `json:"duration"`
+	LocalEndpoint *zipkinEndpoint `json:"localEndpoint"`
+	Shared        bool            `json:"shared"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+//readZipkin reads arrays of spans, e.g. as sent to /api/v2/spans, or arrays of traces, as returned by /api/v2/traces.
+func readZipkin(data []byte) ([]*Span, error) {
+	var spans []*Span
+	err := decodeAll(data, func(decoder *json.Decoder) error {
+		var elements []json.RawMessage
+		if err := decoder.Decode(&elements); err != nil {
+			return err
+		}
+		for _, element := range elements {
+			var trace []*zipkinSpan
+			if bytes.HasPrefix(bytes.TrimSpace(element), []byte("[")) {
+				if err := json.Unmarshal(element, &trace); err != nil {
+					return err
+				}
+			} else {
+				s := &zipkinSpan{}
+				if err := json.Unmarshal(element, s); err != nil {
+					return err
+				}
+				trace = []*zipkinSpan{s}
+			}
+			for _, s := range trace {
+				spans = append(spans, s.toSpan())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	splitSharedSpans(spans)
+	return spans, nil
+}
+
+func (s *zipkinSpan) toSpan() *Span {
+	span := &Span{
+		TraceID:   s.TraceID,
+		SpanID:    s.ID,
+		ParentID:  s.ParentID,
+		Operation: s.Name,
+		Kind:      normalizeKind(s.Kind),
+		StartTime: time.Unix(0, s.Timestamp*int64(time.Microsecond)),
+		Duration:  time.Duration(s.Duration) * time.Microsecond,
+	}
+	if s.LocalEndpoint != nil {
+		span.Service = s.LocalEndpoint.ServiceName
+	}
+	return span
+}
+
+//splitSharedSpans gives server spans, which share their ID with the client span of the call, a new ID and makes them children of the client span.
+//Zipkin instrumentation shares span IDs between client and server by default.
+func splitSharedSpans(spans []*Span) {
+	type key struct{ trace, span string }
+	clients := make(map[key]bool)
+	for _, s := range spans {
+		if s.Kind == KindClient {
+			clients[key{s.TraceID, s.SpanID}] = true
+		}
+	}
+	for _, s := range spans {
+		if s.Kind == KindServer && clients[key{s.TraceID, s.SpanID}] {
+			s.ParentID = s.SpanID
+			s.SpanID += "-server"
+		}
+	}
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/dominik-/t-race/executionmodel"
@@ -10,8 +11,8 @@ import (
 //Provider is a simple abstraction to integrate provisioning for deployment of t-race components.
 type Provider interface {
 	CreateEnvironments([]string)
-	AllocateSinks([]*executionmodel.Sink)
-	AllocateServices([]*executionmodel.Service)
+	AllocateSinks([]*executionmodel.Sink) error
+	AllocateServices([]*executionmodel.Service) error
 	GetIdWorkerMap() map[string]string
 	GetIdServiceMap() map[string]string
 	GetUnitServiceMap() map[string]string
@@ -66,7 +67,10 @@ func (p *StaticProvider) CreateEnvironments(envRefs []string) {
 }
 
 //AllocateServices maps services/worker addresses from the StaticProvider to the benchmark config.
-func (p *StaticProvider) AllocateServices(svcs []*executionmodel.Service) {
+func (p *StaticProvider) AllocateServices(svcs []*executionmodel.Service) error {
+	if len(svcs) > len(p.deployment.WorkerAddresses) {
+		return fmt.Errorf("deployment contains %d workers, but %d services need to be allocated", len(p.deployment.WorkerAddresses), len(svcs))
+	}
 	p.SvcMap = make(map[string]string, len(svcs))
 	p.WorkerMap = make(map[string]string, len(svcs))
 	for i, s := range svcs {
@@ -74,14 +78,19 @@ func (p *StaticProvider) AllocateServices(svcs []*executionmodel.Service) {
 		p.SvcMap[s.Identifier] = p.deployment.WorkerAddresses[i].ServiceAddress
 		p.WorkerMap[s.Identifier] = p.deployment.WorkerAddresses[i].BenchmarkAddress
 	}
+	return nil
 }
 
 //AllocateSinks maps SUT addresses from the StaticProvider to the benchmark config.
-func (p *StaticProvider) AllocateSinks(sinks []*executionmodel.Sink) {
+func (p *StaticProvider) AllocateSinks(sinks []*executionmodel.Sink) error {
+	if len(sinks) > len(p.deployment.Sinks) {
+		return fmt.Errorf("deployment contains %d sinks, but %d sinks need to be allocated", len(p.deployment.Sinks), len(sinks))
+	}
 	p.SinkMap = make(map[string]string, len(sinks))
 	for i, s := range sinks {
 		p.SinkMap[s.Identifier] = p.deployment.Sinks[i]
 	}
+	return nil
 }
 
 func (p *StaticProvider) GetIdWorkerMap() map[string]string {
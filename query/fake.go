@@ -0,0 +1,87 @@
+package query
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//FakeServer serves a fixed set of spans through the jaeger query API. It emulates a backend which loses a share of spans and ingests spans with a delay,
+//so verification can be tested without a tracing system.
+type FakeServer struct {
+	traces map[TraceID][]*Span
+	//DropRatio is the share of spans the server never returns. Spans are dropped by their ID, so repeated queries return the same spans.
+	DropRatio float64
+	//Delay is the time after the end of a span, or the start of the server for spans which ended earlier, until the server returns it.
+	Delay   time.Duration
+	started time.Time
+}
+
+//NewFakeServer creates a server, which returns the given spans.
+func NewFakeServer(spans []*Span, dropRatio float64, delay time.Duration) *FakeServer {
+	server := &FakeServer{
+		traces:    make(map[TraceID][]*Span),
+		DropRatio: dropRatio,
+		Delay:     delay,
+		started:   time.Now(),
+	}
+	for _, s := range spans {
+		server.traces[s.TraceID] = append(server.traces[s.TraceID], s)
+	}
+	return server
+}
+
+//ServeHTTP answers GET /api/traces/{traceID}. Unknown traces and traces without visible spans are answered with 404, same as jaeger does.
+func (s *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || !strings.HasPrefix(r.URL.Path, "/api/traces/") {
+		http.NotFound(w, r)
+		return
+	}
+	traceID, err := ParseTraceID(strings.TrimPrefix(r.URL.Path, "/api/traces/"))
+	if err != nil {
+		writeJaegerError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	now := time.Now()
+	var visible []*Span
+	for _, span := range s.traces[traceID] {
+		if s.dropped(span) {
+			continue
+		}
+		ingested := span.StartTime.Add(span.Duration)
+		if ingested.Before(s.started) {
+			ingested = s.started
+		}
+		if now.Before(ingested.Add(s.Delay)) {
+			continue
+		}
+		visible = append(visible, span)
+	}
+	if len(visible) == 0 {
+		writeJaegerError(w, http.StatusNotFound, ErrTraceNotFound.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&jaegerResponse{Data: []*jaegerTrace{newJaegerTrace(traceID, visible)}})
+}
+
+func (s *FakeServer) dropped(span *Span) bool {
+	if s.DropRatio <= 0 {
+		return false
+	}
+	hash := fnv.New64a()
+	var id [8]byte
+	for i := range id {
+		id[i] = byte(span.SpanID >> (8 * i))
+	}
+	hash.Write(id[:])
+	return float64(hash.Sum64()%10000) < s.DropRatio*10000
+}
+
+func writeJaegerError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&jaegerResponse{Errors: []*jaegerError{{Code: status, Message: message}}})
+}
@@ -0,0 +1,84 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testSpans() []*Span {
+	traceID := TraceID{High: 0x1, Low: 0xabc}
+	start := time.Now().Add(-time.Minute).Truncate(time.Microsecond)
+	return []*Span{
+		{TraceID: traceID, SpanID: 1, Service: "frontend", Operation: "root", StartTime: start, Duration: 10 * time.Millisecond},
+		{TraceID: traceID, SpanID: 2, ParentID: 1, Service: "frontend", Operation: "invoke-b", StartTime: start.Add(time.Millisecond), Duration: 8 * time.Millisecond},
+		{TraceID: traceID, SpanID: 3, ParentID: 2, Service: "backend", Operation: "b", StartTime: start.Add(2 * time.Millisecond), Duration: 6 * time.Millisecond},
+	}
+}
+
+func newTestClient(t *testing.T, server *FakeServer) Client {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	client, err := NewClient("jaeger", httpServer.URL, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestFakeServerReturnsSpansThroughJaegerClient(t *testing.T) {
+	spans := testSpans()
+	client := newTestClient(t, NewFakeServer(spans, 0, 0))
+	actual, err := client.Trace(context.Background(), spans[0].TraceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(spans) {
+		t.Fatalf("expected %d spans, got %d", len(spans), len(actual))
+	}
+	byID := make(map[uint64]*Span)
+	for _, s := range actual {
+		byID[s.SpanID] = s
+	}
+	for _, expected := range spans {
+		s, ok := byID[expected.SpanID]
+		if !ok {
+			t.Fatalf("span %d is missing", expected.SpanID)
+		}
+		if s.TraceID != expected.TraceID || s.ParentID != expected.ParentID || s.Service != expected.Service || s.Operation != expected.Operation ||
+			!s.StartTime.Equal(expected.StartTime) || s.Duration != expected.Duration {
+			t.Errorf("expected span %+v, got %+v", expected, s)
+		}
+	}
+}
+
+func TestFakeServerUnknownTrace(t *testing.T) {
+	client := newTestClient(t, NewFakeServer(testSpans(), 0, 0))
+	if _, err := client.Trace(context.Background(), TraceID{Low: 0xdead}); !errors.Is(err, ErrTraceNotFound) {
+		t.Errorf("expected ErrTraceNotFound, got %v", err)
+	}
+}
+
+func TestFakeServerDropsSpans(t *testing.T) {
+	spans := testSpans()
+	client := newTestClient(t, NewFakeServer(spans, 1, 0))
+	if _, err := client.Trace(context.Background(), spans[0].TraceID); !errors.Is(err, ErrTraceNotFound) {
+		t.Errorf("expected all spans to be dropped, got %v", err)
+	}
+}
+
+func TestFakeServerDelaysSpans(t *testing.T) {
+	spans := testSpans()
+	delay := 200 * time.Millisecond
+	client := newTestClient(t, NewFakeServer(spans, 0, delay))
+	if _, err := client.Trace(context.Background(), spans[0].TraceID); !errors.Is(err, ErrTraceNotFound) {
+		t.Fatalf("expected trace to be invisible before the delay, got %v", err)
+	}
+	time.Sleep(delay)
+	if actual, err := client.Trace(context.Background(), spans[0].TraceID); err != nil || len(actual) != len(spans) {
+		t.Errorf("expected %d spans after the delay, got %d and error %v", len(spans), len(actual), err)
+	}
+}
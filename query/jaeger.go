@@ -0,0 +1,168 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//jaegerClient uses the HTTP API of jaeger-query, which is also used by the jaeger UI.
+type jaegerClient struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+func newJaegerClient(endpoint string, httpClient *http.Client) Client {
+	return &jaegerClient{
+		endpoint:   endpoint,
+		httpClient: httpClient,
+	}
+}
+
+//jaegerResponse is the envelope of all responses of the jaeger query API.
+type jaegerResponse struct {
+	Data   []*jaegerTrace `json:"data"`
+	Errors []*jaegerError `json:"errors,omitempty"`
+}
+
+type jaegerError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+type jaegerTrace struct {
+	TraceID   string                    `json:"traceID"`
+	Spans     []*jaegerSpan             `json:"spans"`
+	Processes map[string]*jaegerProcess `json:"processes"`
+}
+
+//jaegerSpan has start time and duration in microseconds.
+type jaegerSpan struct {
+	TraceID       string             `json:"traceID"`
+	SpanID        string             `json:"spanID"`
+	OperationName string             `json:"operationName"`
+	References    []*jaegerReference `json:"references"`
+	StartTime     int64              `json:"startTime"`
+	Duration      int64              `json:"duration"`
+	ProcessID     string             `json:"processID"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerProcess struct {
+	ServiceName string `json:"serviceName"`
+}
+
+func (c *jaegerClient) Trace(ctx context.Context, traceID TraceID) ([]*Span, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/api/traces/"+traceID.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrTraceNotFound
+	}
+	body := &jaegerResponse{}
+	if err := json.NewDecoder(response.Body).Decode(body); err != nil {
+		return nil, fmt.Errorf("couldn't decode response of jaeger query (status %s): %v", response.Status, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		if len(body.Errors) > 0 {
+			return nil, fmt.Errorf("jaeger query returned %s: %s", response.Status, body.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("jaeger query returned %s", response.Status)
+	}
+	if len(body.Data) == 0 {
+		return nil, ErrTraceNotFound
+	}
+	var spans []*Span
+	for _, trace := range body.Data {
+		for _, s := range trace.Spans {
+			span, err := s.toSpan(trace.Processes)
+			if err != nil {
+				return nil, err
+			}
+			spans = append(spans, span)
+		}
+	}
+	return spans, nil
+}
+
+func (s *jaegerSpan) toSpan(processes map[string]*jaegerProcess) (*Span, error) {
+	traceID, err := ParseTraceID(s.TraceID)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := strconv.ParseUint(s.SpanID, 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid span ID %q: %v", s.SpanID, err)
+	}
+	span := &Span{
+		TraceID:   traceID,
+		SpanID:    spanID,
+		Operation: s.OperationName,
+		StartTime: time.Unix(0, s.StartTime*int64(time.Microsecond)),
+		Duration:  time.Duration(s.Duration) * time.Microsecond,
+	}
+	if process, ok := processes[s.ProcessID]; ok {
+		span.Service = process.ServiceName
+	}
+	//a span can have several references, the parent is the first reference to the same trace.
+	for _, ref := range s.References {
+		if ref.TraceID != s.TraceID {
+			continue
+		}
+		if span.ParentID, err = strconv.ParseUint(ref.SpanID, 16, 64); err != nil {
+			return nil, fmt.Errorf("invalid parent span ID %q: %v", ref.SpanID, err)
+		}
+		break
+	}
+	return span, nil
+}
+
+//newJaegerTrace converts spans of a single trace to the format of the jaeger query API, with one process per service.
+func newJaegerTrace(traceID TraceID, spans []*Span) *jaegerTrace {
+	trace := &jaegerTrace{
+		TraceID:   traceID.String(),
+		Spans:     make([]*jaegerSpan, len(spans)),
+		Processes: make(map[string]*jaegerProcess),
+	}
+	processIDs := make(map[string]string)
+	for i, s := range spans {
+		processID, ok := processIDs[s.Service]
+		if !ok {
+			processID = fmt.Sprintf("p%d", len(processIDs)+1)
+			processIDs[s.Service] = processID
+			trace.Processes[processID] = &jaegerProcess{ServiceName: s.Service}
+		}
+		span := &jaegerSpan{
+			TraceID:       trace.TraceID,
+			SpanID:        fmt.Sprintf("%016x", s.SpanID),
+			OperationName: s.Operation,
+			References:    []*jaegerReference{},
+			StartTime:     s.StartTime.UnixNano() / int64(time.Microsecond),
+			Duration:      int64(s.Duration / time.Microsecond),
+			ProcessID:     processID,
+		}
+		if s.ParentID != 0 {
+			span.References = append(span.References, &jaegerReference{
+				RefType: "CHILD_OF",
+				TraceID: trace.TraceID,
+				SpanID:  fmt.Sprintf("%016x", s.ParentID),
+			})
+		}
+		trace.Spans[i] = span
+	}
+	return trace
+}
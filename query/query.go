@@ -0,0 +1,101 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ErrTraceNotFound is returned by clients if the backend doesn't know a trace, e.g. because it wasn't ingested (yet).
+var ErrTraceNotFound = errors.New("trace not found")
+
+//DefaultBackend is used if no query backend is configured.
+const DefaultBackend = "jaeger"
+
+//Span is a span as returned by the query API of a tracing backend.
+type Span struct {
+	TraceID   TraceID
+	SpanID    uint64
+	ParentID  uint64
+	Service   string
+	Operation string
+	StartTime time.Time
+	Duration  time.Duration
+}
+
+//Client queries the traces stored by a tracing backend, i.e. the SUT.
+type Client interface {
+	//Trace returns all spans of a trace, or ErrTraceNotFound if the backend doesn't know the trace.
+	Trace(ctx context.Context, traceID TraceID) ([]*Span, error)
+}
+
+type clientFactory func(endpoint string, httpClient *http.Client) Client
+
+var clientRegistry map[string]clientFactory
+
+func init() {
+	clientRegistry = map[string]clientFactory{
+		"jaeger": newJaegerClient,
+	}
+}
+
+//NewClient creates a client for the query API of the given backend at endpoint, e.g. http://localhost:16686 for jaeger.
+func NewClient(backend, endpoint string, timeout time.Duration) (Client, error) {
+	if backend == "" {
+		backend = DefaultBackend
+	}
+	factory, ok := clientRegistry[strings.ToLower(backend)]
+	if !ok {
+		return nil, fmt.Errorf("unknown query backend %s, available backends are: %s", backend, strings.Join(Backends(), ", "))
+	}
+	return factory(strings.TrimSuffix(endpoint, "/"), &http.Client{Timeout: timeout}), nil
+}
+
+//Backends returns the names of all query backends in alphabetical order.
+func Backends() []string {
+	names := make([]string, 0, len(clientRegistry))
+	for name := range clientRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//TraceID is a trace ID of up to 128 bit. 64 bit IDs only use Low.
+type TraceID struct {
+	High uint64
+	Low  uint64
+}
+
+//String returns the ID in hex, with 16 digits for 64 bit IDs and 32 digits otherwise.
+func (t TraceID) String() string {
+	if t.High == 0 {
+		return fmt.Sprintf("%016x", t.Low)
+	}
+	return fmt.Sprintf("%016x%016x", t.High, t.Low)
+}
+
+//ParseTraceID parses a trace ID in hex, with or without leading 0x, as written to the results of a run.
+func ParseTraceID(s string) (TraceID, error) {
+	hex := strings.TrimPrefix(strings.ToLower(s), "0x")
+	if len(hex) == 0 || len(hex) > 32 {
+		return TraceID{}, fmt.Errorf("invalid trace ID %q", s)
+	}
+	var id TraceID
+	var err error
+	if len(hex) > 16 {
+		if id.High, err = strconv.ParseUint(hex[:len(hex)-16], 16, 64); err != nil {
+			return TraceID{}, fmt.Errorf("invalid trace ID %q: %v", s, err)
+		}
+		hex = hex[len(hex)-16:]
+	}
+	if id.Low, err = strconv.ParseUint(hex, 16, 64); err != nil {
+		return TraceID{}, fmt.Errorf("invalid trace ID %q: %v", s, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,27 @@
+//Package version provides the version of t-race, which is reported by workers and recorded in run manifests.
+package version
+
+import (
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
+
+//Version is set at build time, e.g. with -ldflags "-X github.com/dominik-/t-race/version.Version=v0.9.2".
+var Version = "v0.9.1"
+
+//pseudoVersion matches the timestamp and commit of versions the go tool derives from untagged commits.
+var pseudoVersion = regexp.MustCompile(`\d{14}-[0-9a-f]{12}`)
+
+//String returns Version, followed by the module version of the build if it was built from a tagged release with another version.
+func String() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Version
+	}
+	module := info.Main.Version
+	if module == "" || module == "(devel)" || module == Version || strings.Contains(module, "+dirty") || pseudoVersion.MatchString(module) {
+		return Version
+	}
+	return Version + " (" + module + ")"
+}
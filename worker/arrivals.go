@@ -0,0 +1,239 @@
+package worker
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+)
+
+//DefaultArrivalType is used for root units which don't configure an arrival process. It starts traces in fixed intervals.
+const DefaultArrivalType = "constant"
+
+//ArrivalProcess decides when traces arrive at a root unit. All processes have a long-run mean rate of the rate they were created with, i.e. the throughput of the unit.
+type ArrivalProcess interface {
+	//NextArrival returns the time of the next arrival after the previous one. Both are offsets from the start of load generation.
+	NextArrival(previous time.Duration) time.Duration
+}
+
+//arrivalProcessFactory creates an arrival process with the given mean rate in arrivals per second. Each unit gets its own process, since processes have state.
+type arrivalProcessFactory func(rate float64, params map[string]float64, rng *rand.Rand) (ArrivalProcess, error)
+
+var arrivalProcessRegistry map[string]arrivalProcessFactory
+
+func init() {
+	arrivalProcessRegistry = map[string]arrivalProcessFactory{
+		"constant": newConstantArrivals,
+		"poisson":  newPoissonArrivals,
+		"onoff":    newOnOffArrivals,
+		"mmpp":     newMMPPArrivals,
+		"diurnal":  newDiurnalArrivals,
+	}
+}
+
+//ArrivalTypes returns the names of all registered arrival processes, sorted by name.
+func ArrivalTypes() []string {
+	names := make([]string, 0, len(arrivalProcessRegistry))
+	for name := range arrivalProcessRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//NewArrivalProcess creates the arrival process configured for a root unit with the given mean rate and seed. A nil arrival selects the DefaultArrivalType.
+func NewArrivalProcess(arrival *api.Arrival, rate float64, seed int64) (ArrivalProcess, error) {
+	arrivalType := DefaultArrivalType
+	var params map[string]float64
+	if arrival != nil {
+		if arrival.Type != "" {
+			arrivalType = arrival.Type
+		}
+		params = arrival.Parameters
+	}
+	factory, exists := arrivalProcessRegistry[strings.ToLower(arrivalType)]
+	if !exists {
+		return nil, fmt.Errorf("unknown arrival process %s, known processes are: %s", arrivalType, strings.Join(ArrivalTypes(), ", "))
+	}
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return nil, fmt.Errorf("rate of arrival process %s must be positive, got %f", arrivalType, rate)
+	}
+	return factory(rate, params, rand.New(rand.NewSource(seed)))
+}
+
+//param returns a parameter, or the default value if it isn't set.
+func param(params map[string]float64, name string, defaultValue float64) float64 {
+	if value, ok := params[name]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+//constantArrivals are periodic, i.e. traces arrive in fixed intervals of 1/rate. The nth arrival is computed from n instead of the previous arrival,
+//so rounding errors don't add up at high rates. The phase shifts all arrivals, e.g. to interleave several processes.
+type constantArrivals struct {
+	interval float64
+	phase    float64
+	n        int64
+}
+
+func newConstantArrivals(rate float64, params map[string]float64, rng *rand.Rand) (ArrivalProcess, error) {
+	return &constantArrivals{interval: 1 / rate}, nil
+}
+
+func (a *constantArrivals) NextArrival(previous time.Duration) time.Duration {
+	a.n++
+	return seconds(a.phase + float64(a.n)*a.interval)
+}
+
+//poissonArrivals have exponentially distributed times between arrivals with a mean of 1/rate.
+type poissonArrivals struct {
+	rate float64
+	rng  *rand.Rand
+}
+
+func newPoissonArrivals(rate float64, params map[string]float64, rng *rand.Rand) (ArrivalProcess, error) {
+	return &poissonArrivals{rate: rate, rng: rng}, nil
+}
+
+func (a *poissonArrivals) NextArrival(previous time.Duration) time.Duration {
+	return previous + seconds(a.rng.ExpFloat64()/a.rate)
+}
+
+//onOffArrivals alternate between bursts of "on" seconds, in which traces arrive as a Poisson process, and pauses of "off" seconds without arrivals.
+//The rate during bursts is rate*(on+off)/on. If "poisson" is 0, traces arrive in fixed intervals during bursts.
+type onOffArrivals struct {
+	on       time.Duration
+	off      time.Duration
+	burst    float64
+	poisson  bool
+	onTime   time.Duration
+	rng      *rand.Rand
+	interval time.Duration
+}
+
+func newOnOffArrivals(rate float64, params map[string]float64, rng *rand.Rand) (ArrivalProcess, error) {
+	on := param(params, "on", 1)
+	off := param(params, "off", 1)
+	if on <= 0 || off < 0 {
+		return nil, fmt.Errorf("onoff arrivals need on > 0 and off >= 0 seconds, got on %f and off %f", on, off)
+	}
+	burst := rate * (on + off) / on
+	return &onOffArrivals{
+		on:       seconds(on),
+		off:      seconds(off),
+		burst:    burst,
+		poisson:  param(params, "poisson", 1) != 0,
+		rng:      rng,
+		interval: calculateIntervalForThroughput(burst),
+	}, nil
+}
+
+//NextArrival advances the time spent in bursts and maps it to the time since the start, by inserting a pause after each burst.
+func (a *onOffArrivals) NextArrival(previous time.Duration) time.Duration {
+	if a.poisson {
+		a.onTime += seconds(a.rng.ExpFloat64() / a.burst)
+	} else {
+		a.onTime += a.interval
+	}
+	bursts := a.onTime / a.on
+	return bursts*(a.on+a.off) + a.onTime%a.on
+}
+
+//mmppArrivals are a Markov-modulated Poisson process with a low and a high state. The rate in the high state is "factor" times the rate in the low state,
+//the times spent in each state are exponentially distributed with means of "high" and "low" seconds.
+type mmppArrivals struct {
+	rates     [2]float64
+	durations [2]float64
+	state     int
+	stateEnd  time.Duration
+	rng       *rand.Rand
+}
+
+const (
+	mmppLow  = 0
+	mmppHigh = 1
+)
+
+func newMMPPArrivals(rate float64, params map[string]float64, rng *rand.Rand) (ArrivalProcess, error) {
+	factor := param(params, "factor", 5)
+	high := param(params, "high", 1)
+	low := param(params, "low", 4)
+	if factor < 1 || high <= 0 || low <= 0 {
+		return nil, fmt.Errorf("mmpp arrivals need factor >= 1 and high, low > 0 seconds, got factor %f, high %f and low %f", factor, high, low)
+	}
+	//the mean rate is weighted by the share of time spent in each state
+	lowRate := rate * (high + low) / (factor*high + low)
+	a := &mmppArrivals{
+		rates:     [2]float64{lowRate, factor * lowRate},
+		durations: [2]float64{low, high},
+		rng:       rng,
+	}
+	//the initial state is drawn from the stationary distribution, so the process doesn't always start with a pause or a burst
+	if rng.Float64() < high/(high+low) {
+		a.state = mmppHigh
+	}
+	a.stateEnd = seconds(rng.ExpFloat64() * a.durations[a.state])
+	return a, nil
+}
+
+//NextArrival draws the next arrival in the current state. If the state ends before, the process switches state and draws again, which is correct since arrivals are memoryless.
+func (a *mmppArrivals) NextArrival(previous time.Duration) time.Duration {
+	t := previous
+	for {
+		next := t + seconds(a.rng.ExpFloat64()/a.rates[a.state])
+		if next < a.stateEnd {
+			return next
+		}
+		t = a.stateEnd
+		a.state = 1 - a.state
+		a.stateEnd = t + seconds(a.rng.ExpFloat64()*a.durations[a.state])
+	}
+}
+
+//diurnalArrivals are a Poisson process with a sinusoidal rate: rate*(1 + amplitude*sin(2*pi*(t/period + phase))). The period is in seconds, the phase a fraction of the period.
+type diurnalArrivals struct {
+	rate      float64
+	amplitude float64
+	period    float64
+	phase     float64
+	rng       *rand.Rand
+}
+
+func newDiurnalArrivals(rate float64, params map[string]float64, rng *rand.Rand) (ArrivalProcess, error) {
+	a := &diurnalArrivals{
+		rate:      rate,
+		amplitude: param(params, "amplitude", 0.5),
+		period:    param(params, "period", 60),
+		phase:     param(params, "phase", 0),
+		rng:       rng,
+	}
+	if a.amplitude < 0 || a.amplitude > 1 || a.period <= 0 {
+		return nil, fmt.Errorf("diurnal arrivals need an amplitude between 0 and 1 and a positive period, got amplitude %f and period %f", a.amplitude, a.period)
+	}
+	return a, nil
+}
+
+func (a *diurnalArrivals) rateAt(t time.Duration) float64 {
+	return a.rate * (1 + a.amplitude*math.Sin(2*math.Pi*(t.Seconds()/a.period+a.phase)))
+}
+
+//NextArrival uses thinning: candidates are drawn with the maximum rate and accepted with the ratio of the current to the maximum rate.
+func (a *diurnalArrivals) NextArrival(previous time.Duration) time.Duration {
+	maxRate := a.rate * (1 + a.amplitude)
+	t := previous
+	for {
+		t += seconds(a.rng.ExpFloat64() / maxRate)
+		if a.rng.Float64()*maxRate <= a.rateAt(t) {
+			return t
+		}
+	}
+}
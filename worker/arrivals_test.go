@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/opentracing/opentracing-go"
+)
+
+//arrivals returns the first n arrivals of a process with the given rate and seed.
+func arrivals(t *testing.T, arrival *api.Arrival, rate float64, seed int64, n int) []time.Duration {
+	t.Helper()
+	process, err := NewArrivalProcess(arrival, rate, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	times := make([]time.Duration, n)
+	var previous time.Duration
+	for i := range times {
+		previous = process.NextArrival(previous)
+		if previous < 0 || i > 0 && previous < times[i-1] {
+			t.Fatalf("arrival %d at %v is before the previous arrival", i, previous)
+		}
+		times[i] = previous
+	}
+	return times
+}
+
+func TestArrivalProcessesKeepMeanRate(t *testing.T) {
+	const rate = 100
+	for _, arrival := range []*api.Arrival{
+		nil,
+		{Type: "poisson"},
+		{Type: "onoff", Parameters: map[string]float64{"on": 1, "off": 3}},
+		{Type: "onoff", Parameters: map[string]float64{"on": 0.5, "off": 0.5, "poisson": 0}},
+		{Type: "mmpp", Parameters: map[string]float64{"factor": 10, "high": 0.5, "low": 2}},
+		{Type: "diurnal", Parameters: map[string]float64{"amplitude": 0.8, "period": 10, "phase": 0.25}},
+	} {
+		times := arrivals(t, arrival, rate, 1, 1000000)
+		actual := float64(len(times)) / times[len(times)-1].Seconds()
+		if math.Abs(actual-rate)/rate > 0.02 {
+			t.Errorf("%+v: expected a mean rate of %d, got %.2f", arrival, rate, actual)
+		}
+	}
+}
+
+func TestConstantArrivalsAreEvenlySpaced(t *testing.T) {
+	for i, arrival := range arrivals(t, &api.Arrival{Type: "Constant"}, 1000, 1, 5000) {
+		//arrivals are computed from their index, so only rounding errors of single arrivals are allowed
+		if expected := time.Duration(i+1) * time.Millisecond; arrival < expected-time.Nanosecond || arrival > expected+time.Nanosecond {
+			t.Fatalf("expected arrival %d at %v, got %v", i, expected, arrival)
+		}
+	}
+}
+
+func TestOnOffArrivalsPause(t *testing.T) {
+	for _, arrival := range arrivals(t, &api.Arrival{Type: "onoff", Parameters: map[string]float64{"on": 1, "off": 3}}, 100, 1, 10000) {
+		if arrival%(4*time.Second) >= time.Second {
+			t.Fatalf("expected arrivals only in the first second of each 4 seconds, got %v", arrival)
+		}
+	}
+}
+
+func TestDiurnalArrivalsFollowRate(t *testing.T) {
+	//with a phase of 0, the rate is above the mean in the first half of each period and below it in the second half
+	counts := make([]int, 2)
+	for _, arrival := range arrivals(t, &api.Arrival{Type: "diurnal", Parameters: map[string]float64{"amplitude": 0.5, "period": 10}}, 100, 1, 100000) {
+		counts[int(arrival.Seconds())%10/5]++
+	}
+	//the integral of 1 + 0.5*sin over the first half period is 1 + 1/pi times the mean, over the second half 1 - 1/pi
+	ratio := float64(counts[0]) / float64(counts[1])
+	if expected := (1 + 1/math.Pi) / (1 - 1/math.Pi); math.Abs(ratio-expected)/expected > 0.05 {
+		t.Errorf("expected %.2f times as many arrivals in the first as in the second half period, got %.2f", expected, ratio)
+	}
+}
+
+func TestNewArrivalProcessRejectsInvalidParameters(t *testing.T) {
+	for _, test := range []struct {
+		arrival *api.Arrival
+		rate    float64
+	}{
+		{&api.Arrival{Type: "bursty"}, 10},
+		{nil, 0},
+		{&api.Arrival{Type: "poisson"}, math.Inf(1)},
+		{&api.Arrival{Type: "onoff", Parameters: map[string]float64{"on": 0}}, 10},
+		{&api.Arrival{Type: "onoff", Parameters: map[string]float64{"off": -1}}, 10},
+		{&api.Arrival{Type: "mmpp", Parameters: map[string]float64{"factor": 0.5}}, 10},
+		{&api.Arrival{Type: "mmpp", Parameters: map[string]float64{"high": 0}}, 10},
+		{&api.Arrival{Type: "diurnal", Parameters: map[string]float64{"amplitude": 1.5}}, 10},
+		{&api.Arrival{Type: "diurnal", Parameters: map[string]float64{"period": 0}}, 10},
+	} {
+		if _, err := NewArrivalProcess(test.arrival, test.rate, 1); err == nil {
+			t.Errorf("expected an error for %+v with rate %f", test.arrival, test.rate)
+		}
+	}
+}
+
+func TestGeneratorWithoutThroughputStartsOneTracePerSecond(t *testing.T) {
+	config := &api.Unit{Identifier: "root", ThroughputRatio: 1}
+	unit, err := CreateUnitExecutorFromConfig(config, seededWorker(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	generator, err := NewOpenTracingUnitSpanGenerator(unit, config, nil, "frontend", opentracing.NoopTracer{}, 0, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if throughput := generator.(*OpenTracingUnitSpanGenerator).EffectiveThroughput; throughput != 1 {
+		t.Errorf("expected a throughput of 1 trace per second, got %f", throughput)
+	}
+}
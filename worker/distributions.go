@@ -1,9 +1,12 @@
 package worker
 
 import (
-	"errors"
+	"fmt"
+	"math"
 	"math/rand"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dominik-/t-race/api"
@@ -11,16 +14,27 @@ import (
 
 var src = rand.NewSource(time.Now().UnixNano())
 
+//Work types, which don't only have parameters.
+const (
+	//MixtureDistribution is the work type of weighted mixtures of other work templates.
+	MixtureDistribution = "mixture"
+	//EmpiricalDistribution is the work type of observed work times, which are sent to workers as CDF.
+	EmpiricalDistribution = "empirical"
+)
+
 func init() {
-	distributionRegistry = make(map[string]DistributionSampler)
-	distributionRegistry["constant"] = &StaticDistribution{}
-	distributionRegistry["gaussian"] = &GaussianDistribution{
-		randomizer: rand.New(src),
+	distributionRegistry = map[string]distributionFactory{
+		"constant":            func() DistributionSampler { return &StaticDistribution{} },
+		"gaussian":            func() DistributionSampler { return &GaussianDistribution{randomizer: newRandomizer()} },
+		"exponential":         func() DistributionSampler { return &ExpDistribution{randomizer: newRandomizer()} },
+		"truncnormal":         func() DistributionSampler { return &TruncatedNormalDistribution{randomizer: newRandomizer()} },
+		"lognormal":           func() DistributionSampler { return &LogNormalDistribution{randomizer: newRandomizer()} },
+		"pareto":              func() DistributionSampler { return &ParetoDistribution{randomizer: newRandomizer()} },
+		"weibull":             func() DistributionSampler { return &WeibullDistribution{randomizer: newRandomizer()} },
+		"uniform":             func() DistributionSampler { return &UniformDistribution{randomizer: newRandomizer()} },
+		MixtureDistribution:   func() DistributionSampler { return &WeightedMixture{randomizer: newRandomizer()} },
+		EmpiricalDistribution: func() DistributionSampler { return &EmpiricalSampler{randomizer: newRandomizer()} },
 	}
-	distributionRegistry["exponential"] = &ExpDistribution{
-		randomizer: rand.New(src),
-	}
-	//rng := rand.New(rand.NewSource(time.Now().UnixNano()))
 }
 
 //Alphabet for random key/value generation from https://stackoverflow.com/questions/22892120/how-to-generate-a-random-string-of-a-fixed-length-in-go
@@ -33,6 +47,11 @@ const (
 
 //RandStringWithLength implements random string generation; based on https://stackoverflow.com/questions/22892120/how-to-generate-a-random-string-of-a-fixed-length-in-go
 func RandStringWithLength(n int64) string {
+	return randString(src, n)
+}
+
+//randString generates a random string with letters from the given source, so strings can be repeated with the same seed.
+func randString(src rand.Source, n int64) string {
 	b := make([]byte, n)
 	// A src.Int63() generates 63 random bits, enough for letterIdxMax characters!
 	for i, cache, remain := n-1, src.Int63(), letterIdxMax; i >= 0; {
@@ -50,7 +69,48 @@ func RandStringWithLength(n int64) string {
 	return string(b)
 }
 
-var distributionRegistry map[string]DistributionSampler
+//distributionFactory creates a sampler without parameters. Each work template gets its own sampler, so units with the same distribution don't share parameters.
+type distributionFactory func() DistributionSampler
+
+var distributionRegistry map[string]distributionFactory
+
+//srcLock guards src, since workers of a local run look up distributions concurrently.
+var srcLock sync.Mutex
+
+//newRandomizer returns a generator, which can be used by concurrent invocations of a unit.
+func newRandomizer() *rand.Rand {
+	srcLock.Lock()
+	defer srcLock.Unlock()
+	return newSeededRandomizer(src.Int63())
+}
+
+func newSeededRandomizer(seed int64) *rand.Rand {
+	return rand.New(&lockedSource{src: rand.NewSource(seed).(rand.Source64)})
+}
+
+//lockedSource is a rand.Source, which is safe for concurrent use. The sources of package rand are not.
+type lockedSource struct {
+	lock sync.Mutex
+	src  rand.Source64
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.src.Uint64()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.src.Seed(seed)
+}
 
 type DistributionIndex struct {
 	Name       string
@@ -63,8 +123,8 @@ type DistributionIndex struct {
 type NoDistribution struct {
 }
 
-func (nd *NoDistribution) SetParameters(values map[string]float64) {
-	//do nothing
+func (nd *NoDistribution) SetParameters(values map[string]float64) error {
+	return nil
 }
 
 func (nd *NoDistribution) GetNextValue() time.Duration {
@@ -79,8 +139,15 @@ type StaticDistribution struct {
 	Value int64
 }
 
-func (sd *StaticDistribution) SetParameters(values map[string]float64) {
+func (sd *StaticDistribution) SetParameters(values map[string]float64) error {
+	if err := checkParameters("constant", values, []string{"value"}); err != nil {
+		return err
+	}
+	if values["value"] < 0 {
+		return fmt.Errorf("constant distribution needs a value >= 0, got %f", values["value"])
+	}
 	sd.Value = int64(values["value"])
+	return nil
 }
 
 func (sd *StaticDistribution) GetNextValue() time.Duration {
@@ -92,12 +159,24 @@ func (sd *StaticDistribution) SetRNGSeed(seed int64) {
 }
 
 // DistributionSampler describes a statistic distribution and allows setting parameters (based on YAML configs) and a seed for that distribution.
+//All values are in microseconds. SetParameters returns an error if parameters are missing, unknown or out of range.
 type DistributionSampler interface {
-	SetParameters(map[string]float64)
+	SetParameters(map[string]float64) error
 	GetNextValue() time.Duration
 	SetRNGSeed(int64)
 }
 
+//DistributionTypes returns the names of all registered distributions, including "none", sorted by name.
+func DistributionTypes() []string {
+	names := []string{"none"}
+	for name := range distributionRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//LookupDistribution creates a new sampler for the work and sets its parameters. Components of mixtures are looked up recursively.
 func LookupDistribution(work *api.Work) (DistributionSampler, error) {
 	if work == nil {
 		return &NoDistribution{}, nil
@@ -105,15 +184,74 @@ func LookupDistribution(work *api.Work) (DistributionSampler, error) {
 	if strings.Compare("none", strings.ToLower(work.DistType)) == 0 {
 		return &NoDistribution{}, nil
 	}
-	dist, exists := distributionRegistry[work.DistType]
+	factory, exists := distributionRegistry[work.DistType]
 	if !exists {
-		return nil, errors.New("Unknown distribution")
+		return nil, fmt.Errorf("unknown distribution %s, known distributions are: %s", work.DistType, strings.Join(DistributionTypes(), ", "))
+	}
+	dist := factory()
+	if mixture, ok := dist.(*WeightedMixture); ok {
+		if err := mixture.setComponents(work.Components); err != nil {
+			return nil, err
+		}
+	} else if len(work.Components) > 0 {
+		return nil, fmt.Errorf("%s distribution doesn't have components, only %s does", work.DistType, MixtureDistribution)
+	}
+	if empirical, ok := dist.(*EmpiricalSampler); ok {
+		if err := empirical.setCDF(work.Empirical); err != nil {
+			return nil, err
+		}
+	} else if work.Empirical != nil {
+		return nil, fmt.Errorf("%s distribution doesn't read a file, only %s does", work.DistType, EmpiricalDistribution)
+	}
+	if err := dist.SetParameters(work.Parameters); err != nil {
+		return nil, err
 	}
-	dist.SetParameters(work.Parameters)
-	//TODO check if params are valid for distribution
 	return dist, nil
 }
 
+//ValidateDistribution returns the error LookupDistribution would return for the work, e.g. because of invalid parameters.
+func ValidateDistribution(work *api.Work) error {
+	_, err := LookupDistribution(work)
+	return err
+}
+
+//checkParameters returns an error if a required parameter is missing, or a parameter is neither required nor optional.
+func checkParameters(distribution string, values map[string]float64, required []string, optional ...string) error {
+	for _, name := range required {
+		if _, exists := values[name]; !exists {
+			return fmt.Errorf("%s distribution needs the parameters %s, %s is missing", distribution, strings.Join(required, ", "), name)
+		}
+	}
+	for name := range values {
+		if !containsName(required, name) && !containsName(optional, name) {
+			known := append(append([]string{}, required...), optional...)
+			return fmt.Errorf("%s distribution has unknown parameter %s, known parameters are: %s", distribution, name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+//microseconds converts a sample to a duration. Negative samples are returned as 0, samples beyond the range of durations as the maximum duration.
+func microseconds(value float64) time.Duration {
+	if value <= 0 || math.IsNaN(value) {
+		return 0
+	}
+	if value >= float64(math.MaxInt64/int64(time.Microsecond)) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(value * float64(time.Microsecond))
+}
+
+//GaussianDistribution is a normal distribution. Negative samples are returned as 0, use TruncatedNormalDistribution to sample from a range instead.
 type GaussianDistribution struct {
 	randomizer *rand.Rand
 	mean       float64 `yaml:"mean"`
@@ -121,17 +259,23 @@ type GaussianDistribution struct {
 }
 
 func (gd *GaussianDistribution) GetNextValue() time.Duration {
-	return time.Duration(gd.randomizer.NormFloat64()*gd.stdDev+gd.mean) * time.Microsecond
+	return microseconds(gd.randomizer.NormFloat64()*gd.stdDev + gd.mean)
 }
 
-func (gd *GaussianDistribution) SetParameters(values map[string]float64) {
-	//TODO should probably add some parsing/validation
+func (gd *GaussianDistribution) SetParameters(values map[string]float64) error {
+	if err := checkParameters("gaussian", values, []string{"mean", "stddev"}); err != nil {
+		return err
+	}
+	if values["stddev"] < 0 {
+		return fmt.Errorf("gaussian distribution needs a stddev >= 0, got %f", values["stddev"])
+	}
 	gd.mean = values["mean"]
 	gd.stdDev = values["stddev"]
+	return nil
 }
 
 func (gd *GaussianDistribution) SetRNGSeed(seed int64) {
-	gd.randomizer = rand.New(rand.NewSource(seed))
+	gd.randomizer = newSeededRandomizer(seed)
 }
 
 type ExpDistribution struct {
@@ -141,14 +285,308 @@ type ExpDistribution struct {
 }
 
 func (ed *ExpDistribution) SetRNGSeed(seed int64) {
-	ed.randomizer = rand.New(rand.NewSource(seed))
+	ed.randomizer = newSeededRandomizer(seed)
 }
 
-func (ed *ExpDistribution) SetParameters(values map[string]float64) {
+func (ed *ExpDistribution) SetParameters(values map[string]float64) error {
+	if err := checkParameters("exponential", values, []string{"mean"}); err != nil {
+		return err
+	}
+	if values["mean"] <= 0 {
+		return fmt.Errorf("exponential distribution needs a mean > 0, got %f", values["mean"])
+	}
 	ed.mean = values["mean"]
 	ed.lambda = 1 / ed.mean
+	return nil
 }
 
 func (ed *ExpDistribution) GetNextValue() time.Duration {
-	return time.Duration(ed.randomizer.ExpFloat64()/ed.lambda) * time.Microsecond
+	return microseconds(ed.randomizer.ExpFloat64() / ed.lambda)
+}
+
+//TruncatedNormalDistribution is a normal distribution restricted to the range from min (default 0) to max (default unbounded).
+//Values are sampled by inverting the CDF, so ranges far from the mean don't need many attempts.
+type TruncatedNormalDistribution struct {
+	randomizer *rand.Rand
+	mean       float64
+	stdDev     float64
+	min        float64
+	max        float64
+	//lower and upper are the values of the standard normal CDF at min and max.
+	lower float64
+	upper float64
+}
+
+func normalCDF(z float64) float64 {
+	return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+}
+
+func (td *TruncatedNormalDistribution) SetParameters(values map[string]float64) error {
+	if err := checkParameters("truncnormal", values, []string{"mean", "stddev"}, "min", "max"); err != nil {
+		return err
+	}
+	td.mean = values["mean"]
+	td.stdDev = values["stddev"]
+	td.min = param(values, "min", 0)
+	td.max = param(values, "max", math.Inf(1))
+	if td.stdDev <= 0 || td.min < 0 || td.max <= td.min {
+		return fmt.Errorf("truncnormal distribution needs a stddev > 0 and 0 <= min < max, got stddev %f, min %f and max %f", td.stdDev, td.min, td.max)
+	}
+	td.lower = normalCDF((td.min - td.mean) / td.stdDev)
+	td.upper = normalCDF((td.max - td.mean) / td.stdDev)
+	if td.upper-td.lower < 1e-12 {
+		return fmt.Errorf("truncnormal distribution with mean %f and stddev %f has almost no probability between min %f and max %f", td.mean, td.stdDev, td.min, td.max)
+	}
+	return nil
+}
+
+func (td *TruncatedNormalDistribution) GetNextValue() time.Duration {
+	p := td.lower + td.randomizer.Float64()*(td.upper-td.lower)
+	value := td.mean + td.stdDev*math.Sqrt2*math.Erfinv(2*p-1)
+	//rounding errors in the tails can leave the range
+	return microseconds(math.Max(td.min, math.Min(td.max, value)))
+}
+
+func (td *TruncatedNormalDistribution) SetRNGSeed(seed int64) {
+	td.randomizer = newSeededRandomizer(seed)
+}
+
+//LogNormalDistribution is parameterized either by mu and sigma of the underlying normal distribution, i.e. the median is e^mu,
+//or by the mean and stddev of the samples.
+type LogNormalDistribution struct {
+	randomizer *rand.Rand
+	mu         float64
+	sigma      float64
+}
+
+func (ld *LogNormalDistribution) SetParameters(values map[string]float64) error {
+	if _, exists := values["mean"]; exists {
+		if err := checkParameters("lognormal", values, []string{"mean", "stddev"}); err != nil {
+			return err
+		}
+		mean, stdDev := values["mean"], values["stddev"]
+		if mean <= 0 || stdDev < 0 {
+			return fmt.Errorf("lognormal distribution needs a mean > 0 and a stddev >= 0, got mean %f and stddev %f", mean, stdDev)
+		}
+		variance := math.Log(1 + stdDev*stdDev/(mean*mean))
+		ld.mu = math.Log(mean) - variance/2
+		ld.sigma = math.Sqrt(variance)
+		return nil
+	}
+	if err := checkParameters("lognormal", values, []string{"mu", "sigma"}); err != nil {
+		return fmt.Errorf("%v, or use mean and stddev", err)
+	}
+	if values["sigma"] < 0 {
+		return fmt.Errorf("lognormal distribution needs a sigma >= 0, got %f", values["sigma"])
+	}
+	ld.mu = values["mu"]
+	ld.sigma = values["sigma"]
+	return nil
+}
+
+func (ld *LogNormalDistribution) GetNextValue() time.Duration {
+	return microseconds(math.Exp(ld.mu + ld.sigma*ld.randomizer.NormFloat64()))
+}
+
+func (ld *LogNormalDistribution) SetRNGSeed(seed int64) {
+	ld.randomizer = newSeededRandomizer(seed)
+}
+
+//ParetoDistribution has samples of at least scale, with a tail which gets heavier for smaller shapes, i.e. the mean is infinite for shapes <= 1.
+//An optional max bounds the samples.
+type ParetoDistribution struct {
+	randomizer *rand.Rand
+	scale      float64
+	shape      float64
+	//bounded is 1-(scale/max)^shape, the probability of samples below max.
+	bounded float64
+}
+
+func (pd *ParetoDistribution) SetParameters(values map[string]float64) error {
+	if err := checkParameters("pareto", values, []string{"scale", "shape"}, "max"); err != nil {
+		return err
+	}
+	pd.scale = values["scale"]
+	pd.shape = values["shape"]
+	max := param(values, "max", math.Inf(1))
+	if pd.scale <= 0 || pd.shape <= 0 || max <= pd.scale {
+		return fmt.Errorf("pareto distribution needs a scale > 0, a shape > 0 and max > scale, got scale %f, shape %f and max %f", pd.scale, pd.shape, max)
+	}
+	pd.bounded = 1 - math.Pow(pd.scale/max, pd.shape)
+	return nil
+}
+
+func (pd *ParetoDistribution) GetNextValue() time.Duration {
+	return microseconds(pd.scale / math.Pow(1-pd.randomizer.Float64()*pd.bounded, 1/pd.shape))
+}
+
+func (pd *ParetoDistribution) SetRNGSeed(seed int64) {
+	pd.randomizer = newSeededRandomizer(seed)
+}
+
+//WeibullDistribution has a tail which is heavier than exponential for shapes < 1 and lighter for shapes > 1. A shape of 1 is an exponential distribution with mean scale.
+type WeibullDistribution struct {
+	randomizer *rand.Rand
+	scale      float64
+	shape      float64
+}
+
+func (wd *WeibullDistribution) SetParameters(values map[string]float64) error {
+	if err := checkParameters("weibull", values, []string{"scale", "shape"}); err != nil {
+		return err
+	}
+	wd.scale = values["scale"]
+	wd.shape = values["shape"]
+	if wd.scale <= 0 || wd.shape <= 0 {
+		return fmt.Errorf("weibull distribution needs a scale > 0 and a shape > 0, got scale %f and shape %f", wd.scale, wd.shape)
+	}
+	return nil
+}
+
+func (wd *WeibullDistribution) GetNextValue() time.Duration {
+	return microseconds(wd.scale * math.Pow(wd.randomizer.ExpFloat64(), 1/wd.shape))
+}
+
+func (wd *WeibullDistribution) SetRNGSeed(seed int64) {
+	wd.randomizer = newSeededRandomizer(seed)
+}
+
+type UniformDistribution struct {
+	randomizer *rand.Rand
+	min        float64
+	max        float64
+}
+
+func (ud *UniformDistribution) SetParameters(values map[string]float64) error {
+	if err := checkParameters("uniform", values, []string{"min", "max"}); err != nil {
+		return err
+	}
+	ud.min = values["min"]
+	ud.max = values["max"]
+	if ud.min < 0 || ud.max < ud.min {
+		return fmt.Errorf("uniform distribution needs 0 <= min <= max, got min %f and max %f", ud.min, ud.max)
+	}
+	return nil
+}
+
+func (ud *UniformDistribution) GetNextValue() time.Duration {
+	return microseconds(ud.min + ud.randomizer.Float64()*(ud.max-ud.min))
+}
+
+func (ud *UniformDistribution) SetRNGSeed(seed int64) {
+	ud.randomizer = newSeededRandomizer(seed)
+}
+
+//WeightedMixture samples from one of its components, which is chosen with a probability proportional to its weight, e.g. to emulate cache hits and misses.
+type WeightedMixture struct {
+	randomizer *rand.Rand
+	components []DistributionSampler
+	//cumulative contains the sums of the weights up to each component.
+	cumulative []float64
+}
+
+func (wm *WeightedMixture) setComponents(components []*api.MixtureComponent) error {
+	if len(components) == 0 {
+		return fmt.Errorf("%s distribution needs at least one component", MixtureDistribution)
+	}
+	total := 0.0
+	for i, c := range components {
+		if c.Weight <= 0 || math.IsInf(c.Weight, 0) || math.IsNaN(c.Weight) {
+			return fmt.Errorf("component %d of %s distribution needs a positive weight, got %f", i+1, MixtureDistribution, c.Weight)
+		}
+		if c.Work == nil {
+			return fmt.Errorf("component %d of %s distribution has no work", i+1, MixtureDistribution)
+		}
+		dist, err := LookupDistribution(c.Work)
+		if err != nil {
+			return fmt.Errorf("component %d of %s distribution: %v", i+1, MixtureDistribution, err)
+		}
+		total += c.Weight
+		wm.components = append(wm.components, dist)
+		wm.cumulative = append(wm.cumulative, total)
+	}
+	return nil
+}
+
+func (wm *WeightedMixture) SetParameters(values map[string]float64) error {
+	return checkParameters(MixtureDistribution, values, nil)
+}
+
+func (wm *WeightedMixture) GetNextValue() time.Duration {
+	choice := wm.randomizer.Float64() * wm.cumulative[len(wm.cumulative)-1]
+	i := sort.SearchFloat64s(wm.cumulative, choice)
+	//SearchFloat64s returns the first component whose sum is >= choice, which is only past the end for rounding errors
+	if i == len(wm.components) {
+		i--
+	}
+	return wm.components[i].GetNextValue()
+}
+
+//SetRNGSeed seeds the choice of components and derives the seeds of all components from the seed.
+func (wm *WeightedMixture) SetRNGSeed(seed int64) {
+	seeds := rand.New(rand.NewSource(seed))
+	wm.randomizer = newSeededRandomizer(seeds.Int63())
+	for _, c := range wm.components {
+		c.SetRNGSeed(seeds.Int63())
+	}
+}
+
+//EmpiricalSampler replays observed work times by inverting their CDF, i.e. a uniform probability is mapped to the value at which the CDF reaches it,
+//interpolating linearly between the points of the CDF. The optional parameter scale multiplies all values, e.g. 1000 for files in milliseconds.
+type EmpiricalSampler struct {
+	randomizer    *rand.Rand
+	values        []float64
+	probabilities []float64
+	scale         float64
+}
+
+func (es *EmpiricalSampler) setCDF(cdf *api.EmpiricalDistribution) error {
+	if cdf == nil {
+		return fmt.Errorf("%s distribution needs a file with samples or histogram buckets", EmpiricalDistribution)
+	}
+	values, probabilities := cdf.Values, cdf.Probabilities
+	if len(values) < 2 || len(values) != len(probabilities) {
+		return fmt.Errorf("%s distribution needs at least two points of the CDF with a value and probability each, got %d values and %d probabilities", EmpiricalDistribution, len(values), len(probabilities))
+	}
+	if probabilities[0] != 0 || probabilities[len(probabilities)-1] != 1 {
+		return fmt.Errorf("probabilities of the %s distribution must range from 0 to 1, got %f to %f", EmpiricalDistribution, probabilities[0], probabilities[len(probabilities)-1])
+	}
+	for i := range values {
+		if values[i] < 0 || (i > 0 && (values[i] < values[i-1] || probabilities[i] < probabilities[i-1])) {
+			return fmt.Errorf("values of the %s distribution must not be negative, and values and probabilities must be ascending, point %d is %f with probability %f", EmpiricalDistribution, i+1, values[i], probabilities[i])
+		}
+	}
+	es.values = values
+	es.probabilities = probabilities
+	return nil
+}
+
+func (es *EmpiricalSampler) SetParameters(values map[string]float64) error {
+	if err := checkParameters(EmpiricalDistribution, values, nil, "scale"); err != nil {
+		return err
+	}
+	es.scale = param(values, "scale", 1)
+	if es.scale <= 0 {
+		return fmt.Errorf("%s distribution needs a scale > 0, got %f", EmpiricalDistribution, es.scale)
+	}
+	return nil
+}
+
+func (es *EmpiricalSampler) GetNextValue() time.Duration {
+	p := es.randomizer.Float64()
+	//i is the first point whose probability is >= p, so p lies between the points i-1 and i
+	i := sort.SearchFloat64s(es.probabilities, p)
+	if i == 0 {
+		return microseconds(es.values[0] * es.scale)
+	}
+	lowerP, upperP := es.probabilities[i-1], es.probabilities[i]
+	value := es.values[i]
+	if upperP > lowerP {
+		value = es.values[i-1] + (p-lowerP)/(upperP-lowerP)*(es.values[i]-es.values[i-1])
+	}
+	return microseconds(value * es.scale)
+}
+
+func (es *EmpiricalSampler) SetRNGSeed(seed int64) {
+	es.randomizer = newSeededRandomizer(seed)
 }
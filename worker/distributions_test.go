@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dominik-/t-race/api"
+)
+
+//paramWork creates work with integer parameters, given as name, value pairs.
+func paramWork(distType string, params ...interface{}) *api.Work {
+	w := &api.Work{DistType: distType, Parameters: make(map[string]float64)}
+	for i := 0; i < len(params); i += 2 {
+		w.Parameters[params[i].(string)] = float64(params[i+1].(int))
+	}
+	return w
+}
+
+func mixtureWork(components ...*api.MixtureComponent) *api.Work {
+	return &api.Work{DistType: MixtureDistribution, Components: components}
+}
+
+func TestLookupDistributionRejectsInvalidParameters(t *testing.T) {
+	for name, w := range map[string]*api.Work{
+		"unknown type":               paramWork("gamma", "shape", 2),
+		"case-sensitive type":        paramWork("Constant", "value", 1),
+		"components of non-mixture":  {DistType: "constant", Parameters: map[string]float64{"value": 1}, Components: mixtureWork(&api.MixtureComponent{Weight: 1, Work: paramWork("constant", "value", 1)}).Components},
+		"cdf of non-empirical":       {DistType: "constant", Parameters: map[string]float64{"value": 1}, Empirical: &api.EmpiricalDistribution{Values: []float64{0, 1}, Probabilities: []float64{0, 1}}},
+		"missing parameter":          paramWork("constant"),
+		"unknown parameter":          paramWork("constant", "value", 1, "stddev", 1),
+		"negative constant":          paramWork("constant", "value", -1),
+		"negative sigma":             paramWork("lognormal", "mu", 5, "sigma", -1),
+		"lognormal without sigma":    paramWork("lognormal", "mu", 5),
+		"lognormal mean 0":           paramWork("lognormal", "mean", 0, "stddev", 10),
+		"negative stddev":            paramWork("gaussian", "mean", 100, "stddev", -1),
+		"exponential mean 0":         paramWork("exponential", "mean", 0),
+		"truncnormal stddev 0":       paramWork("truncnormal", "mean", 100, "stddev", 0),
+		"truncnormal min > max":      paramWork("truncnormal", "mean", 100, "stddev", 10, "min", 200, "max", 50),
+		"truncnormal negative min":   paramWork("truncnormal", "mean", 100, "stddev", 10, "min", -1),
+		"truncnormal empty range":    paramWork("truncnormal", "mean", 0, "stddev", 1, "min", 100),
+		"pareto shape 0":             paramWork("pareto", "scale", 100, "shape", 0),
+		"pareto negative shape":      paramWork("pareto", "scale", 100, "shape", -2),
+		"pareto scale 0":             paramWork("pareto", "scale", 0, "shape", 2),
+		"pareto max <= scale":        paramWork("pareto", "scale", 100, "shape", 2, "max", 100),
+		"weibull shape 0":            paramWork("weibull", "scale", 100, "shape", 0),
+		"uniform min > max":          paramWork("uniform", "min", 200, "max", 100),
+		"uniform negative min":       paramWork("uniform", "min", -100, "max", 100),
+		"mixture without components": mixtureWork(),
+		"mixture weight 0": mixtureWork(
+			&api.MixtureComponent{Weight: 1, Work: paramWork("constant", "value", 1)},
+			&api.MixtureComponent{Weight: 0, Work: paramWork("constant", "value", 2)}),
+		"mixture negative weight": mixtureWork(&api.MixtureComponent{Weight: -1, Work: paramWork("constant", "value", 1)}),
+		"mixture NaN weight":      mixtureWork(&api.MixtureComponent{Weight: math.NaN(), Work: paramWork("constant", "value", 1)}),
+		"mixture without work":    mixtureWork(&api.MixtureComponent{Weight: 1}),
+		"mixture invalid work":    mixtureWork(&api.MixtureComponent{Weight: 1, Work: paramWork("uniform", "min", 2, "max", 1)}),
+		"mixture parameters":      {DistType: MixtureDistribution, Parameters: map[string]float64{"weight": 1}, Components: mixtureWork(&api.MixtureComponent{Weight: 1, Work: paramWork("constant", "value", 1)}).Components},
+		"empirical without cdf":   paramWork(EmpiricalDistribution),
+		"empirical descending":    {DistType: EmpiricalDistribution, Empirical: &api.EmpiricalDistribution{Values: []float64{10, 5}, Probabilities: []float64{0, 1}}},
+		"empirical incomplete":    {DistType: EmpiricalDistribution, Empirical: &api.EmpiricalDistribution{Values: []float64{1, 5}, Probabilities: []float64{0, 0.9}}},
+		"empirical scale 0":       {DistType: EmpiricalDistribution, Parameters: map[string]float64{"scale": 0}, Empirical: &api.EmpiricalDistribution{Values: []float64{1, 5}, Probabilities: []float64{0, 1}}},
+	} {
+		if _, err := LookupDistribution(w); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDistributionsHaveExpectedMean(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		work *api.Work
+		mean float64
+	}{
+		{"constant", paramWork("constant", "value", 500), 500},
+		{"gaussian", paramWork("gaussian", "mean", 1000, "stddev", 100), 1000},
+		{"exponential", paramWork("exponential", "mean", 1000), 1000},
+		{"half-normal", paramWork("truncnormal", "mean", 0, "stddev", 1000), 1000 * math.Sqrt(2/math.Pi)},
+		{"truncnormal", paramWork("truncnormal", "mean", 1000, "stddev", 500, "min", 500, "max", 1500), 1000},
+		{"lognormal", paramWork("lognormal", "mu", 7, "sigma", 1), math.Exp(7.5)},
+		{"lognormal by mean", paramWork("lognormal", "mean", 1000, "stddev", 2000), 1000},
+		{"pareto", paramWork("pareto", "scale", 100, "shape", 3), 150},
+		{"weibull", paramWork("weibull", "scale", 1000, "shape", 2), 1000 * math.Gamma(1.5)},
+		{"uniform", paramWork("uniform", "min", 100, "max", 300), 200},
+		{"mixture", mixtureWork(
+			&api.MixtureComponent{Weight: 1, Work: paramWork("constant", "value", 100)},
+			&api.MixtureComponent{Weight: 3, Work: paramWork("uniform", "min", 400, "max", 600)}), 400},
+		{"empirical", &api.Work{DistType: EmpiricalDistribution, Parameters: map[string]float64{"scale": 2},
+			Empirical: &api.EmpiricalDistribution{Values: []float64{0, 100, 1000}, Probabilities: []float64{0, 0.5, 1}}}, 2 * (0.5*50 + 0.5*550)},
+	} {
+		sampler, err := LookupDistribution(test.work)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		sampler.SetRNGSeed(1)
+		const n = 200000
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += float64(sampler.GetNextValue().Microseconds())
+		}
+		if mean := sum / n; math.Abs(mean-test.mean)/test.mean > 0.02 {
+			t.Errorf("%s: expected a mean of %.1fµs, got %.1fµs", test.name, test.mean, mean)
+		}
+	}
+}
+
+func TestBoundedDistributionsStayInRange(t *testing.T) {
+	for _, test := range []struct {
+		work     *api.Work
+		min, max float64
+	}{
+		{paramWork("truncnormal", "mean", 1000, "stddev", 5000, "min", 900, "max", 1100), 900, 1100},
+		{paramWork("pareto", "scale", 100, "shape", 1, "max", 1000), 100, 1000},
+		{paramWork("uniform", "min", 100, "max", 100), 100, 100},
+		{paramWork("gaussian", "mean", 0, "stddev", 100), 0, math.Inf(1)},
+	} {
+		sampler, err := LookupDistribution(test.work)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sampler.SetRNGSeed(1)
+		for i := 0; i < 10000; i++ {
+			if value := float64(sampler.GetNextValue().Microseconds()); value < test.min || value > test.max {
+				t.Fatalf("%s: expected samples between %.0f and %.0f, got %.0f", test.work.DistType, test.min, test.max, value)
+			}
+		}
+	}
+}
@@ -2,12 +2,11 @@ package worker
 
 import (
 	"context"
-	"encoding/binary"
 	"io"
 	"log"
 	"math"
-	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dominik-/t-race/api"
@@ -15,18 +14,13 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
-
-	jaegerclient "github.com/uber/jaeger-client-go"
-	jaegercfg "github.com/uber/jaeger-client-go/config"
 )
 
-//TODO this flag is implementation-specific for jaeger-client-go impl of opentracing SpanContext. Should remove this.
-const flagSampled = byte(1)
-
 type OpenTracingSpanGenerator struct {
 	TraceCounter     int64
 	SpanDurationHist prometheus.Histogram
 	Tracer           opentracing.Tracer
+	Inspector        SpanContextInspector
 	Closer           io.Closer
 	Units            map[string]Unit
 	ServiceName      string
@@ -48,47 +42,75 @@ func (sg *OpenTracingSpanGenerator) GetTracer() opentracing.Tracer {
 	return sg.Tracer
 }
 
-// InitTracer returns an instance of a Tracer that logs sampled Spans to stdout the given sinkAddress.
-func InitTracer(sinkAddress, serviceName, samplingstrategy string, samplingParam float64) (opentracing.Tracer, io.Closer, error) {
-	tracerConfig := jaegercfg.Configuration{
-		ServiceName: serviceName,
-		Sampler: &jaegercfg.SamplerConfig{
-			Type:  samplingstrategy,
-			Param: samplingParam,
-		},
-		Reporter: &jaegercfg.ReporterConfig{
-			LocalAgentHostPort: sinkAddress,
-			LogSpans:           true,
-		},
-	}
-	return tracerConfig.NewTracer()
-}
-
+//OpenTracingUnitSpanGenerator invokes a root unit. TraceCounter and lag are accessed atomically, so they come first to be 64-bit aligned.
 type OpenTracingUnitSpanGenerator struct {
-	TraceCounter        int64
+	TraceCounter int64
+	//lag is the time in nanoseconds by which the last invocation was late.
+	lag                 int64
 	SpanDurationHist    prometheus.Histogram
 	ReportHistogram     bool
 	Tracer              opentracing.Tracer
 	Unit                Unit
 	EffectiveThroughput float64
-	ServiceName         string
-	Reporter            ResultReporter
+	//Shards invoke the unit concurrently, each at the times of its own arrival process. If Profile is set, the arrivals follow the load profile.
+	Shards      []*generatorShard
+	Profile     *api.LoadProfile
+	UnitName    string
+	Metrics     *GeneratorMetrics
+	ServiceName string
+	Reporter    ResultReporter
 }
 
-func NewOpenTracingUnitSpanGenerator(unit Unit, serviceName string, tracer opentracing.Tracer, throughput int64, histogram ...prometheus.Histogram) UnitContextGenerator {
+//generatorShard is a part of the arrivals of a generator, which is generated by its own goroutine.
+type generatorShard struct {
+	arrivals ArrivalProcess
+	schedule *loadSchedule
+}
+
+//NewOpenTracingUnitSpanGenerator creates a generator for a root unit. Without a load profile, the unit is invoked with the given throughput times its ratio, or once per second if that is 0.
+//With a load profile, the throughput is taken from the profile, and the given throughput is only the reference of the arrival process. The seed is the seed of the arrival process.
+//Metrics are optional.
+func NewOpenTracingUnitSpanGenerator(unit Unit, config *api.Unit, profile *api.LoadProfile, serviceName string, tracer opentracing.Tracer, throughput int64, seed int64, metrics *GeneratorMetrics, histogram ...prometheus.Histogram) (UnitContextGenerator, error) {
+	reference := float64(throughput)
+	if profile != nil && reference <= 0 {
+		reference = maxThroughput(profile)
+	}
 	generator := &OpenTracingUnitSpanGenerator{
 		TraceCounter:        0,
 		Tracer:              tracer,
 		Unit:                unit,
-		EffectiveThroughput: float64(throughput) * unit.GetLoadPercentage(),
+		EffectiveThroughput: reference * unit.GetLoadPercentage(),
+		Profile:             profile,
+		UnitName:            config.Identifier,
+		Metrics:             metrics,
 		ServiceName:         serviceName,
 		ReportHistogram:     false,
 	}
+	if profile == nil && generator.EffectiveThroughput <= 0 {
+		log.Println("Target throughput of 0 or below. Setting it to 1 trace per second.")
+		generator.EffectiveThroughput = 1
+	}
+	//the schedule of a profile speeds up arrivals by the throughput of the profile relative to the reference
+	peak := generator.EffectiveThroughput
+	if profile != nil && reference > 0 {
+		peak *= maxThroughput(profile) / reference
+	}
+	arrivals, err := NewArrivalShards(config.Arrival, generator.EffectiveThroughput, peak, seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range arrivals {
+		shard := &generatorShard{arrivals: a}
+		if profile != nil {
+			shard.schedule = newLoadSchedule(profile, reference)
+		}
+		generator.Shards = append(generator.Shards, shard)
+	}
 	if len(histogram) > 0 {
 		generator.SpanDurationHist = histogram[0]
 		generator.ReportHistogram = true
 	}
-	return generator
+	return generator, nil
 }
 
 func NewOpenTracingSpanGenerator(tracer opentracing.Tracer, worker *Worker) SpanGenerator {
@@ -96,72 +118,19 @@ func NewOpenTracingSpanGenerator(tracer opentracing.Tracer, worker *Worker) Span
 		TraceCounter:     0,
 		Units:            worker.UnitExecutorMap,
 		Tracer:           tracer,
+		Inspector:        worker.Inspector,
 		SpanDurationHist: worker.SpanDurationHist,
 		ServiceName:      worker.Config.ServiceName,
 	}
 }
 
-//this function is actually jaeger-specific, as the OpentTacing API doesn't include any operations for accessing content of the context.
-func getSampledFlag(ctx opentracing.SpanContext) bool {
-	if converted, ok := ctx.(jaegerclient.SpanContext); ok {
-		return converted.IsSampled()
-	}
-	panic("Couldn't convert opentracing context to jaeger context!")
-	// if ctx == nil {
-	// 	//we can panic here, because we will panic further down anyways if the context is nil
-	// 	panic("Context is nil!!")
-	// }
-	// v := reflect.ValueOf(ctx)
-	// f := v.FieldByName("flags")
-
-	// if !f.IsValid() || f.Kind() != reflect.Uint8 {
-	// 	panic("Couldn't convert to byte!!")
-	// }
-
-	// byteFlags := byte(f.Uint())
-
-	// return (byteFlags & flagSampled) == flagSampled
-}
-
-//TODO: remove this after conversion of opentracing spancontext to jaeger-client-go spancontext (see getSampledFlag() method)
-type TraceID struct {
-	High, Low uint64
-}
-
-//TODO: refactor this to use a conversion of opentracing spancontext to jaeger-client-go spancontext (see getSampledFlag() method)
-func getTraceIdAsBytes(ctx opentracing.SpanContext) []byte {
-	v := reflect.ValueOf(ctx)
-	f := v.FieldByName("traceID")
-
-	if !f.IsValid() || f.Kind() != reflect.Struct {
-		log.Printf("Couldn't convert to struct!!")
-	}
-	//One level deeper...
-
-	b1, b2 := make([]byte, 8), make([]byte, 8)
-	binary.BigEndian.PutUint64(b1, f.FieldByName("High").Uint())
-	binary.BigEndian.PutUint64(b2, f.FieldByName("Low").Uint())
-	return append(b1, b2...)
-}
-
-//TODO: refactor this to use a conversion of opentracing spancontext to jaeger-client-go spancontext (see getSampledFlag() method)
-func getSpanID(ctx opentracing.SpanContext) []byte {
-	v := reflect.ValueOf(ctx)
-	f := v.FieldByName("spanID")
-
-	if !f.IsValid() || f.Kind() != reflect.Uint64 {
-		log.Printf("Couldn't convert to uint!!")
-	}
-	b := make([]byte, 8)
-	binary.BigEndian.PutUint64(b, f.Uint())
-	return b
-}
-
 func (sg *OpenTracingSpanGenerator) finishAndReportTimedOTSpan(startTime time.Time, span opentracing.Span, childSpanCount int64, reporter ResultReporter) error {
-	sampled := getSampledFlag(span.Context())
-	traceID := getTraceIdAsBytes(span.Context())
-	spanID := getSpanID(span.Context())
+	info, err := sg.Inspector.Inspect(span.Context())
 	span.Finish()
+	if err != nil {
+		log.Printf("Couldn't inspect span context: %v", err)
+		return err
+	}
 	finishTimeDelta := time.Since(startTime)
 	sg.SpanDurationHist.Observe(float64(finishTimeDelta.Nanoseconds() / 1000.0))
 	started, err := ptypes.TimestampProto(startTime)
@@ -173,11 +142,12 @@ func (sg *OpenTracingSpanGenerator) finishAndReportTimedOTSpan(startTime time.Ti
 	reporter.Collect(&api.Result{
 		TraceNum:   sg.TraceCounter,
 		SpanNum:    childSpanCount,
-		TraceId:    traceID,
-		SpanId:     spanID,
+		TraceId:    info.TraceID,
+		SpanId:     info.SpanID,
+		ParentId:   info.ParentID,
 		StartTime:  started,
 		FinishTime: finished,
-		Sampled:    sampled,
+		Sampled:    info.Sampled,
 	})
 	return nil
 }
@@ -241,28 +211,120 @@ func (sg *OpenTracingSpanGenerator) WriteSpansUntilExitSignal(exitSignalChannel
 	return finishedIndicator
 }
 
+//GenerateUntilExitSignal invokes the unit at the times of its arrival processes until the stop signal is received. Once per second, the achieved throughput is compared with the target.
 func (gen *OpenTracingUnitSpanGenerator) GenerateUntilExitSignal(stopSignalRecv <-chan bool, reporter ResultReporter, waitGroup *sync.WaitGroup) {
-	interval := calculateIntervalForThroughput(gen.EffectiveThroughput)
 	go func() {
-		ticker := time.NewTicker(interval)
-		//TODO make report interval configurable; together with channel buffer size, this limits the maximum throughput!
+		start := time.Now()
+		//stopped ends generation, including waiting for a free slot under the block policy
+		stopped, stop := context.WithCancel(context.Background())
+		var shardWG sync.WaitGroup
+		for _, shard := range gen.Shards {
+			shardWG.Add(1)
+			go gen.generate(shard, start, stopped, &shardWG)
+		}
+		ticker := time.NewTicker(time.Second)
+		lastCount, lastTime := int64(0), start
 	GenerateLoop:
 		for {
 			select {
 			case <-stopSignalRecv:
 				//log.Println("Received shutdown signal at write Span loop.")
 				break GenerateLoop
-			case <-ticker.C:
-				//write span (async) to the writer, generate new parent context
-				go gen.Unit.Invoke(context.Background(), gen.Tracer)
-				break
+			case now := <-ticker.C:
+				count := atomic.LoadInt64(&gen.TraceCounter)
+				gen.observe(now.Sub(start), count-lastCount, now.Sub(lastTime))
+				lastCount, lastTime = count, now
 			}
 		}
+		ticker.Stop()
+		stop()
+		shardWG.Wait()
+		elapsed := time.Since(start)
+		count := atomic.LoadInt64(&gen.TraceCounter)
+		log.Printf("Unit %s started %d traces in %.1fs: %.2f/s, target %.2f/s, last lag %v.", gen.UnitName, count, elapsed.Seconds(), float64(count)/elapsed.Seconds(), gen.meanTarget(elapsed), time.Duration(atomic.LoadInt64(&gen.lag)))
 		//signal to parent that this worker is successfully finished
 		waitGroup.Done()
 	}()
 }
 
+//generate invokes the unit at the times of the arrival process of a shard, until it is stopped. Only invocations admitted by the concurrency limits are counted.
+func (gen *OpenTracingUnitSpanGenerator) generate(shard *generatorShard, start time.Time, stopped context.Context, shardWG *sync.WaitGroup) {
+	defer shardWG.Done()
+	next := shard.arrivals.NextArrival(0)
+	nextTime, scheduled := shard.arrivalTime(start, next)
+	timer := time.NewTimer(time.Until(nextTime))
+	defer timer.Stop()
+	if !scheduled {
+		timer.Stop()
+	}
+	for {
+		select {
+		case <-stopped.Done():
+			return
+		case <-timer.C:
+			//arrivals are scheduled relative to the start, all arrivals which are due are invoked in batches, so late timers don't lower the rate.
+			//Timers don't fire more often than about once per millisecond, so at high rates every timer starts a batch.
+			now := time.Now()
+			for scheduled && !nextTime.After(now) {
+				batch, admitted := 0, int64(0)
+				for ; batch < maxBatch && scheduled && !nextTime.After(now) && stopped.Err() == nil; batch++ {
+					if gen.Unit.Go(context.Background(), gen.Tracer, stopped) {
+						admitted++
+					}
+					//measured after admission, which may have waited under the block policy
+					atomic.StoreInt64(&gen.lag, int64(time.Since(nextTime)))
+					next = shard.arrivals.NextArrival(next)
+					nextTime, scheduled = shard.arrivalTime(start, next)
+				}
+				atomic.AddInt64(&gen.TraceCounter, admitted)
+				if stopped.Err() != nil {
+					return
+				}
+				now = time.Now()
+			}
+			if scheduled {
+				timer.Reset(time.Until(nextTime))
+			}
+		}
+	}
+}
+
+//arrivalTime returns the wall-clock time of an arrival of the arrival process, and false if the arrival never happens under the load profile.
+func (shard *generatorShard) arrivalTime(start time.Time, arrival time.Duration) (time.Time, bool) {
+	if shard.schedule == nil {
+		return start.Add(arrival), true
+	}
+	offset, ok := shard.schedule.offset(arrival)
+	return start.Add(offset), ok
+}
+
+//targetAt returns the throughput the unit should have at the given time since the start.
+func (gen *OpenTracingUnitSpanGenerator) targetAt(elapsed time.Duration) float64 {
+	if gen.Profile == nil {
+		return gen.EffectiveThroughput
+	}
+	return gen.Profile.ThroughputAt(elapsed.Seconds()) * gen.Unit.GetLoadPercentage()
+}
+
+//meanTarget returns the mean throughput the unit should have had until the given time since the start.
+func (gen *OpenTracingUnitSpanGenerator) meanTarget(elapsed time.Duration) float64 {
+	if gen.Profile == nil {
+		return gen.EffectiveThroughput
+	}
+	return gen.Profile.MeanThroughput(elapsed.Seconds()) * gen.Unit.GetLoadPercentage()
+}
+
+//observe updates the throughput metrics of the unit with the traces started in the last interval.
+func (gen *OpenTracingUnitSpanGenerator) observe(elapsed time.Duration, traces int64, interval time.Duration) {
+	if gen.Metrics == nil {
+		return
+	}
+	gen.Metrics.TargetThroughput.WithLabelValues(gen.UnitName).Set(gen.targetAt(elapsed))
+	gen.Metrics.AchievedThroughput.WithLabelValues(gen.UnitName).Set(float64(traces) / interval.Seconds())
+	gen.Metrics.Traces.WithLabelValues(gen.UnitName).Add(float64(traces))
+	gen.Metrics.Lag.WithLabelValues(gen.UnitName).Set(time.Duration(atomic.LoadInt64(&gen.lag)).Seconds())
+}
+
 func normalizeWeightsForRR(sg *OpenTracingSpanGenerator) {
 	minWeight := 1.0
 	maxWeight := 0.0
@@ -318,18 +380,17 @@ func calculateIntervalByThroughput(targetThroughput int64) time.Duration {
 	return time.Duration(1000000/targetThroughput) * time.Microsecond
 }
 
+//calculateIntervalForThroughput returns the interval between arrivals in nanoseconds, without limiting the throughput.
 func calculateIntervalForThroughput(targetThroughput float64) time.Duration {
-	if targetThroughput > 1000000 {
-		targetThroughput = 0
-	}
-	if targetThroughput < 0 {
-		targetThroughput = 0
+	if targetThroughput <= 0 {
+		log.Println("Target throughput of 0 or below. Setting interval to 1s.")
+		return time.Second
 	}
-	if targetThroughput == 0 {
-		log.Println("Target throughput of 0 or above maximum (1mio). Setting throughput to 1mio reqs/s.")
-		return 1 * time.Microsecond
+	interval := time.Duration(float64(time.Second) / targetThroughput)
+	if interval < 1 {
+		interval = 1
 	}
-	return time.Duration(1000000/targetThroughput) * time.Microsecond
+	return interval
 }
 
 func calculateThroughputScaledByWeights(throughput, combinedWeights int64) time.Duration {
@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	zipkintracer "github.com/openzipkin-contrib/zipkin-go-opentracing"
+	jaegerclient "github.com/uber/jaeger-client-go"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+//Sampled flag as used by jaeger and the W3C traceparent header.
+const flagSampled = byte(1)
+
+//ErrUnsupportedSpanContext is returned by a SpanContextInspector for span contexts created by a different tracer backend.
+var ErrUnsupportedSpanContext = errors.New("span context is not supported by this inspector")
+
+//SpanContextInfo contains the identifiers and sampling decision of a span. IDs are big endian; ParentID is nil for root spans, and for spans of tracers
+//which don't record parents in span contexts.
+type SpanContextInfo struct {
+	TraceID  []byte
+	SpanID   []byte
+	ParentID []byte
+	Sampled  bool
+}
+
+//SpanContextInspector reads a SpanContextInfo from span contexts of a specific tracer backend, as the opentracing API doesn't include any operations for accessing content of the context.
+type SpanContextInspector interface {
+	Inspect(opentracing.SpanContext) (*SpanContextInfo, error)
+}
+
+func traceIDToBytes(high, low uint64) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b[:8], high)
+	binary.BigEndian.PutUint64(b[8:], low)
+	return b
+}
+
+//spanIDToBytes returns nil for the span ID 0, which is used by tracers to indicate a missing parent.
+func spanIDToBytes(id uint64) []byte {
+	if id == 0 {
+		return nil
+	}
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
+
+//JaegerInspector inspects span contexts of jaeger-client-go.
+type JaegerInspector struct {
+}
+
+func (i *JaegerInspector) Inspect(ctx opentracing.SpanContext) (*SpanContextInfo, error) {
+	converted, ok := ctx.(jaegerclient.SpanContext)
+	if !ok {
+		return nil, ErrUnsupportedSpanContext
+	}
+	return &SpanContextInfo{
+		TraceID:  traceIDToBytes(converted.TraceID().High, converted.TraceID().Low),
+		SpanID:   spanIDToBytes(uint64(converted.SpanID())),
+		ParentID: spanIDToBytes(uint64(converted.ParentID())),
+		Sampled:  converted.IsSampled(),
+	}, nil
+}
+
+//ZipkinInspector inspects span contexts of zipkin-go-opentracing.
+type ZipkinInspector struct {
+}
+
+func (i *ZipkinInspector) Inspect(ctx opentracing.SpanContext) (*SpanContextInfo, error) {
+	converted, ok := ctx.(zipkintracer.SpanContext)
+	if !ok {
+		return nil, ErrUnsupportedSpanContext
+	}
+	info := &SpanContextInfo{
+		TraceID: traceIDToBytes(converted.TraceID.High, converted.TraceID.Low),
+		SpanID:  spanIDToBytes(uint64(converted.ID)),
+		Sampled: converted.Sampled != nil && *converted.Sampled,
+	}
+	if converted.ParentID != nil {
+		info.ParentID = spanIDToBytes(uint64(*converted.ParentID))
+	}
+	return info, nil
+}
+
+//OTelInspector inspects span contexts of the OpenTelemetry opentracing bridge. The bridge doesn't expose IDs and flags, so span contexts are injected in W3C format.
+//Parent IDs aren't part of span contexts of the bridge, so ParentID is always nil; units take the span ID of the context they start their span from instead.
+//Span processors can't track parents either, since the SDK doesn't call them for spans which aren't sampled.
+type OTelInspector struct {
+	propagator *otbridge.BridgeTracer
+}
+
+func NewOTelInspector() *OTelInspector {
+	propagator := otbridge.NewBridgeTracer()
+	propagator.SetTextMapPropagator(propagation.TraceContext{})
+	return &OTelInspector{
+		propagator: propagator,
+	}
+}
+
+func (i *OTelInspector) Inspect(ctx opentracing.SpanContext) (*SpanContextInfo, error) {
+	header := http.Header{}
+	err := i.propagator.Inject(ctx, opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+	if err != nil {
+		return nil, ErrUnsupportedSpanContext
+	}
+	//traceparent is version-traceid-spanid-flags, e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	parts := strings.Split(header.Get("traceparent"), "-")
+	if len(parts) != 4 {
+		return nil, opentracing.ErrInvalidSpanContext
+	}
+	traceID, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return nil, opentracing.ErrInvalidSpanContext
+	}
+	info := &SpanContextInfo{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: flags[0]&flagSampled == flagSampled,
+	}
+	return info, nil
+}
+
+//inspectChild inspects the span context of a span, which was started as child of parent. Inspectors which can't read parent IDs leave ParentID nil,
+//in which case the span ID of parent is used. Root spans have a nil parent, or an empty one which can't be inspected, if the tracer didn't find a context.
+func inspectChild(inspector SpanContextInspector, span opentracing.SpanContext, parent opentracing.SpanContext) (*SpanContextInfo, error) {
+	info, err := inspector.Inspect(span)
+	if err != nil || info.ParentID != nil || parent == nil {
+		return info, err
+	}
+	if parentInfo, err := inspector.Inspect(parent); err == nil && bytes.Equal(parentInfo.TraceID, info.TraceID) {
+		info.ParentID = parentInfo.SpanID
+	}
+	return info, nil
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+)
+
+//inspectSpan inspects a span started as child of parent and fails the test on errors.
+func inspectSpan(t *testing.T, inspector SpanContextInspector, span opentracing.Span, parent opentracing.SpanContext) *SpanContextInfo {
+	t.Helper()
+	info, err := inspectChild(inspector, span.Context(), parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+//TestInspectorsReadParents starts a root span, a child of its remote context and a local child for each backend, sampled or not, and compares their IDs.
+func TestInspectorsReadParents(t *testing.T) {
+	for _, provider := range []string{"jaeger", "zipkin", "otlp"} {
+		for _, sampled := range []bool{true, false} {
+			backend, err := LookupTracerBackend(provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+			param := 0.0
+			if sampled {
+				param = 1
+			}
+			tracer, closer, err := backend.NewTracer("localhost:1", "frontend", "const", param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inspector := backend.Inspector()
+
+			//root spans start from the empty context the tracer extracts from requests without headers
+			empty, _ := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(http.Header{}))
+			root := tracer.StartSpan("root", opentracing.ChildOf(empty))
+			rootInfo := inspectSpan(t, inspector, root, empty)
+			if rootInfo.ParentID != nil || rootInfo.Sampled != sampled {
+				t.Errorf("%s, sampled %t: expected a root span with sampled %t, got parent %x and sampled %t", provider, sampled, sampled, rootInfo.ParentID, rootInfo.Sampled)
+			}
+
+			header := http.Header{}
+			if err := tracer.Inject(root.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header)); err != nil {
+				t.Fatal(err)
+			}
+			remote, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
+			if err != nil {
+				t.Fatal(err)
+			}
+			remoteChild := tracer.StartSpan("remote", opentracing.ChildOf(remote))
+			localChild, _ := opentracing.StartSpanFromContextWithTracer(opentracing.ContextWithSpan(context.Background(), root), tracer, "local")
+			for name, info := range map[string]*SpanContextInfo{
+				"remote": inspectSpan(t, inspector, remoteChild, remote),
+				"local":  inspectSpan(t, inspector, localChild, root.Context()),
+			} {
+				if !bytes.Equal(info.TraceID, rootInfo.TraceID) || !bytes.Equal(info.ParentID, rootInfo.SpanID) || info.Sampled != sampled {
+					t.Errorf("%s, sampled %t: expected %s child in trace %x with parent %x, got trace %x with parent %x, sampled %t",
+						provider, sampled, name, rootInfo.TraceID, rootInfo.SpanID, info.TraceID, info.ParentID, info.Sampled)
+				}
+			}
+			localChild.Finish()
+			remoteChild.Finish()
+			root.Finish()
+			//the sink isn't reachable, so flushing the spans can only time out
+			go closer.Close()
+		}
+	}
+}
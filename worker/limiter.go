@@ -0,0 +1,240 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//Overload policies of a ConcurrencyLimiter.
+const (
+	//DropPolicy discards invocations while the limit is reached.
+	DropPolicy = "drop"
+	//QueuePolicy lets a bounded number of invocations wait for a free slot, and discards the rest.
+	QueuePolicy = "queue"
+	//BlockPolicy makes the generator wait for a free slot, which lowers the rate of arrivals to what the worker can handle.
+	//Asynchronous calls can't wait, since their caller holds a slot itself, so they are discarded.
+	BlockPolicy = "block"
+)
+
+//DefaultOverloadPolicy is used by limits which don't configure a policy.
+const DefaultOverloadPolicy = DropPolicy
+
+//OverloadPolicies returns the names of all overload policies.
+func OverloadPolicies() []string {
+	return []string{BlockPolicy, DropPolicy, QueuePolicy}
+}
+
+//ConcurrencyLimiter limits the number of concurrent invocations of a worker or unit.
+type ConcurrencyLimiter struct {
+	//Scope is "worker" or "unit", and labels dropped invocations.
+	Scope  string
+	Policy string
+	slots  chan struct{}
+	queue  chan struct{}
+}
+
+//NewConcurrencyLimiter creates a limiter from its configuration. Returns nil if the configuration is nil, i.e. the number of invocations isn't limited.
+func NewConcurrencyLimiter(config *api.Concurrency, scope string) (*ConcurrencyLimiter, error) {
+	if config == nil {
+		return nil, nil
+	}
+	policy := DefaultOverloadPolicy
+	if config.Policy != "" {
+		policy = strings.ToLower(config.Policy)
+	}
+	if policy != DropPolicy && policy != QueuePolicy && policy != BlockPolicy {
+		return nil, fmt.Errorf("unknown overload policy %s, known policies are: %s", config.Policy, strings.Join(OverloadPolicies(), ", "))
+	}
+	if config.Limit < 1 || config.Queue < 0 {
+		return nil, fmt.Errorf("concurrency limit must be at least 1 and queue must not be negative, got limit %d and queue %d", config.Limit, config.Queue)
+	}
+	limiter := &ConcurrencyLimiter{
+		Scope:  scope,
+		Policy: policy,
+		slots:  make(chan struct{}, config.Limit),
+	}
+	if policy == QueuePolicy {
+		limiter.queue = make(chan struct{}, config.Queue)
+	}
+	return limiter, nil
+}
+
+func (l *ConcurrencyLimiter) tryAcquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *ConcurrencyLimiter) acquire(wait context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-wait.Done():
+		return false
+	}
+}
+
+func (l *ConcurrencyLimiter) release() {
+	<-l.slots
+}
+
+func (l *ConcurrencyLimiter) tryEnqueue() bool {
+	select {
+	case l.queue <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+//admission holds the slots of an invocation, and the queues it waits in for the remaining slots.
+type admission struct {
+	//limiters are all limiters of the invocation, in the order in which slots are taken.
+	limiters []*ConcurrencyLimiter
+	acquired []*ConcurrencyLimiter
+	queued   []*ConcurrencyLimiter
+}
+
+//admit applies the policies of all limiters, nil limiters are skipped. Under the block policy, the caller waits until a slot is free or wait is done,
+//a nil wait drops the invocation instead. Returns the limiter which dropped the invocation, if it was dropped.
+func admit(wait context.Context, limiters ...*ConcurrencyLimiter) (*admission, *ConcurrencyLimiter) {
+	a := &admission{}
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		a.limiters = append(a.limiters, l)
+		switch {
+		case l.tryAcquire():
+			a.acquired = append(a.acquired, l)
+		case l.Policy == BlockPolicy && wait != nil && l.acquire(wait):
+			a.acquired = append(a.acquired, l)
+		case l.Policy == QueuePolicy && l.tryEnqueue():
+			a.queued = append(a.queued, l)
+		default:
+			a.release()
+			return nil, l
+		}
+	}
+	return a, nil
+}
+
+//await waits for the slots of all limiters the invocation is queued in. A queued invocation doesn't hold any slots while it waits: it frees the slots it
+//already has and takes all slots in the order of limiters, like invocations blocked in admit. Otherwise two invocations could each hold the slot the other
+//one waits for. Slots become free when running invocations finish, so waiting ends eventually, or when stop is done. Returns false if the invocation
+//stopped waiting, the slots it took until then are freed by release. A nil stop waits until the slots are free.
+func (a *admission) await(stop context.Context) bool {
+	if len(a.queued) == 0 {
+		return true
+	}
+	for _, l := range a.acquired {
+		l.release()
+	}
+	a.acquired = nil
+	if !a.acquireAll(stop) {
+		return false
+	}
+	for _, l := range a.queued {
+		<-l.queue
+	}
+	a.queued = nil
+	return true
+}
+
+//acquireAll takes the slots of all limiters in their order, and waits for free slots until stop is done. A nil stop waits until the slots are free.
+func (a *admission) acquireAll(stop context.Context) bool {
+	var stopped <-chan struct{}
+	if stop != nil {
+		stopped = stop.Done()
+	}
+	for _, l := range a.limiters {
+		select {
+		case l.slots <- struct{}{}:
+			a.acquired = append(a.acquired, l)
+		case <-stopped:
+			return false
+		}
+	}
+	return true
+}
+
+//release frees all slots and queue places of the invocation.
+func (a *admission) release() {
+	for _, l := range a.acquired {
+		l.release()
+	}
+	for _, l := range a.queued {
+		<-l.queue
+	}
+	a.acquired, a.queued = nil, nil
+}
+
+//goLimited runs f in a new goroutine, if the limits of the unit and the worker admit it. Queued invocations wait in their goroutine until the run is stopped,
+//the caller waits under the block policy until wait is done. Returns false if the invocation was dropped.
+func (w *Worker) goLimited(wait context.Context, unit string, unitLimiter *ConcurrencyLimiter, f func()) bool {
+	stopped := w.stopped
+	a, droppedBy := admit(wait, unitLimiter, w.Limiter)
+	if droppedBy != nil {
+		//invocations which waited for a slot until the run was stopped aren't counted
+		if wait == nil || wait.Err() == nil {
+			w.drop(unit, droppedBy)
+		}
+		return false
+	}
+	go func() {
+		defer a.release()
+		//invocations which were still queued when the run was stopped aren't started
+		if a.await(stopped) {
+			f()
+		}
+	}()
+	return true
+}
+
+//invokeLimited runs f in the calling goroutine, once it holds the slots of the unit and the worker. It waits for free slots whatever the overload policy,
+//like a caller under the block policy, until wait is done. Returns false if f wasn't run.
+func (w *Worker) invokeLimited(wait context.Context, unitLimiter *ConcurrencyLimiter, f func()) bool {
+	a := &admission{}
+	for _, l := range []*ConcurrencyLimiter{unitLimiter, w.Limiter} {
+		if l != nil {
+			a.limiters = append(a.limiters, l)
+		}
+	}
+	defer a.release()
+	if !a.acquireAll(wait) {
+		return false
+	}
+	f()
+	return true
+}
+
+//drop counts an invocation of the unit, which was dropped by the limiter.
+func (w *Worker) drop(unit string, limiter *ConcurrencyLimiter) {
+	w.dropLock.Lock()
+	w.dropped[unit]++
+	w.dropLock.Unlock()
+	if w.DroppedInvocations != nil {
+		w.DroppedInvocations.With(prometheus.Labels{"unit": unit, "limit": limiter.Scope}).Inc()
+	}
+}
+
+//droppedInvocations returns a copy of the number of dropped invocations per unit in the current run.
+func (w *Worker) droppedInvocations() map[string]int64 {
+	w.dropLock.Lock()
+	defer w.dropLock.Unlock()
+	if len(w.dropped) == 0 {
+		return nil
+	}
+	dropped := make(map[string]int64, len(w.dropped))
+	for unit, count := range w.dropped {
+		dropped[unit] = count
+	}
+	return dropped
+}
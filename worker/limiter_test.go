@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+)
+
+func newQueueLimiter(t *testing.T, scope string, limit, queue int64) *ConcurrencyLimiter {
+	t.Helper()
+	l, err := NewConcurrencyLimiter(&api.Concurrency{Limit: limit, Policy: QueuePolicy, Queue: queue}, scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+//waitFor fails the test if done isn't closed within a few seconds, i.e. if invocations deadlocked.
+func waitFor(t *testing.T, done <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s didn't finish, invocations are deadlocked", what)
+	}
+}
+
+//TestQueuedInvocationsDontHoldSlots builds the situation in which one invocation holds the unit slot and waits for the worker slot, while another one holds
+//the worker slot and waits for the unit slot.
+func TestQueuedInvocationsDontHoldSlots(t *testing.T) {
+	unit := newQueueLimiter(t, "unit", 1, 10)
+	other := newQueueLimiter(t, "unit", 1, 10)
+	worker := newQueueLimiter(t, "worker", 1, 10)
+
+	running, dropped := admit(nil, other, worker)
+	if dropped != nil {
+		t.Fatalf("first invocation was dropped by %s limit", dropped.Scope)
+	}
+	first, dropped := admit(nil, unit, worker)
+	if dropped != nil || len(first.acquired) != 1 || len(first.queued) != 1 {
+		t.Fatalf("expected second invocation to hold the unit slot and wait for the worker slot, got %+v", first)
+	}
+	running.release()
+	second, dropped := admit(nil, unit, worker)
+	if dropped != nil || len(second.acquired) != 1 || second.acquired[0] != worker {
+		t.Fatalf("expected third invocation to hold the worker slot and wait for the unit slot, got %+v", second)
+	}
+
+	var wg sync.WaitGroup
+	for _, a := range []*admission{first, second} {
+		wg.Add(1)
+		go func(a *admission) {
+			defer wg.Done()
+			a.await(nil)
+			a.release()
+		}(a)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	waitFor(t, done, "queued invocations")
+	if len(unit.slots) != 0 || len(worker.slots) != 0 || len(unit.queue) != 0 || len(worker.queue) != 0 {
+		t.Errorf("slots or queue places weren't released: unit %d/%d, worker %d/%d", len(unit.slots), len(unit.queue), len(worker.slots), len(worker.queue))
+	}
+}
+
+func TestGoLimitedFinishesAllQueuedInvocations(t *testing.T) {
+	const invocations = 200
+	w := &Worker{Limiter: newQueueLimiter(t, "worker", 2, invocations), dropped: make(map[string]int64)}
+	units := []*ConcurrencyLimiter{newQueueLimiter(t, "unit", 1, invocations), newQueueLimiter(t, "unit", 1, invocations)}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	for i := 0; i < invocations; i++ {
+		wg.Add(1)
+		admitted := w.goLimited(nil, "unit", units[i%len(units)], func() {
+			defer wg.Done()
+			lock.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			lock.Unlock()
+			time.Sleep(100 * time.Microsecond)
+			lock.Lock()
+			running--
+			lock.Unlock()
+		})
+		if !admitted {
+			wg.Done()
+			t.Fatalf("invocation %d was dropped, queues should fit all invocations", i)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	waitFor(t, done, "invocations limited by unit and worker")
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 concurrent invocations, got %d", maxRunning)
+	}
+	if dropped := w.droppedInvocations(); dropped != nil {
+		t.Errorf("expected no dropped invocations, got %v", dropped)
+	}
+}
+
+func TestQueuedInvocationsStopWaitingWhenRunStops(t *testing.T) {
+	stopped, stop := context.WithCancel(context.Background())
+	w := &Worker{Limiter: newQueueLimiter(t, "worker", 1, 10), dropped: make(map[string]int64), stopped: stopped}
+	unit := newQueueLimiter(t, "unit", 1, 10)
+	running, finish := make(chan struct{}), make(chan struct{})
+	if !w.goLimited(nil, "unit", unit, func() {
+		close(running)
+		<-finish
+	}) {
+		t.Fatal("first invocation was dropped")
+	}
+	<-running
+	defer close(finish)
+	invoked := make(chan struct{}, 10)
+	for i := 0; i < 3; i++ {
+		if !w.goLimited(nil, "unit", unit, func() { invoked <- struct{}{} }) {
+			t.Fatalf("invocation %d was dropped, it should be queued", i)
+		}
+	}
+	stop()
+	//the queued invocations give up their queue places without running, while the first one still holds the slots
+	deadline := time.Now().Add(5 * time.Second)
+	for len(unit.queue) > 0 || len(w.Limiter.queue) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued invocations still wait after the run was stopped: unit queue %d, worker queue %d", len(unit.queue), len(w.Limiter.queue))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(invoked) > 0 {
+		t.Errorf("expected no queued invocation to run after the run was stopped, %d did", len(invoked))
+	}
+	if len(unit.slots) != 1 || len(w.Limiter.slots) != 1 {
+		t.Errorf("expected only the slots of the running invocation to be held, got unit %d, worker %d", len(unit.slots), len(w.Limiter.slots))
+	}
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"math"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+)
+
+//loadSchedule lets arrival processes follow a load profile. Arrival processes run at the reference throughput; an arrival at time u of the process happens
+//at the time t at which the profile has generated as many traces as the reference throughput in u seconds. This keeps the structure of constant and Poisson arrivals,
+//periods of other processes are shortened when the throughput is above the reference, and stretched when it is below.
+type loadSchedule struct {
+	points    []*api.LoadPoint
+	reference float64
+	//cumulative is the integral of the throughput up to each point.
+	cumulative []float64
+	//segment is the index of the point the last arrival was found after. Arrivals are monotonic, so the search continues from there.
+	segment int
+}
+
+func newLoadSchedule(profile *api.LoadProfile, reference float64) *loadSchedule {
+	points := profile.GetPoints()
+	if len(points) > 0 && points[0].OffsetSeconds > 0 {
+		points = append([]*api.LoadPoint{{OffsetSeconds: 0, Throughput: points[0].Throughput}}, points...)
+	}
+	s := &loadSchedule{
+		points:     points,
+		reference:  reference,
+		cumulative: make([]float64, len(points)),
+	}
+	for i := 1; i < len(points); i++ {
+		s.cumulative[i] = s.cumulative[i-1] + (points[i-1].Throughput+points[i].Throughput)/2*(points[i].OffsetSeconds-points[i-1].OffsetSeconds)
+	}
+	return s
+}
+
+//maxThroughput returns the highest throughput of the profile.
+func maxThroughput(profile *api.LoadProfile) float64 {
+	max := 0.0
+	for _, point := range profile.GetPoints() {
+		max = math.Max(max, point.Throughput)
+	}
+	return max
+}
+
+//offset returns the time since the start of load generation of an arrival at the given time of the arrival process. It returns false if the arrival
+//never happens, because the profile ends with a throughput of 0.
+func (s *loadSchedule) offset(arrival time.Duration) (time.Duration, bool) {
+	if len(s.points) == 0 {
+		return arrival, true
+	}
+	traces := arrival.Seconds() * s.reference
+	for ; s.segment < len(s.points)-1; s.segment++ {
+		if traces <= s.cumulative[s.segment+1] {
+			from, to := s.points[s.segment], s.points[s.segment+1]
+			length := to.OffsetSeconds - from.OffsetSeconds
+			if length <= 0 {
+				continue
+			}
+			slope := (to.Throughput - from.Throughput) / length
+			return seconds(from.OffsetSeconds + solveLinearRamp(from.Throughput, slope, traces-s.cumulative[s.segment])), true
+		}
+	}
+	last := s.points[len(s.points)-1]
+	if last.Throughput <= 0 {
+		return 0, false
+	}
+	return seconds(last.OffsetSeconds + (traces-s.cumulative[len(s.points)-1])/last.Throughput), true
+}
+
+//solveLinearRamp returns the time x after which a throughput starting at a and changing by slope per second has generated the given number of traces,
+//i.e. solves a*x + slope/2*x^2 = traces.
+func solveLinearRamp(a, slope, traces float64) float64 {
+	if traces <= 0 {
+		return 0
+	}
+	if math.Abs(slope) < 1e-12 {
+		return traces / a
+	}
+	return 2 * traces / (a + math.Sqrt(math.Max(0, a*a+2*slope*traces)))
+}
@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"math"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/opentracing/opentracing-go"
+)
+
+func testProfile(points ...float64) *api.LoadProfile {
+	profile := &api.LoadProfile{}
+	for i := 0; i < len(points); i += 2 {
+		profile.Points = append(profile.Points, &api.LoadPoint{OffsetSeconds: points[i], Throughput: points[i+1]})
+	}
+	return profile
+}
+
+func TestLoadScheduleFollowsIntegralOfThroughput(t *testing.T) {
+	//ramp from 10 to 100 traces per second, hold, jump down to 20 and hold until the end
+	profile := testProfile(0, 10, 30, 100, 40, 100, 40, 20, 60, 20)
+	for _, reference := range []float64{10, 50, 100} {
+		schedule := newLoadSchedule(profile, reference)
+		for u := 0.1; u < 100; u += 0.7 {
+			offset, ok := schedule.offset(seconds(u))
+			if !ok {
+				t.Fatalf("reference %.0f: expected arrival at %.1fs of the process to happen", reference, u)
+			}
+			//the profile generates as many traces until the offset, as the reference throughput until the arrival of the process
+			if traces := profile.Integral(offset.Seconds()); math.Abs(traces-u*reference) > 0.01 {
+				t.Errorf("reference %.0f: arrival at %.1fs of the process happens at %v, after %.3f instead of %.3f traces", reference, u, offset, traces, u*reference)
+			}
+		}
+	}
+}
+
+func TestLoadScheduleKeepsRateOfArrivals(t *testing.T) {
+	profile := testProfile(0, 0, 10, 200, 20, 200)
+	schedule := newLoadSchedule(profile, 100)
+	times := arrivals(t, &api.Arrival{Type: "poisson"}, 100, 3, 5000)
+	counts := make([]int, 20)
+	for _, arrival := range times {
+		offset, _ := schedule.offset(arrival)
+		if offset >= 20*time.Second {
+			break
+		}
+		counts[int(offset.Seconds())]++
+	}
+	for second, count := range counts {
+		expected := profile.Integral(float64(second+1)) - profile.Integral(float64(second))
+		if math.Abs(float64(count)-expected) > 4*math.Sqrt(expected)+1 {
+			t.Errorf("expected about %.0f arrivals in second %d, got %d", expected, second, count)
+		}
+	}
+}
+
+func TestLoadScheduleStartsAtFirstThroughput(t *testing.T) {
+	//the profile starts 10 seconds after the start of load generation with its first throughput
+	schedule := newLoadSchedule(testProfile(10, 50, 20, 50), 25)
+	if offset, ok := schedule.offset(5 * time.Second); !ok || offset != 2500*time.Millisecond {
+		t.Errorf("expected arrival after 2.5s, got %v", offset)
+	}
+}
+
+func TestLoadScheduleEndsWithoutThroughput(t *testing.T) {
+	schedule := newLoadSchedule(testProfile(0, 10, 10, 10, 10, 0), 10)
+	if offset, ok := schedule.offset(9 * time.Second); !ok || offset != 9*time.Second {
+		t.Errorf("expected arrival after 9s, got %v", offset)
+	}
+	if _, ok := schedule.offset(11 * time.Second); ok {
+		t.Errorf("expected no arrivals after the throughput dropped to 0")
+	}
+}
+
+func TestLoadScheduleWithoutPoints(t *testing.T) {
+	schedule := newLoadSchedule(&api.LoadProfile{}, 10)
+	if offset, ok := schedule.offset(3 * time.Second); !ok || offset != 3*time.Second {
+		t.Errorf("expected arrivals to be unchanged, got %v", offset)
+	}
+}
+
+func TestGeneratorShardsFollowPeakOfProfile(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	config := &api.Unit{Identifier: "root", ThroughputRatio: 1}
+	unit, err := CreateUnitExecutorFromConfig(config, seededWorker(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		profile *api.LoadProfile
+		shards  int
+	}{
+		//ramp from the reference of 1000 traces per second to 100000
+		{testProfile(0, 1000, 60, 100000), 5},
+		{testProfile(0, 1000, 60, 15000), 1},
+		{nil, 1},
+	} {
+		generator, err := NewOpenTracingUnitSpanGenerator(unit, config, test.profile, "frontend", opentracing.NoopTracer{}, 1000, 1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shards := len(generator.(*OpenTracingUnitSpanGenerator).Shards); shards != test.shards {
+			t.Errorf("%v: expected %d shards, got %d", test.profile, test.shards, shards)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/dominik-/t-race/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+//localBufferSize is the buffer size of in-memory connections in bytes.
+const localBufferSize = 1024 * 1024
+
+//Address prefixes of in-memory listeners. The service name is appended.
+const (
+	localBenchmarkPrefix = "bufconn/benchmark/"
+	localServicePrefix   = "bufconn/service/"
+)
+
+//LocalCluster runs one worker per service inside the current process. Benchmark and service endpoints of the workers are in-memory gRPC connections instead of TCP ports,
+//so the coordinator and workers communicate the same way as in a distributed setup, using the addresses and DialOption of the cluster.
+type LocalCluster struct {
+	Workers map[string]*Worker
+	//listeners are replaced when a worker prepares a run, since releasing the previous run closes its service listener.
+	listeners     map[string]*bufconn.Listener
+	listenersLock sync.RWMutex
+	servers       []*grpc.Server
+}
+
+//NewLocalCluster creates and starts a worker for each of the given services.
+func NewLocalCluster(services []string, samplingType string, samplingParam float64) *LocalCluster {
+	cluster := &LocalCluster{
+		Workers:   make(map[string]*Worker, len(services)),
+		listeners: make(map[string]*bufconn.Listener, 2*len(services)),
+		servers:   make([]*grpc.Server, 0, len(services)),
+	}
+	for _, svc := range services {
+		benchmarkListener := bufconn.Listen(localBufferSize)
+		cluster.listeners[cluster.BenchmarkAddress(svc)] = benchmarkListener
+		w := &Worker{
+			ServiceListen:    cluster.listen(cluster.ServiceAddress(svc)),
+			DialOptions:      []grpc.DialOption{cluster.DialOption()},
+			SamplingStrategy: samplingType,
+			SamplingParams:   []float64{samplingParam},
+		}
+		server := grpc.NewServer()
+		api.RegisterBenchmarkWorkerServer(server, w)
+		go server.Serve(benchmarkListener)
+		cluster.Workers[svc] = w
+		cluster.servers = append(cluster.servers, server)
+	}
+	return cluster
+}
+
+//BenchmarkAddress returns the address of the benchmark endpoint of the worker for the given service.
+func (c *LocalCluster) BenchmarkAddress(service string) string {
+	return localBenchmarkPrefix + service
+}
+
+//ServiceAddress returns the address of the service endpoint of the worker for the given service.
+func (c *LocalCluster) ServiceAddress(service string) string {
+	return localServicePrefix + service
+}
+
+//DialOption has to be passed to grpc.Dial to connect to addresses of the cluster.
+func (c *LocalCluster) DialOption() grpc.DialOption {
+	return grpc.WithContextDialer(c.dial)
+}
+
+//listen returns a function which creates a new in-memory listener for the address, replacing the previous one.
+func (c *LocalCluster) listen(address string) func() (net.Listener, error) {
+	return func() (net.Listener, error) {
+		listener := bufconn.Listen(localBufferSize)
+		c.listenersLock.Lock()
+		c.listeners[address] = listener
+		c.listenersLock.Unlock()
+		return listener, nil
+	}
+}
+
+func (c *LocalCluster) dial(ctx context.Context, address string) (net.Conn, error) {
+	//depending on the resolver, the address might still contain the scheme
+	address = strings.TrimPrefix(address, "passthrough:///")
+	c.listenersLock.RLock()
+	listener, exists := c.listeners[address]
+	c.listenersLock.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("no local worker listens on %s", address)
+	}
+	return listener.DialContext(ctx)
+}
+
+//Stop stops the benchmark endpoints of all workers.
+func (c *LocalCluster) Stop() {
+	for _, server := range c.servers {
+		server.Stop()
+	}
+}
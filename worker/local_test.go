@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"google.golang.org/grpc"
+)
+
+//callLocal invokes a unit through the service endpoint of a worker of the cluster, like a local predecessor does.
+func callLocal(t *testing.T, cluster *LocalCluster, service, unit string) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, cluster.ServiceAddress(service), grpc.WithInsecure(), cluster.DialOption())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = api.NewBenchmarkWorkerClient(conn).Call(ctx, &api.DispatchId{UnitReference: unit})
+	return err
+}
+
+func TestLocalClusterServesSeveralRuns(t *testing.T) {
+	cluster := NewLocalCluster([]string{"backend"}, "const", 1)
+	defer cluster.Stop()
+	w := cluster.Workers["backend"]
+	config := &api.WorkerConfiguration{
+		WorkerId:       "worker-backend",
+		ServiceName:    "backend",
+		SinkProvider:   "jaeger",
+		SinkHostPort:   "localhost:6831",
+		RuntimeSeconds: 1,
+		Units:          []*api.Unit{{Identifier: "b"}},
+	}
+	//a prepared run is replaced by the next Prepare, an aborted one stays released until then
+	for i, next := range []func(){
+		func() {},
+		func() {},
+		func() { w.Stop(context.Background(), &api.StopRequest{Abort: true, Reason: "test"}) },
+	} {
+		next()
+		if _, err := w.Prepare(context.Background(), config); err != nil {
+			t.Fatalf("run %d: %v", i+1, err)
+		}
+		if err := callLocal(t, cluster, "backend", "b"); err != nil {
+			t.Fatalf("run %d: expected the service endpoint to serve calls, got %v", i+1, err)
+		}
+	}
+	w.Stop(context.Background(), &api.StopRequest{Abort: true, Reason: "test"})
+	if err := callLocal(t, cluster, "backend", "b"); err == nil {
+		t.Errorf("expected the service endpoint to be closed after the run was released")
+	}
+}
+
+func TestCallsWhilePreparing(t *testing.T) {
+	cluster := NewLocalCluster([]string{"backend"}, "const", 1)
+	defer cluster.Stop()
+	w := cluster.Workers["backend"]
+	config := &api.WorkerConfiguration{
+		WorkerId:     "worker-backend",
+		ServiceName:  "backend",
+		SinkProvider: "jaeger",
+		SinkHostPort: "localhost:6831",
+		Units:        []*api.Unit{{Identifier: "a"}, {Identifier: "b"}, {Identifier: "c"}},
+	}
+	if _, err := w.Prepare(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	//predecessors keep calling, while the next Prepare replaces the service endpoint and the units. Calls fail until the units are complete.
+	conn, err := grpc.Dial(cluster.ServiceAddress("backend"), grpc.WithInsecure(), cluster.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := api.NewBenchmarkWorkerClient(conn)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				client.Call(ctx, &api.DispatchId{UnitReference: "b"})
+				cancel()
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		if _, err := w.Prepare(context.Background(), config); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(done)
+	<-finished
+	if err := callLocal(t, cluster, "backend", "b"); err != nil {
+		t.Errorf("expected the unit of the last run to be called, got %v", err)
+	}
+	if err := callLocal(t, cluster, "backend", "d"); err == nil {
+		t.Errorf("expected an error for an unknown unit")
+	}
+}
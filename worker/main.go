@@ -6,25 +6,30 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/dominik-/t-race/api"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 )
 
-func StartWorkerProcess(benchmarkPort, servicePort, prometheusPort int, exportPrometheus bool, samplingType string, samplingParam float64) chan bool {
+func StartWorkerProcess(benchmarkPort, servicePort, prometheusPort int, exportPrometheus bool, samplingType string, samplingParam float64, callTimeout time.Duration) chan bool {
 	listenerBenchmark, err := net.Listen("tcp", fmt.Sprintf(":%d", benchmarkPort))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
+	//each worker has its own registry, so several workers can run in one process
+	registry := prometheus.NewRegistry()
 	if exportPrometheus {
 		//TODO this listener is never closed
 		listenerHTTPPrometheus, err := net.Listen("tcp", fmt.Sprintf(":%d", prometheusPort))
 		if err != nil {
 			log.Fatalf("failed to listen: %v", err)
 		}
-		http.Handle("/metrics", promhttp.Handler())
-		go http.Serve(listenerHTTPPrometheus, nil)
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		go http.Serve(listenerHTTPPrometheus, mux)
 	}
 	/* // Read cert and key file
 	backendCert, err := ioutil.ReadFile("/certs/tls.crt")
@@ -50,6 +55,8 @@ func StartWorkerProcess(benchmarkPort, servicePort, prometheusPort int, exportPr
 		ServicePort:      servicePort,
 		SamplingStrategy: samplingType,
 		SamplingParams:   []float64{samplingParam},
+		CallTimeout:      callTimeout,
+		MetricsRegistry:  registry,
 	})
 	go server.Serve(listenerBenchmark)
 	//wait for external signal to shut down
@@ -0,0 +1,372 @@
+package worker
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var propagationFormatRegistry map[string]PropagationFormat
+
+func init() {
+	propagationFormatRegistry = make(map[string]PropagationFormat)
+	propagationFormatRegistry["uber"] = &UberPropagation{}
+	propagationFormatRegistry["w3c"] = &W3CPropagation{}
+	propagationFormatRegistry["b3"] = &B3SinglePropagation{}
+	propagationFormatRegistry["b3multi"] = &B3MultiPropagation{}
+	//aliases, as used by the OpenTelemetry SDKs.
+	propagationFormatRegistry["jaeger"] = propagationFormatRegistry["uber"]
+	propagationFormatRegistry["tracecontext"] = propagationFormatRegistry["w3c"]
+}
+
+//ErrNoPropagatedContext is returned by a PropagationFormat if headers don't contain a context in that format.
+var ErrNoPropagatedContext = errors.New("no propagated context found in headers")
+
+//PropagatedContext is the backend-neutral content of propagation headers. IDs are big endian, the TraceID is always 16 bytes long.
+type PropagatedContext struct {
+	TraceID    []byte
+	SpanID     []byte
+	ParentID   []byte
+	Sampled    bool
+	TraceState string
+}
+
+//PropagationFormat reads and writes the trace context headers of a specific propagation format. Baggage is not part of the format.
+type PropagationFormat interface {
+	Inject(*PropagatedContext, http.Header)
+	Extract(http.Header) (*PropagatedContext, error)
+	//Clear removes all trace context headers of this format.
+	Clear(http.Header)
+}
+
+//LookupPropagationFormat returns the propagation format registered with the given name.
+func LookupPropagationFormat(name string) (PropagationFormat, error) {
+	format, exists := propagationFormatRegistry[strings.ToLower(name)]
+	if !exists {
+		return nil, fmt.Errorf("unknown propagation format %s", name)
+	}
+	return format, nil
+}
+
+//PropagationFormatNames returns the names of all registered propagation formats, sorted by name.
+func PropagationFormatNames() []string {
+	names := make([]string, 0, len(propagationFormatRegistry))
+	for name := range propagationFormatRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//ContextPropagator converts between the native propagation format of a tracer backend and the propagation format configured for a service.
+//Only the configured format is understood on incoming calls, i.e. context is lost between services with different formats.
+type ContextPropagator struct {
+	Native PropagationFormat
+	Format PropagationFormat
+}
+
+//NewContextPropagator creates a ContextPropagator for the given format name. An empty name keeps the native format of the backend.
+func NewContextPropagator(backend TracerBackend, format string) (*ContextPropagator, error) {
+	native := backend.NativePropagation()
+	if format == "" {
+		return &ContextPropagator{Native: native, Format: native}, nil
+	}
+	configured, err := LookupPropagationFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return &ContextPropagator{Native: native, Format: configured}, nil
+}
+
+//FromNative converts headers injected by the tracer to the configured format.
+func (p *ContextPropagator) FromNative(header http.Header) http.Header {
+	if p.Native == p.Format {
+		return header
+	}
+	ctx, err := p.Native.Extract(header)
+	p.Native.Clear(header)
+	if err == nil {
+		p.Format.Inject(ctx, header)
+	}
+	return header
+}
+
+//ToNative converts incoming headers in the configured format to the native format of the tracer. Native headers not in the configured format are dropped.
+func (p *ContextPropagator) ToNative(header http.Header) http.Header {
+	if p.Native == p.Format {
+		return header
+	}
+	ctx, err := p.Format.Extract(header)
+	p.Format.Clear(header)
+	p.Native.Clear(header)
+	if err == nil {
+		p.Native.Inject(ctx, header)
+	}
+	return header
+}
+
+//headerSize returns the number of bytes of all headers, i.e. trace context and baggage, as they are sent to the next service.
+func headerSize(header http.Header) int {
+	size := 0
+	for k, vals := range header {
+		for _, v := range vals {
+			size += len(k) + len(v)
+		}
+	}
+	return size
+}
+
+//decodeID decodes a hex ID and left-pads it to the given length in bytes, e.g. for 64bit trace IDs.
+func decodeID(id string, length int) ([]byte, error) {
+	if len(id)%2 == 1 {
+		id = "0" + id
+	}
+	decoded, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) > length {
+		return nil, fmt.Errorf("id %s is longer than %d bytes", id, length)
+	}
+	padded := make([]byte, length)
+	copy(padded[length-len(decoded):], decoded)
+	return padded, nil
+}
+
+func isZeroID(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+const uberTraceIDHeader = "uber-trace-id"
+
+//UberPropagation is the propagation format of jaeger, i.e. the uber-trace-id header.
+type UberPropagation struct {
+}
+
+func (f *UberPropagation) Inject(ctx *PropagatedContext, header http.Header) {
+	parentID := "0"
+	if len(ctx.ParentID) > 0 {
+		parentID = hex.EncodeToString(ctx.ParentID)
+	}
+	flags := 0
+	if ctx.Sampled {
+		flags = int(flagSampled)
+	}
+	header.Set(uberTraceIDHeader, fmt.Sprintf("%s:%s:%s:%x", hex.EncodeToString(ctx.TraceID), hex.EncodeToString(ctx.SpanID), parentID, flags))
+}
+
+func (f *UberPropagation) Extract(header http.Header) (*PropagatedContext, error) {
+	value := header.Get(uberTraceIDHeader)
+	if value == "" {
+		return nil, ErrNoPropagatedContext
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("malformed %s header: %s", uberTraceIDHeader, value)
+	}
+	traceID, err := decodeID(parts[0], 16)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := decodeID(parts[1], 8)
+	if err != nil {
+		return nil, err
+	}
+	parentID, err := decodeID(parts[2], 8)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &PropagatedContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: byte(flags)&flagSampled == flagSampled,
+	}
+	if !isZeroID(parentID) {
+		ctx.ParentID = parentID
+	}
+	return ctx, nil
+}
+
+func (f *UberPropagation) Clear(header http.Header) {
+	header.Del(uberTraceIDHeader)
+}
+
+const (
+	traceParentHeader = "traceparent"
+	traceStateHeader  = "tracestate"
+)
+
+//W3CPropagation is the W3C Trace Context format, i.e. the traceparent and tracestate headers.
+type W3CPropagation struct {
+}
+
+func (f *W3CPropagation) Inject(ctx *PropagatedContext, header http.Header) {
+	flags := byte(0)
+	if ctx.Sampled {
+		flags = flagSampled
+	}
+	header.Set(traceParentHeader, fmt.Sprintf("00-%s-%s-%02x", hex.EncodeToString(ctx.TraceID), hex.EncodeToString(ctx.SpanID), flags))
+	if ctx.TraceState != "" {
+		header.Set(traceStateHeader, ctx.TraceState)
+	}
+}
+
+func (f *W3CPropagation) Extract(header http.Header) (*PropagatedContext, error) {
+	value := header.Get(traceParentHeader)
+	if value == "" {
+		return nil, ErrNoPropagatedContext
+	}
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return nil, fmt.Errorf("malformed %s header: %s", traceParentHeader, value)
+	}
+	traceID, err := decodeID(parts[1], 16)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := decodeID(parts[2], 8)
+	if err != nil {
+		return nil, err
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return nil, err
+	}
+	return &PropagatedContext{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		Sampled:    byte(flags)&flagSampled == flagSampled,
+		TraceState: header.Get(traceStateHeader),
+	}, nil
+}
+
+func (f *W3CPropagation) Clear(header http.Header) {
+	header.Del(traceParentHeader)
+	header.Del(traceStateHeader)
+}
+
+const b3SingleHeader = "b3"
+
+//B3SinglePropagation is the single header format of zipkin B3, i.e. b3: {traceid}-{spanid}-{sampled}-{parentid}.
+type B3SinglePropagation struct {
+}
+
+func (f *B3SinglePropagation) Inject(ctx *PropagatedContext, header http.Header) {
+	sampled := "0"
+	if ctx.Sampled {
+		sampled = "1"
+	}
+	value := hex.EncodeToString(ctx.TraceID) + "-" + hex.EncodeToString(ctx.SpanID) + "-" + sampled
+	if len(ctx.ParentID) > 0 {
+		value += "-" + hex.EncodeToString(ctx.ParentID)
+	}
+	header.Set(b3SingleHeader, value)
+}
+
+func (f *B3SinglePropagation) Extract(header http.Header) (*PropagatedContext, error) {
+	value := header.Get(b3SingleHeader)
+	if value == "" {
+		return nil, ErrNoPropagatedContext
+	}
+	parts := strings.Split(value, "-")
+	//a single sampling flag (b3: 0) carries no context
+	if len(parts) < 2 || len(parts) > 4 {
+		return nil, ErrNoPropagatedContext
+	}
+	traceID, err := decodeID(parts[0], 16)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := decodeID(parts[1], 8)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &PropagatedContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}
+	if len(parts) > 2 {
+		ctx.Sampled = parts[2] == "1" || parts[2] == "d"
+	}
+	if len(parts) > 3 {
+		if ctx.ParentID, err = decodeID(parts[3], 8); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+func (f *B3SinglePropagation) Clear(header http.Header) {
+	header.Del(b3SingleHeader)
+}
+
+const (
+	b3TraceIDHeader      = "x-b3-traceid"
+	b3SpanIDHeader       = "x-b3-spanid"
+	b3ParentSpanIDHeader = "x-b3-parentspanid"
+	b3SampledHeader      = "x-b3-sampled"
+	b3FlagsHeader        = "x-b3-flags"
+)
+
+//B3MultiPropagation is the multi header format of zipkin B3, i.e. the X-B3-* headers.
+type B3MultiPropagation struct {
+}
+
+func (f *B3MultiPropagation) Inject(ctx *PropagatedContext, header http.Header) {
+	header.Set(b3TraceIDHeader, hex.EncodeToString(ctx.TraceID))
+	header.Set(b3SpanIDHeader, hex.EncodeToString(ctx.SpanID))
+	if len(ctx.ParentID) > 0 {
+		header.Set(b3ParentSpanIDHeader, hex.EncodeToString(ctx.ParentID))
+	}
+	if ctx.Sampled {
+		header.Set(b3SampledHeader, "1")
+	} else {
+		header.Set(b3SampledHeader, "0")
+	}
+}
+
+func (f *B3MultiPropagation) Extract(header http.Header) (*PropagatedContext, error) {
+	if header.Get(b3TraceIDHeader) == "" || header.Get(b3SpanIDHeader) == "" {
+		return nil, ErrNoPropagatedContext
+	}
+	traceID, err := decodeID(header.Get(b3TraceIDHeader), 16)
+	if err != nil {
+		return nil, err
+	}
+	spanID, err := decodeID(header.Get(b3SpanIDHeader), 8)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &PropagatedContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: header.Get(b3SampledHeader) == "1" || header.Get(b3SampledHeader) == "true" || header.Get(b3FlagsHeader) == "1",
+	}
+	if parentID := header.Get(b3ParentSpanIDHeader); parentID != "" {
+		if ctx.ParentID, err = decodeID(parentID, 8); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+func (f *B3MultiPropagation) Clear(header http.Header) {
+	header.Del(b3TraceIDHeader)
+	header.Del(b3SpanIDHeader)
+	header.Del(b3ParentSpanIDHeader)
+	header.Del(b3SampledHeader)
+	header.Del(b3FlagsHeader)
+}
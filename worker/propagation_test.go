@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func testContext(sampled bool) *PropagatedContext {
+	return &PropagatedContext{
+		TraceID:    []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		ParentID:   []byte{0x05, 0xe3, 0xac, 0x9a, 0x4f, 0x6e, 0x3b, 0x90},
+		Sampled:    sampled,
+		TraceState: "vendor=value",
+	}
+}
+
+func TestPropagationRoundTrip(t *testing.T) {
+	for _, name := range []string{"uber", "w3c", "b3", "b3multi"} {
+		format, err := LookupPropagationFormat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sampled := range []bool{true, false} {
+			ctx := testContext(sampled)
+			header := make(http.Header)
+			format.Inject(ctx, header)
+			actual, err := format.Extract(header)
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+				continue
+			}
+			//the parent is only part of uber and B3 headers, the trace state only of W3C headers
+			expected := *ctx
+			if name == "w3c" {
+				expected.ParentID = nil
+			} else {
+				expected.TraceState = ""
+			}
+			if !reflect.DeepEqual(*actual, expected) {
+				t.Errorf("%s: expected %+v, got %+v", name, expected, *actual)
+			}
+			format.Clear(header)
+			if len(header) != 0 {
+				t.Errorf("%s: expected no headers after Clear, got %v", name, header)
+			}
+		}
+	}
+}
+
+func TestPropagationExtractsShortTraceIDs(t *testing.T) {
+	expected := append(make([]byte, 8), 0, 0, 0, 0, 0, 0, 0x0a, 0xbc)
+	for name, header := range map[string]http.Header{
+		"uber":    {"Uber-Trace-Id": {"abc:def:0:1"}},
+		"b3":      {"B3": {"0000000000000abc-0000000000000def-1"}},
+		"b3multi": {"X-B3-Traceid": {"abc"}, "X-B3-Spanid": {"def"}, "X-B3-Sampled": {"1"}},
+	} {
+		format, _ := LookupPropagationFormat(name)
+		ctx, err := format.Extract(header)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(ctx.TraceID, expected) || !bytes.Equal(ctx.SpanID, []byte{0, 0, 0, 0, 0, 0, 0x0d, 0xef}) || !ctx.Sampled || ctx.ParentID != nil {
+			t.Errorf("%s: expected padded 64bit IDs without parent, got %+v", name, ctx)
+		}
+	}
+}
+
+func TestPropagationExtractErrors(t *testing.T) {
+	for name, header := range map[string]http.Header{
+		"uber":    {"Uber-Trace-Id": {"abc:def:1"}},
+		"w3c":     {"Traceparent": {"00-4bf92f3577b34da6-00f067aa0ba902b7-01"}},
+		"b3multi": {"X-B3-Traceid": {"xyz"}, "X-B3-Spanid": {"def"}},
+	} {
+		format, _ := LookupPropagationFormat(name)
+		if _, err := format.Extract(make(http.Header)); !errors.Is(err, ErrNoPropagatedContext) {
+			t.Errorf("%s: expected ErrNoPropagatedContext for empty headers, got %v", name, err)
+		}
+		if _, err := format.Extract(header); err == nil || errors.Is(err, ErrNoPropagatedContext) {
+			t.Errorf("%s: expected an error for malformed headers, got %v", name, err)
+		}
+	}
+	//a single sampling decision carries no context
+	b3, _ := LookupPropagationFormat("b3")
+	if _, err := b3.Extract(http.Header{"B3": {"0"}}); !errors.Is(err, ErrNoPropagatedContext) {
+		t.Errorf("b3: expected ErrNoPropagatedContext for a sampling decision, got %v", err)
+	}
+}
+
+func TestLookupPropagationFormatAliases(t *testing.T) {
+	for alias, name := range map[string]string{"jaeger": "uber", "tracecontext": "w3c", "B3Multi": "b3multi"} {
+		format, err := LookupPropagationFormat(alias)
+		expected, _ := LookupPropagationFormat(name)
+		if err != nil || format != expected {
+			t.Errorf("expected %s to be an alias of %s, got %v", alias, name, err)
+		}
+	}
+	if _, err := LookupPropagationFormat("xray"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func newTestPropagator(native, format string) *ContextPropagator {
+	n, _ := LookupPropagationFormat(native)
+	f, _ := LookupPropagationFormat(format)
+	return &ContextPropagator{Native: n, Format: f}
+}
+
+func TestContextPropagatorConvertsFormats(t *testing.T) {
+	ctx := testContext(true)
+	ctx.TraceState = ""
+	header := http.Header{"Uberctx-User": {"alice"}}
+	(&UberPropagation{}).Inject(ctx, header)
+
+	outgoing := newTestPropagator("uber", "b3multi").FromNative(header)
+	if outgoing.Get(uberTraceIDHeader) != "" || outgoing.Get(b3TraceIDHeader) == "" {
+		t.Fatalf("expected native headers to be replaced by B3 headers, got %v", outgoing)
+	}
+	if outgoing.Get("uberctx-user") != "alice" {
+		t.Errorf("expected baggage to be kept, got %v", outgoing)
+	}
+
+	incoming := newTestPropagator("uber", "b3multi").ToNative(outgoing)
+	if incoming.Get(b3TraceIDHeader) != "" {
+		t.Errorf("expected B3 headers to be removed, got %v", incoming)
+	}
+	actual, err := (&UberPropagation{}).Extract(incoming)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, ctx) {
+		t.Errorf("expected context %+v after converting back to native headers, got %+v", ctx, actual)
+	}
+}
+
+func TestContextPropagatorKeepsNativeFormat(t *testing.T) {
+	header := make(http.Header)
+	(&W3CPropagation{}).Inject(testContext(true), header)
+	expected := header.Clone()
+	p := newTestPropagator("w3c", "tracecontext")
+	if !reflect.DeepEqual(p.FromNative(header), expected) || !reflect.DeepEqual(p.ToNative(header), expected) {
+		t.Errorf("expected headers in the native format to be unchanged, got %v", header)
+	}
+}
+
+func TestContextPropagatorLosesContextOfOtherFormats(t *testing.T) {
+	//the caller is configured to propagate B3, the callee to propagate W3C, both use a backend with uber headers
+	header := make(http.Header)
+	(&UberPropagation{}).Inject(testContext(true), header)
+	outgoing := newTestPropagator("uber", "b3").FromNative(header)
+	//native headers, which a proxy or client library adds besides the configured format, are dropped as well
+	(&UberPropagation{}).Inject(testContext(true), outgoing)
+
+	incoming := newTestPropagator("uber", "w3c").ToNative(outgoing)
+	if _, err := (&UberPropagation{}).Extract(incoming); !errors.Is(err, ErrNoPropagatedContext) {
+		t.Errorf("expected the context to be lost, got %v in %v", err, incoming)
+	}
+}
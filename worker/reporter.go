@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"log"
 	"sync"
 
 	"github.com/dominik-/t-race/api"
@@ -14,14 +15,21 @@ type ResultReporter interface {
 	Report()
 }
 
+//ResultStream is the server stream of results to the coordinator, i.e. of the StartWorker or Start RPC.
+type ResultStream interface {
+	Send(*api.ResultPackage) error
+}
+
 type BufferingReporter struct {
 	resultBuffer []*api.Result
 	lock         sync.Mutex
 	size         int
-	target       api.BenchmarkWorker_StartWorkerServer
+	target       ResultStream
+	reported     int64
 }
 
-func NewBufferingReporter(target api.BenchmarkWorker_StartWorkerServer, bufferSize int) *BufferingReporter {
+//NewBufferingReporter creates a reporter which sends results to target. If target is nil, results are buffered until a target is set with SetTarget.
+func NewBufferingReporter(target ResultStream, bufferSize int) *BufferingReporter {
 	if bufferSize == 0 || bufferSize < 0 {
 		bufferSize = 1000
 	}
@@ -38,15 +46,49 @@ func (r *BufferingReporter) Collect(result *api.Result) {
 	defer r.lock.Unlock()
 	r.resultBuffer = append(r.resultBuffer, result)
 	if len(r.resultBuffer) > r.size {
-		r.Report()
+		r.report()
 	}
 }
 
 func (r *BufferingReporter) Report() {
 	r.lock.Lock()
 	defer r.lock.Unlock()
-	r.target.Send(&api.ResultPackage{
+	r.report()
+}
+
+//report sends the buffer to the target; the lock must be held by the caller.
+func (r *BufferingReporter) report() {
+	if r.target == nil || len(r.resultBuffer) == 0 {
+		return
+	}
+	err := r.target.Send(&api.ResultPackage{
 		Results: r.resultBuffer,
 	})
+	if err != nil {
+		log.Printf("Couldn't send %d results to coordinator: %v", len(r.resultBuffer), err)
+	} else {
+		r.reported += int64(len(r.resultBuffer))
+	}
 	r.resultBuffer = make([]*api.Result, 0)
 }
+
+//SetTarget sets the stream results are sent to. A nil target buffers results until a new target is set.
+func (r *BufferingReporter) SetTarget(target ResultStream) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.target = target
+}
+
+//Discard drops all buffered results, e.g. if a run is aborted.
+func (r *BufferingReporter) Discard() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.resultBuffer = make([]*api.Result, 0)
+}
+
+//Reported returns the number of results sent to targets successfully.
+func (r *BufferingReporter) Reported() int64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.reported
+}
@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+//deriveSeed derives an independent seed from a seed and names, e.g. of a service and unit. The same seed and names always derive the same seed.
+func deriveSeed(seed int64, names ...string) int64 {
+	hash := fnv.New64a()
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(seed))
+	hash.Write(b[:])
+	for _, name := range names {
+		//names are terminated, so "ab","c" and "a","bc" derive different seeds
+		hash.Write([]byte(name))
+		hash.Write([]byte{0})
+	}
+	return int64(hash.Sum64())
+}
+
+//unitSeed returns the seed of a unit of the current run for a purpose, e.g. its work times. Seeds of different purposes are independent,
+//so e.g. adding a random tag to a unit doesn't change its work times.
+func (w *Worker) unitSeed(unit, purpose string) int64 {
+	return deriveSeed(w.seed, w.Config.ServiceName, unit, purpose)
+}
@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+)
+
+func seededUnit(id string) *api.Unit {
+	return &api.Unit{
+		Identifier: id,
+		WorkBefore: &api.Work{DistType: "lognormal", Parameters: map[string]float64{"mean": 1000, "stddev": 500}},
+		Context: &api.ContextTemplate{
+			Tags:    []*api.KeyValueTemplate{{KeyLength: 6, ValueLength: 12}, {KeyStatic: "clientId", ValueLength: 8}},
+			Baggage: []*api.KeyValueTemplate{{KeyStatic: "session", ValueLength: 16}},
+			Logs:    []*api.KeyValueTemplate{{KeyLength: 4, ValueStatic: "static"}},
+		},
+	}
+}
+
+func seededWorker(seed int64) *Worker {
+	return &Worker{Config: &api.WorkerConfiguration{ServiceName: "frontend"}, seed: seed}
+}
+
+//unitSample creates an executor for the unit and returns its context and the first work times.
+func unitSample(t *testing.T, w *Worker, unit *api.Unit) (*UnitExecutor, []time.Duration) {
+	t.Helper()
+	executor, err := CreateUnitExecutorFromConfig(unit, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples := make([]time.Duration, 100)
+	for i := range samples {
+		samples[i] = executor.WorkSampler.GetNextValue()
+	}
+	return executor, samples
+}
+
+func TestSameSeedRepeatsUnits(t *testing.T) {
+	first, firstSamples := unitSample(t, seededWorker(42), seededUnit("root"))
+	second, secondSamples := unitSample(t, seededWorker(42), seededUnit("root"))
+	if !reflect.DeepEqual(firstSamples, secondSamples) {
+		t.Errorf("expected same work times for the same seed")
+	}
+	if !reflect.DeepEqual(first.Tags, second.Tags) || !reflect.DeepEqual(first.Baggage, second.Baggage) || !reflect.DeepEqual(first.Logs, second.Logs) {
+		t.Errorf("expected same context for the same seed, got %v/%v/%v and %v/%v/%v", first.Tags, first.Baggage, first.Logs, second.Tags, second.Baggage, second.Logs)
+	}
+	if len(first.Tags) != 2 || len(first.Tags["clientId"]) != 8 || len(first.Baggage["session"]) != 16 {
+		t.Errorf("expected random tags and baggage of the configured lengths, got %v and %v", first.Tags, first.Baggage)
+	}
+}
+
+func TestDifferentUnitsAndSeedsGetDifferentStreams(t *testing.T) {
+	root, rootSamples := unitSample(t, seededWorker(42), seededUnit("root"))
+	for name, sample := range map[string]func() (*UnitExecutor, []time.Duration){
+		"other unit": func() (*UnitExecutor, []time.Duration) { return unitSample(t, seededWorker(42), seededUnit("other")) },
+		"other seed": func() (*UnitExecutor, []time.Duration) { return unitSample(t, seededWorker(7), seededUnit("root")) },
+		"other service": func() (*UnitExecutor, []time.Duration) {
+			w := seededWorker(42)
+			w.Config.ServiceName = "backend"
+			return unitSample(t, w, seededUnit("root"))
+		},
+	} {
+		executor, samples := sample()
+		if reflect.DeepEqual(rootSamples, samples) {
+			t.Errorf("%s: expected different work times", name)
+		}
+		if reflect.DeepEqual(root.Tags, executor.Tags) {
+			t.Errorf("%s: expected different tags, got %v for both", name, root.Tags)
+		}
+	}
+}
+
+func TestDeriveSeed(t *testing.T) {
+	if deriveSeed(1, "a", "b") != deriveSeed(1, "a", "b") {
+		t.Errorf("expected the same seed for the same names")
+	}
+	for _, other := range []int64{deriveSeed(2, "a", "b"), deriveSeed(1, "ab"), deriveSeed(1, "a", "b", "c"), deriveSeed(1, "b", "a")} {
+		if other == deriveSeed(1, "a", "b") {
+			t.Errorf("expected different seeds for different seeds or names")
+		}
+	}
+	w := seededWorker(42)
+	if w.unitSeed("root", "work") == w.unitSeed("root", "context") {
+		t.Errorf("expected independent seeds for different purposes")
+	}
+}
+
+func TestGenerateStringMapIsSeeded(t *testing.T) {
+	templates := seededUnit("root").Context.Tags
+	first := generateStringMap(templates, rand.New(rand.NewSource(3)))
+	second := generateStringMap(templates, rand.New(rand.NewSource(3)))
+	other := generateStringMap(templates, rand.New(rand.NewSource(4)))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected same values for the same seed, got %v and %v", first, second)
+	}
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("expected different values for different seeds, got %v for both", first)
+	}
+}
+
+func TestSameSeedRepeatsSamplersAndArrivals(t *testing.T) {
+	works := []*api.Work{
+		{DistType: "gaussian", Parameters: map[string]float64{"mean": 1000, "stddev": 100}},
+		{DistType: "exponential", Parameters: map[string]float64{"mean": 1000}},
+		{DistType: "pareto", Parameters: map[string]float64{"scale": 100, "shape": 2}},
+		{DistType: MixtureDistribution, Components: []*api.MixtureComponent{
+			{Weight: 1, Work: &api.Work{DistType: "uniform", Parameters: map[string]float64{"min": 0, "max": 100}}},
+			{Weight: 1, Work: &api.Work{DistType: "weibull", Parameters: map[string]float64{"scale": 100, "shape": 1.5}}},
+		}},
+	}
+	for _, work := range works {
+		sequences := make([][]time.Duration, 3)
+		for i, seed := range []int64{11, 11, 12} {
+			sampler, err := LookupDistribution(work)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sampler.SetRNGSeed(seed)
+			for j := 0; j < 50; j++ {
+				sequences[i] = append(sequences[i], sampler.GetNextValue())
+			}
+		}
+		if !reflect.DeepEqual(sequences[0], sequences[1]) || reflect.DeepEqual(sequences[0], sequences[2]) {
+			t.Errorf("%s: expected the same samples only for the same seed", work.DistType)
+		}
+	}
+	for _, arrivalType := range []string{"poisson", "mmpp"} {
+		arrival := &api.Arrival{Type: arrivalType}
+		if arrivalType == "mmpp" {
+			arrival.Parameters = map[string]float64{"factor": 5, "high": 0.1, "low": 0.2}
+		}
+		sequences := make([][]time.Duration, 3)
+		for i, seed := range []int64{11, 11, 12} {
+			process, err := NewArrivalProcess(arrival, 100, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var previous time.Duration
+			for j := 0; j < 50; j++ {
+				previous = process.NextArrival(previous)
+				sequences[i] = append(sequences[i], previous)
+			}
+		}
+		if !reflect.DeepEqual(sequences[0], sequences[1]) || reflect.DeepEqual(sequences[0], sequences[2]) {
+			t.Errorf("%s: expected the same arrivals only for the same seed", arrivalType)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"strings"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+//Above shardThroughput traces per second, root units with splittable arrival processes are generated by multiple goroutines, at most GOMAXPROCS.
+const shardThroughput = 20000
+
+//maxBatch limits the number of due arrivals a generator invokes before it checks for the stop signal again.
+const maxBatch = 1000
+
+//splittableArrivals are the arrival processes, which are the same as the superposition of n processes with 1/n of the rate, so they can be generated by multiple goroutines.
+var splittableArrivals = map[string]bool{
+	"constant": true,
+	"poisson":  true,
+}
+
+//NewArrivalShards creates the arrival processes of a root unit with the given mean rate. High rates of splittable processes are split into several processes,
+//which are generated concurrently, all other processes return a single process. The number of shards follows peak, the highest rate the unit reaches, which
+//is above the mean rate if a load profile raises it. The seeds of shards are derived from the seed.
+func NewArrivalShards(arrival *api.Arrival, rate, peak float64, seed int64) ([]ArrivalProcess, error) {
+	process, err := NewArrivalProcess(arrival, rate, seed)
+	if err != nil {
+		return nil, err
+	}
+	arrivalType := DefaultArrivalType
+	if arrival != nil && arrival.Type != "" {
+		arrivalType = strings.ToLower(arrival.Type)
+	}
+	shards := int(math.Ceil(math.Max(rate, peak) / shardThroughput))
+	if shards > runtime.GOMAXPROCS(0) {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	if shards <= 1 || !splittableArrivals[arrivalType] {
+		return []ArrivalProcess{process}, nil
+	}
+	processes := make([]ArrivalProcess, shards)
+	seeds := rand.New(rand.NewSource(seed))
+	for i := range processes {
+		if arrivalType == "constant" {
+			//shard i starts with the (i+1)th arrival and then takes every shards-th arrival, so the shards together arrive in the same intervals as a single process
+			processes[i] = &constantArrivals{
+				interval: float64(shards) / rate,
+				phase:    float64(i+1-shards) / rate,
+			}
+			continue
+		}
+		processes[i] = &poissonArrivals{rate: rate / float64(shards), rng: rand.New(rand.NewSource(seeds.Int63()))}
+	}
+	return processes, nil
+}
+
+//GeneratorMetrics compare the throughput of root units with their target. The metrics of all units of a worker are labeled with the unit.
+type GeneratorMetrics struct {
+	TargetThroughput   *prometheus.GaugeVec
+	AchievedThroughput *prometheus.GaugeVec
+	Traces             *prometheus.CounterVec
+	Lag                *prometheus.GaugeVec
+}
+
+//NewGeneratorMetrics creates the metrics, which have to be registered before use.
+func NewGeneratorMetrics() *GeneratorMetrics {
+	return &GeneratorMetrics{
+		TargetThroughput: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "worker",
+			Name:      "generator_target_throughput",
+			Help:      "Traces per second a root unit should start, following the load profile if there is one",
+		}, []string{"unit"}),
+		AchievedThroughput: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "worker",
+			Name:      "generator_achieved_throughput",
+			Help:      "Traces per second a root unit started during the last second",
+		}, []string{"unit"}),
+		Traces: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "worker",
+			Name:      "generator_traces_total",
+			Help:      "Traces started by a root unit",
+		}, []string{"unit"}),
+		Lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "worker",
+			Name:      "generator_lag_seconds",
+			Help:      "Time by which the last trace of a root unit was started after its scheduled time",
+		}, []string{"unit"}),
+	}
+}
+
+//Collectors returns all metrics for registration.
+func (m *GeneratorMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.TargetThroughput, m.AchievedThroughput, m.Traces, m.Lag}
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+//shardArrivals returns the first n arrivals of each shard, merged in order.
+func shardArrivals(shards []ArrivalProcess, n int) []time.Duration {
+	times := make([]time.Duration, 0, n*len(shards))
+	for _, shard := range shards {
+		var previous time.Duration
+		for i := 0; i < n; i++ {
+			previous = shard.NextArrival(previous)
+			times = append(times, previous)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times
+}
+
+func TestArrivalShardsSplitHighRates(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	for _, test := range []struct {
+		arrival *api.Arrival
+		rate    float64
+		shards  int
+	}{
+		{nil, 1000, 1},
+		{nil, 100000, 5},
+		{&api.Arrival{Type: "Poisson"}, 100000, 5},
+		//at most one shard per GOMAXPROCS
+		{&api.Arrival{Type: "poisson"}, 1000000, 8},
+		//other processes aren't the superposition of processes with lower rates
+		{&api.Arrival{Type: "onoff", Parameters: map[string]float64{"on": 0.01, "off": 0.01}}, 100000, 1},
+	} {
+		shards, err := NewArrivalShards(test.arrival, test.rate, test.rate, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(shards) != test.shards {
+			t.Errorf("%+v at %.0f/s: expected %d shards, got %d", test.arrival, test.rate, test.shards, len(shards))
+			continue
+		}
+		times := shardArrivals(shards, 100000/len(shards))
+		actual := float64(len(times)) / times[len(times)-1].Seconds()
+		if math.Abs(actual-test.rate)/test.rate > 0.02 {
+			t.Errorf("%+v at %.0f/s: expected the shards to keep the rate, got %.2f/s", test.arrival, test.rate, actual)
+		}
+	}
+	//constant shards arrive in turns, at the same times as a single process
+	shards, err := NewArrivalShards(nil, 100000, 100000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, arrival := range shardArrivals(shards, 1000) {
+		if expected := time.Duration(i+1) * 10 * time.Microsecond; arrival < expected-time.Nanosecond || arrival > expected+time.Nanosecond {
+			t.Fatalf("expected arrival %d at %v, got %v", i, expected, arrival)
+		}
+	}
+}
+
+//countingUnit counts its invocations without invoking anything.
+type countingUnit struct {
+	Unit
+	invocations int64
+}
+
+func (u *countingUnit) Go(ctx context.Context, tracer opentracing.Tracer, wait context.Context) bool {
+	atomic.AddInt64(&u.invocations, 1)
+	return true
+}
+
+func (u *countingUnit) GetLoadPercentage() float64 {
+	return 1
+}
+
+func TestGeneratorKeepsHighRates(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	const rate = 100000
+	unit := &countingUnit{}
+	metrics := NewGeneratorMetrics()
+	generator, err := NewOpenTracingUnitSpanGenerator(unit, &api.Unit{Identifier: "root", ThroughputRatio: 1}, nil, "frontend", opentracing.NoopTracer{}, rate, 1, metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shards := len(generator.(*OpenTracingUnitSpanGenerator).Shards); shards != 4 {
+		t.Errorf("expected a shard per GOMAXPROCS, got %d", shards)
+	}
+	stop := make(chan bool, 1)
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(1)
+	start := time.Now()
+	generator.GenerateUntilExitSignal(stop, nil, &waitGroup)
+	//the metrics are updated once per second
+	time.Sleep(1200 * time.Millisecond)
+	stop <- true
+	elapsed := time.Since(start)
+	waitGroup.Wait()
+	expected := rate * elapsed.Seconds()
+	if invocations := float64(atomic.LoadInt64(&unit.invocations)); math.Abs(invocations-expected)/expected > 0.05 {
+		t.Errorf("expected about %.0f invocations, got %.0f", expected, invocations)
+	}
+	if target := testutil.ToFloat64(metrics.TargetThroughput.WithLabelValues("root")); target != rate {
+		t.Errorf("expected a target of %d/s, got %.2f", rate, target)
+	}
+	if achieved := testutil.ToFloat64(metrics.AchievedThroughput.WithLabelValues("root")); math.Abs(achieved-rate)/rate > 0.05 {
+		t.Errorf("expected to achieve about %d/s, got %.2f", rate, achieved)
+	}
+}
@@ -0,0 +1,253 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	zipkintracer "github.com/openzipkin-contrib/zipkin-go-opentracing"
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/proto/zipkin_proto3"
+	"github.com/openzipkin/zipkin-go/reporter"
+	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
+	jaegercfg "github.com/uber/jaeger-client-go/config"
+	otbridge "go.opentelemetry.io/otel/bridge/opentracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+//DefaultTracerBackend is used for sinks which don't specify a provider.
+const DefaultTracerBackend = "jaeger"
+
+var tracerBackendRegistry map[string]TracerBackend
+
+func init() {
+	tracerBackendRegistry = make(map[string]TracerBackend)
+	tracerBackendRegistry["jaeger"] = &JaegerBackend{}
+	tracerBackendRegistry["otlp-grpc"] = &OTLPBackend{Protocol: OTLPProtocolGRPC}
+	tracerBackendRegistry["otlp-http"] = &OTLPBackend{Protocol: OTLPProtocolHTTP}
+	//plain "otlp" defaults to gRPC, same as the OpenTelemetry SDKs do.
+	tracerBackendRegistry["otlp"] = tracerBackendRegistry["otlp-grpc"]
+	tracerBackendRegistry["zipkin-json"] = &ZipkinBackend{Encoding: ZipkinEncodingJSON}
+	tracerBackendRegistry["zipkin-proto"] = &ZipkinBackend{Encoding: ZipkinEncodingProto}
+	//plain "zipkin" uses JSON v2, which every zipkin-compatible collector accepts.
+	tracerBackendRegistry["zipkin"] = tracerBackendRegistry["zipkin-json"]
+}
+
+//TracerBackend creates opentracing-compatible tracers for a specific tracing system (i.e., the SUT), which send their spans to a sink.
+type TracerBackend interface {
+	NewTracer(sinkAddress, serviceName, samplingStrategy string, samplingParam float64) (opentracing.Tracer, io.Closer, error)
+	//Inspector returns a SpanContextInspector for span contexts created by tracers of this backend.
+	Inspector() SpanContextInspector
+	//NativePropagation returns the propagation format tracers of this backend use for the HTTPHeaders format.
+	NativePropagation() PropagationFormat
+}
+
+//LookupTracerBackend returns the tracer backend registered for the given sink provider. An empty provider selects the DefaultTracerBackend.
+func LookupTracerBackend(provider string) (TracerBackend, error) {
+	if provider == "" {
+		provider = DefaultTracerBackend
+	}
+	backend, exists := tracerBackendRegistry[strings.ToLower(provider)]
+	if !exists {
+		return nil, fmt.Errorf("unknown tracer backend for sink provider %s", provider)
+	}
+	return backend, nil
+}
+
+//TracerBackendNames returns the names of all registered tracer backends, i.e. valid sink providers, sorted by name.
+func TracerBackendNames() []string {
+	names := make([]string, 0, len(tracerBackendRegistry))
+	for name := range tracerBackendRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//JaegerBackend creates tracers from jaeger-client-go, which report to a jaeger agent via UDP.
+type JaegerBackend struct {
+}
+
+func (b *JaegerBackend) NewTracer(sinkAddress, serviceName, samplingstrategy string, samplingParam float64) (opentracing.Tracer, io.Closer, error) {
+	tracerConfig := jaegercfg.Configuration{
+		ServiceName: serviceName,
+		Sampler: &jaegercfg.SamplerConfig{
+			Type:  samplingstrategy,
+			Param: samplingParam,
+		},
+		Reporter: &jaegercfg.ReporterConfig{
+			LocalAgentHostPort: sinkAddress,
+			LogSpans:           true,
+		},
+	}
+	return tracerConfig.NewTracer()
+}
+
+func (b *JaegerBackend) Inspector() SpanContextInspector {
+	return &JaegerInspector{}
+}
+
+func (b *JaegerBackend) NativePropagation() PropagationFormat {
+	return propagationFormatRegistry["uber"]
+}
+
+//OTLPProtocol is the transport used by the OTLP exporter.
+type OTLPProtocol string
+
+const (
+	OTLPProtocolGRPC OTLPProtocol = "grpc"
+	OTLPProtocolHTTP OTLPProtocol = "http"
+)
+
+//OTLPBackend creates tracers from the OpenTelemetry SDK, which are bridged to the opentracing API and export spans via OTLP, e.g. to an OpenTelemetry Collector.
+type OTLPBackend struct {
+	Protocol OTLPProtocol
+}
+
+func (b *OTLPBackend) NewTracer(sinkAddress, serviceName, samplingstrategy string, samplingParam float64) (opentracing.Tracer, io.Closer, error) {
+	sampler, err := otelSampler(samplingstrategy, samplingParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	var client otlptrace.Client
+	switch b.Protocol {
+	case OTLPProtocolGRPC:
+		client = otlptracegrpc.NewClient(otlptracegrpc.WithEndpoint(sinkAddress), otlptracegrpc.WithInsecure())
+	case OTLPProtocolHTTP:
+		client = otlptracehttp.NewClient(otlptracehttp.WithEndpoint(sinkAddress), otlptracehttp.WithInsecure())
+	default:
+		return nil, nil, fmt.Errorf("unknown OTLP protocol %s", b.Protocol)
+	}
+	exporter, err := otlptrace.New(context.Background(), client)
+	if err != nil {
+		return nil, nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	bridgeTracer, _ := otbridge.NewTracerPair(provider.Tracer(serviceName))
+	bridgeTracer.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return bridgeTracer, &tracerProviderCloser{provider: provider}, nil
+}
+
+func (b *OTLPBackend) Inspector() SpanContextInspector {
+	return NewOTelInspector()
+}
+
+func (b *OTLPBackend) NativePropagation() PropagationFormat {
+	return propagationFormatRegistry["w3c"]
+}
+
+//otelSampler maps the jaeger-style sampling parameters of the worker to an OpenTelemetry sampler. Like jaeger, sampling decisions of the parent are respected.
+func otelSampler(samplingstrategy string, samplingParam float64) (sdktrace.Sampler, error) {
+	var root sdktrace.Sampler
+	switch strings.ToLower(samplingstrategy) {
+	case "const":
+		if samplingParam >= 1.0 {
+			root = sdktrace.AlwaysSample()
+		} else {
+			root = sdktrace.NeverSample()
+		}
+	case "probabilistic":
+		root = sdktrace.TraceIDRatioBased(samplingParam)
+	default:
+		return nil, fmt.Errorf("sampling type %s is not supported by the OTLP tracer backend", samplingstrategy)
+	}
+	return sdktrace.ParentBased(root), nil
+}
+
+//tracerProviderCloser flushes and shuts down an OpenTelemetry tracer provider on Close.
+type tracerProviderCloser struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (c *tracerProviderCloser) Close() error {
+	return c.provider.Shutdown(context.Background())
+}
+
+//ZipkinEncoding is the span encoding used by the zipkin HTTP reporter.
+type ZipkinEncoding string
+
+const (
+	ZipkinEncodingJSON  ZipkinEncoding = "json"
+	ZipkinEncodingProto ZipkinEncoding = "proto"
+)
+
+//zipkinSpansPath is the default path of the zipkin v2 HTTP API, used if the sink address doesn't contain a full URL.
+const zipkinSpansPath = "/api/v2/spans"
+
+//ZipkinBackend creates tracers from zipkin-go, which are wrapped by zipkin-go-opentracing and report spans to the zipkin v2 HTTP API of a collector (e.g. Zipkin, Tempo or Jaeger).
+type ZipkinBackend struct {
+	Encoding ZipkinEncoding
+}
+
+func (b *ZipkinBackend) NewTracer(sinkAddress, serviceName, samplingstrategy string, samplingParam float64) (opentracing.Tracer, io.Closer, error) {
+	sampler, err := zipkinSampler(samplingstrategy, samplingParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	var serializer reporter.SpanSerializer
+	switch b.Encoding {
+	case ZipkinEncodingJSON:
+		serializer = reporter.JSONSerializer{}
+	case ZipkinEncodingProto:
+		serializer = zipkin_proto3.SpanSerializer{}
+	default:
+		return nil, nil, fmt.Errorf("unknown zipkin encoding %s", b.Encoding)
+	}
+	url := sinkAddress
+	if !strings.Contains(url, "://") {
+		url = "http://" + sinkAddress + zipkinSpansPath
+	}
+	spanReporter := zipkinhttp.NewReporter(url, zipkinhttp.Serializer(serializer))
+	endpoint, err := zipkin.NewEndpoint(serviceName, "")
+	if err != nil {
+		spanReporter.Close()
+		return nil, nil, err
+	}
+	tracer, err := zipkin.NewTracer(spanReporter,
+		zipkin.WithLocalEndpoint(endpoint),
+		zipkin.WithSampler(sampler),
+		zipkin.WithTraceID128Bit(true),
+	)
+	if err != nil {
+		spanReporter.Close()
+		return nil, nil, err
+	}
+	return zipkintracer.Wrap(tracer), spanReporter, nil
+}
+
+func (b *ZipkinBackend) Inspector() SpanContextInspector {
+	return &ZipkinInspector{}
+}
+
+func (b *ZipkinBackend) NativePropagation() PropagationFormat {
+	return propagationFormatRegistry["b3multi"]
+}
+
+//zipkinSampler maps the jaeger-style sampling parameters of the worker to a zipkin sampler. Zipkin always respects sampling decisions of the parent.
+func zipkinSampler(samplingstrategy string, samplingParam float64) (zipkin.Sampler, error) {
+	switch strings.ToLower(samplingstrategy) {
+	case "const":
+		if samplingParam >= 1.0 {
+			return zipkin.AlwaysSample, nil
+		}
+		return zipkin.NeverSample, nil
+	case "probabilistic":
+		return zipkin.NewBoundarySampler(samplingParam, time.Now().UnixNano())
+	default:
+		return nil, fmt.Errorf("sampling type %s is not supported by the zipkin tracer backend", samplingstrategy)
+	}
+}
@@ -0,0 +1,233 @@
+package worker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/proto/zipkin_proto3"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLookupTracerBackend(t *testing.T) {
+	for provider, expected := range map[string]TracerBackend{
+		"":          tracerBackendRegistry[DefaultTracerBackend],
+		"Jaeger":    &JaegerBackend{},
+		"otlp":      &OTLPBackend{Protocol: OTLPProtocolGRPC},
+		"OTLP-HTTP": &OTLPBackend{Protocol: OTLPProtocolHTTP},
+	} {
+		backend, err := LookupTracerBackend(provider)
+		if err != nil {
+			t.Errorf("%s: %v", provider, err)
+		} else if !reflect.DeepEqual(backend, expected) {
+			t.Errorf("%s: expected %+v, got %+v", provider, expected, backend)
+		}
+	}
+	if _, err := LookupTracerBackend("elastic"); err == nil {
+		t.Errorf("expected an error for an unknown provider")
+	}
+	names := TracerBackendNames()
+	if !sort.StringsAreSorted(names) || len(names) != len(tracerBackendRegistry) {
+		t.Errorf("expected the sorted names of all backends, got %v", names)
+	}
+}
+
+func TestOTelSampler(t *testing.T) {
+	traceID := trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	for _, test := range []struct {
+		strategy string
+		param    float64
+		sampled  bool
+	}{
+		{"const", 1, true},
+		{"const", 0, false},
+		{"Probabilistic", 1, true},
+		{"probabilistic", 0, false},
+	} {
+		sampler, err := otelSampler(test.strategy, test.param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := sampler.ShouldSample(sdktrace.SamplingParameters{TraceID: traceID, Name: "root"})
+		if sampled := result.Decision == sdktrace.RecordAndSample; sampled != test.sampled {
+			t.Errorf("%s %f: expected sampled %t, got %t", test.strategy, test.param, test.sampled, sampled)
+		}
+	}
+	if _, err := otelSampler("ratelimiting", 10); err == nil {
+		t.Errorf("expected an error for an unsupported sampling type")
+	}
+}
+
+//otlpCollector records the spans exported to the OTLP/HTTP endpoint, by service and span name.
+type otlpCollector struct {
+	lock  sync.Mutex
+	spans []string
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	request := &coltracepb.ExportTraceServiceRequest{}
+	if r.URL.Path != "/v1/traces" || proto.Unmarshal(body, request) != nil {
+		http.Error(w, "invalid export request", http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, resourceSpans := range request.ResourceSpans {
+		service := ""
+		for _, attribute := range resourceSpans.Resource.Attributes {
+			if attribute.Key == "service.name" {
+				service = attribute.Value.GetStringValue()
+			}
+		}
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				c.spans = append(c.spans, service+"/"+span.Name)
+			}
+		}
+	}
+	response, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(response)
+}
+
+func TestOTLPBackendExportsSampledSpans(t *testing.T) {
+	for _, sampled := range []bool{true, false} {
+		collector := &otlpCollector{}
+		server := httptest.NewServer(collector)
+		backend, _ := LookupTracerBackend("otlp-http")
+		param := 0.0
+		if sampled {
+			param = 1
+		}
+		tracer, closer, err := backend.NewTracer(strings.TrimPrefix(server.URL, "http://"), "frontend", "const", param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root := tracer.StartSpan("root")
+		tracer.StartSpan("child", opentracing.ChildOf(root.Context())).Finish()
+		root.Finish()
+		//closing flushes the spans
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+		server.Close()
+		expected := []string(nil)
+		if sampled {
+			expected = []string{"frontend/child", "frontend/root"}
+		}
+		sort.Strings(collector.spans)
+		if !reflect.DeepEqual(collector.spans, expected) {
+			t.Errorf("sampled %t: expected exported spans %v, got %v", sampled, expected, collector.spans)
+		}
+	}
+}
+
+//zipkinCollector records the spans reported to the zipkin v2 HTTP API in JSON or protobuf encoding, by service and span name.
+type zipkinCollector struct {
+	lock  sync.Mutex
+	paths []string
+	spans []string
+}
+
+func (c *zipkinCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	var spans []*model.SpanModel
+	var err error
+	if r.Header.Get("Content-Type") == "application/x-protobuf" {
+		spans, err = zipkin_proto3.ParseSpans(body, false)
+	} else {
+		err = json.Unmarshal(body, &spans)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.paths = append(c.paths, r.URL.Path)
+	for _, span := range spans {
+		c.spans = append(c.spans, span.LocalEndpoint.ServiceName+"/"+span.Name)
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestZipkinBackendReportsSpans(t *testing.T) {
+	for _, provider := range []string{"zipkin", "zipkin-proto"} {
+		collector := &zipkinCollector{}
+		server := httptest.NewServer(collector)
+		//a sink address without a URL uses the default path of the API, a URL is used as is
+		for _, sink := range []string{strings.TrimPrefix(server.URL, "http://"), server.URL + "/custom/spans"} {
+			backend, _ := LookupTracerBackend(provider)
+			tracer, closer, err := backend.NewTracer(sink, "frontend", "const", 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root := tracer.StartSpan("root")
+			tracer.StartSpan("child", opentracing.ChildOf(root.Context())).Finish()
+			root.Finish()
+			//unsampled traces aren't reported
+			unsampled := opentracing.HTTPHeadersCarrier(http.Header{"X-B3-Traceid": {"0000000000000001"}, "X-B3-Spanid": {"0000000000000002"}, "X-B3-Sampled": {"0"}})
+			parent, err := tracer.Extract(opentracing.HTTPHeaders, unsampled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tracer.StartSpan("unsampled", opentracing.ChildOf(parent)).Finish()
+			//closing flushes the spans
+			if err := closer.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		server.Close()
+		sort.Strings(collector.spans)
+		if expected := []string{"frontend/child", "frontend/child", "frontend/root", "frontend/root"}; !reflect.DeepEqual(collector.spans, expected) {
+			t.Errorf("%s: expected reported spans %v, got %v", provider, expected, collector.spans)
+		}
+		if expected := []string{zipkinSpansPath, "/custom/spans"}; !reflect.DeepEqual(collector.paths, expected) {
+			t.Errorf("%s: expected reports to %v, got %v", provider, expected, collector.paths)
+		}
+	}
+}
+
+func TestZipkinSampler(t *testing.T) {
+	for _, test := range []struct {
+		strategy string
+		param    float64
+		sampled  bool
+	}{
+		{"const", 1, true},
+		{"CONST", 0, false},
+		{"probabilistic", 1, true},
+		{"probabilistic", 0, false},
+	} {
+		sampler, err := zipkinSampler(test.strategy, test.param)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sampled := sampler(12345); sampled != test.sampled {
+			t.Errorf("%s %f: expected sampled %t, got %t", test.strategy, test.param, test.sampled, sampled)
+		}
+	}
+	for _, test := range []struct {
+		strategy string
+		param    float64
+	}{
+		{"remote", 1},
+		{"probabilistic", 2},
+	} {
+		if _, err := zipkinSampler(test.strategy, test.param); err == nil {
+			t.Errorf("expected an error for %s %f", test.strategy, test.param)
+		}
+	}
+}
@@ -3,24 +3,36 @@ package worker
 import (
 	"context"
 	"log"
+	"math/rand"
+	"net/http"
 	"time"
 
 	"github.com/dominik-/t-race/api"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	otlog "github.com/opentracing/opentracing-go/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type Unit interface {
-	Invoke(context.Context, opentracing.Tracer)
+	//Invoke returns an error if the unit itself failed. Failed calls to successors are recorded in the result of the unit instead.
+	Invoke(context.Context, opentracing.Tracer) error
 	ExtractIncomingMetadata(context.Context, opentracing.Tracer) (opentracing.SpanContext, error)
 	StartContext(opentracing.Tracer, opentracing.SpanContext, context.Context) (opentracing.Span, context.Context)
 	EmulateWork()
 	AddContextMetadata(opentracing.Span)
-	Next(context.Context, opentracing.Span, opentracing.Tracer)
+	Next(context.Context, opentracing.Span, opentracing.Tracer) error
 	CloseContext(opentracing.Span)
+	//Go invokes the unit in a new goroutine, if the concurrency limits of the unit and the worker admit it. Under the block policy, the caller waits for a free slot
+	//until wait is done, a nil wait drops the invocation instead. Returns false if the invocation was dropped.
+	Go(ctx context.Context, tracer opentracing.Tracer, wait context.Context) bool
+	//InvokeLimited invokes the unit in the calling goroutine, once the concurrency limits of the unit and the worker have a free slot. It waits for a slot
+	//until wait is done, whatever the overload policy. Returns false if the unit wasn't invoked.
+	InvokeLimited(ctx context.Context, tracer opentracing.Tracer, wait context.Context) bool
 	GetLoadPercentage() float64
 	SetWeight(int64)
 	GetWeight() int64
@@ -37,6 +49,8 @@ type UnitExecutor struct {
 	Logs             map[string]string
 	Worker           *Worker
 	Weight           int64
+	//Limiter limits the concurrent invocations of the unit started by Go, nil doesn't limit them.
+	Limiter *ConcurrencyLimiter
 }
 
 func CreateUnitExecutorFromConfig(unitConfig *api.Unit, workerConfig *Worker) (*UnitExecutor, error) {
@@ -45,6 +59,7 @@ func CreateUnitExecutorFromConfig(unitConfig *api.Unit, workerConfig *Worker) (*
 		//surface error from parsing the distribution
 		return nil, err
 	}
+	dist.SetRNGSeed(workerConfig.unitSeed(unitConfig.Identifier, "work"))
 	// Create TLS credentials for grpc clients that skip root CA verification
 	/* 	creds := credentials.NewTLS(&tls.Config{
 	   		InsecureSkipVerify: true,
@@ -53,25 +68,30 @@ func CreateUnitExecutorFromConfig(unitConfig *api.Unit, workerConfig *Worker) (*
 	clientConnections := make(map[string]*grpc.ClientConn)
 	for _, successor := range unitConfig.Successors {
 		if successor.IsRemote {
-			conn, err := grpc.Dial(successor.HostPort, grpc.WithInsecure())
+			conn, err := grpc.Dial(successor.HostPort, append([]grpc.DialOption{grpc.WithInsecure()}, workerConfig.DialOptions...)...)
 			if err != nil {
 				return nil, err
 			}
 			clientConnections[successor.ServiceId] = conn
 		}
 	}
+	limiter, err := NewConcurrencyLimiter(unitConfig.Concurrency, "unit")
+	if err != nil {
+		return nil, err
+	}
 	var tags map[string]string
 	var baggage map[string]string
 	var logs map[string]string
 	if unitConfig.Context != nil {
+		rng := rand.New(rand.NewSource(workerConfig.unitSeed(unitConfig.Identifier, "context")))
 		if unitConfig.Context.Tags != nil {
-			tags = generateStringMap(unitConfig.Context.Tags)
+			tags = generateStringMap(unitConfig.Context.Tags, rng)
 		}
 		if unitConfig.Context.Baggage != nil {
-			baggage = generateStringMap(unitConfig.Context.Baggage)
+			baggage = generateStringMap(unitConfig.Context.Baggage, rng)
 		}
 		if unitConfig.Context.Logs != nil {
-			logs = generateStringMap(unitConfig.Context.Logs)
+			logs = generateStringMap(unitConfig.Context.Logs, rng)
 		}
 	}
 
@@ -85,23 +105,44 @@ func CreateUnitExecutorFromConfig(unitConfig *api.Unit, workerConfig *Worker) (*
 		Baggage:          baggage,
 		Logs:             logs,
 		Worker:           workerConfig,
+		Limiter:          limiter,
 	}, nil
 }
 
-func (executor *UnitExecutor) Invoke(ctx context.Context, tracer opentracing.Tracer) {
+func (executor *UnitExecutor) Go(ctx context.Context, tracer opentracing.Tracer, wait context.Context) bool {
+	return executor.Worker.goLimited(wait, executor.data.Identifier, executor.Limiter, func() {
+		executor.Invoke(ctx, tracer)
+	})
+}
+
+func (executor *UnitExecutor) InvokeLimited(ctx context.Context, tracer opentracing.Tracer, wait context.Context) bool {
+	return executor.Worker.invokeLimited(wait, executor.Limiter, func() {
+		executor.Invoke(ctx, tracer)
+	})
+}
+
+func (executor *UnitExecutor) Invoke(ctx context.Context, tracer opentracing.Tracer) error {
 	//Assumption: at this point we always have a context
 	spanCtx, err := executor.ExtractIncomingMetadata(ctx, tracer)
 	if err != nil {
-		log.Fatalf("Couldn't extract metadata, please check format. Data was: %v", ctx)
+		log.Printf("Couldn't extract metadata of unit %s, please check format: %v", executor.data.Identifier, err)
+		return status.Errorf(codes.InvalidArgument, "couldn't extract trace context: %v", err)
+	}
+	//the parent is the context of the remote caller, or the span of a local caller
+	parent := spanCtx
+	if parentSpan := opentracing.SpanFromContext(ctx); parent == nil && parentSpan != nil {
+		parent = parentSpan.Context()
 	}
 	spanStart := time.Now()
 	span, ctxNew := executor.StartContext(tracer, spanCtx, ctx)
 	executor.AddContextMetadata(span)
 	executor.EmulateWork()
-	executor.Next(ctxNew, span, tracer)
-	sampled := getSampledFlag(span.Context())
-	traceID := getTraceIdAsBytes(span.Context())
-	spanID := getSpanID(span.Context())
+	callErr := executor.Next(ctxNew, span, tracer)
+	if callErr != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(otlog.Error(callErr))
+	}
+	info, inspectErr := inspectChild(executor.Worker.Inspector, span.Context(), parent)
 	executor.CloseContext(span)
 	finishTimeDelta := time.Since(spanStart)
 	executor.Worker.SpanDurationHist.Observe(float64(finishTimeDelta.Nanoseconds() / 1000.0))
@@ -110,13 +151,26 @@ func (executor *UnitExecutor) Invoke(ctx context.Context, tracer opentracing.Tra
 	if err != nil {
 		log.Fatalf("Couldn't convert timestamps to proto format.")
 	}
-	go executor.Worker.Reporter.Collect(&api.Result{
-		TraceId:    traceID,
-		SpanId:     spanID,
+	//spans of a foreign tracer can't be reported, but they're still sent to the sink.
+	if inspectErr != nil {
+		log.Printf("Couldn't inspect span context of unit %s: %v", executor.data.Identifier, inspectErr)
+		return nil
+	}
+	result := &api.Result{
+		Unit:       executor.data.Identifier,
+		TraceId:    info.TraceID,
+		SpanId:     info.SpanID,
+		ParentId:   info.ParentID,
 		StartTime:  started,
 		FinishTime: finished,
-		Sampled:    sampled,
-	})
+		Sampled:    info.Sampled,
+	}
+	if callErr != nil {
+		result.StatusCode = uint32(status.Code(callErr))
+		result.Error = status.Convert(callErr).Message()
+	}
+	go executor.Worker.Reporter.Collect(result)
+	return nil
 }
 
 func (executor *UnitExecutor) ExtractIncomingMetadata(ctx context.Context, tracer opentracing.Tracer) (opentracing.SpanContext, error) {
@@ -124,7 +178,8 @@ func (executor *UnitExecutor) ExtractIncomingMetadata(ctx context.Context, trace
 	if !ok {
 		md = metadata.New(nil)
 	}
-	remoteContext, err := tracer.Extract(opentracing.HTTPHeaders, metadataReaderWriter{md})
+	header := executor.Worker.Propagator.ToNative(http.Header(metadataToHTTPHeaders(md)))
+	remoteContext, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(header))
 	//if there is no span context to be found in headers, were fine actually, because it means that this is a root span. Could do an additional check for that here.
 	if err != nil && err != opentracing.ErrSpanContextNotFound {
 		return nil, err
@@ -175,10 +230,14 @@ func (executor *UnitExecutor) AddContextMetadata(span opentracing.Span) {
 	}
 }
 
-func (executor *UnitExecutor) Next(ctx context.Context, span opentracing.Span, tracer opentracing.Tracer) {
+//Next invokes all successors and returns the first error of a synchronous call. Asynchronous calls which fail are only logged, since the span is finished before they return.
+//Asynchronous calls which are dropped by a concurrency limit fail with ResourceExhausted.
+func (executor *UnitExecutor) Next(ctx context.Context, span opentracing.Span, tracer opentracing.Tracer) error {
 	//for each successor we have 4 different cases: remote or local, req-resp or fire and forget
 	//var ctxNew context.Context
+	var firstErr error
 	for _, successor := range executor.data.Successors {
+		var err error
 		localClientSpan, ctxNew := opentracing.StartSpanFromContextWithTracer(ctx, tracer, "invoke-"+successor.UnitId, mapOpenTracingRelationshipType(executor.data.RelType, span.Context()))
 		//localClientSpan := tracer.StartSpan("invoke-"+successor.UnitId, mapOpenTracingRelationshipType(api.RelationshipType_CHILD, span.Context()))
 		//ctxNew = opentracing.ContextWithSpan(ctx, localClientSpan)
@@ -190,26 +249,62 @@ func (executor *UnitExecutor) Next(ctx context.Context, span opentracing.Span, t
 				md = md.Copy()
 			}
 			mdWriter := metadataReaderWriter{md}
-			//Step 3b: Inject the local span context with HTTP-Header-Format into the metadatawriter.
-			err := tracer.Inject(localClientSpan.Context(), opentracing.HTTPHeaders, mdWriter)
-			if err != nil {
-				log.Printf("Tracer.Inject() failed: %v", err)
+			//Step 3b: Inject the local span context with HTTP-Header-Format, convert it to the configured propagation format and copy it into the metadatawriter.
+			carrier := opentracing.HTTPHeadersCarrier(http.Header{})
+			if injectErr := tracer.Inject(localClientSpan.Context(), opentracing.HTTPHeaders, carrier); injectErr != nil {
+				log.Printf("Tracer.Inject() failed: %v", injectErr)
 			}
+			header := executor.Worker.Propagator.FromNative(http.Header(carrier))
+			executor.Worker.HeaderSizeHist.Observe(float64(headerSize(header)))
+			mdWriter.SetFromHTTPHeaders(opentracing.HTTPHeadersCarrier(header))
+			//Step 3a: Use context ("outgoing" is from the perspective of the calling service!) and create a metadata writer;
+			outgoingCtx := metadata.NewOutgoingContext(ctxNew, md)
 			if successor.Sync {
-				//Step 3a: Use context ("outgoing" is from the perspective of the calling service!) and create a metadata writer;
-				api.NewBenchmarkWorkerClient(executor.SuccessorClients[successor.ServiceId]).Call(metadata.NewOutgoingContext(ctxNew, md), &api.DispatchId{UnitReference: successor.UnitId})
+				err = executor.call(outgoingCtx, successor)
 			} else {
-				go api.NewBenchmarkWorkerClient(executor.SuccessorClients[successor.ServiceId]).Call(metadata.NewOutgoingContext(ctxNew, md), &api.DispatchId{UnitReference: successor.UnitId})
+				//asynchronous calls count against the limit of the worker, the limits of the remote unit apply on its worker
+				successor := successor
+				admitted := executor.Worker.goLimited(nil, executor.data.Identifier, nil, func() {
+					if err := executor.call(outgoingCtx, successor); err != nil {
+						log.Printf("Asynchronous call to %s/%s failed: %v", successor.ServiceId, successor.UnitId, err)
+					}
+				})
+				if !admitted {
+					err = status.Errorf(codes.ResourceExhausted, "concurrency limit of worker reached, asynchronous call was dropped")
+				}
 			}
 		} else {
-			if successor.Sync {
-				executor.Worker.UnitExecutorMap[successor.UnitId].Invoke(ctxNew, tracer)
-			} else {
-				go executor.Worker.UnitExecutorMap[successor.UnitId].Invoke(ctxNew, tracer)
+			unit, _, ok := executor.Worker.lookupUnit(successor.UnitId)
+			if !ok {
+				err = status.Errorf(codes.NotFound, "unknown unit %s", successor.UnitId)
+			} else if successor.Sync {
+				err = unit.Invoke(ctxNew, tracer)
+			} else if !unit.Go(ctxNew, tracer, nil) {
+				err = status.Errorf(codes.ResourceExhausted, "concurrency limit reached, asynchronous invocation was dropped")
+			}
+		}
+		if err != nil {
+			ext.Error.Set(localClientSpan, true)
+			localClientSpan.LogFields(otlog.Error(err))
+			if firstErr == nil {
+				firstErr = status.Errorf(status.Code(err), "call to %s/%s failed: %s", successor.ServiceId, successor.UnitId, status.Convert(err).Message())
 			}
 		}
 		localClientSpan.Finish()
 	}
+	return firstErr
+}
+
+//call invokes a unit of a remote successor. The call fails if it doesn't return within the call timeout of the worker.
+func (executor *UnitExecutor) call(ctx context.Context, successor *api.UnitRef) error {
+	conn, ok := executor.SuccessorClients[successor.ServiceId]
+	if !ok {
+		return status.Errorf(codes.Unavailable, "no connection to service %s", successor.ServiceId)
+	}
+	ctx, cancel := context.WithTimeout(ctx, executor.Worker.callTimeout())
+	defer cancel()
+	_, err := api.NewBenchmarkWorkerClient(conn).Call(ctx, &api.DispatchId{UnitReference: successor.UnitId})
+	return err
 }
 
 func (executor *UnitExecutor) CloseContext(span opentracing.Span) {
@@ -217,7 +312,7 @@ func (executor *UnitExecutor) CloseContext(span opentracing.Span) {
 }
 
 //Turns templates for tags and baggage into a map of strings to strings.
-func generateStringMap(templates []*api.KeyValueTemplate) map[string]string {
+func generateStringMap(templates []*api.KeyValueTemplate, rng *rand.Rand) map[string]string {
 	data := make(map[string]string, len(templates))
 	for _, tagTemplate := range templates {
 		//Differentiation 0: key is static value, i.e. check for length to be 0 or less
@@ -226,14 +321,15 @@ func generateStringMap(templates []*api.KeyValueTemplate) map[string]string {
 			if tagTemplate.GetValueLength() <= 0 {
 				data[tagTemplate.GetKeyStatic()] = tagTemplate.GetValueStatic()
 			} else {
-				data[tagTemplate.GetKeyStatic()] = RandStringWithLength(tagTemplate.GetValueLength())
+				data[tagTemplate.GetKeyStatic()] = randString(rng, tagTemplate.GetValueLength())
 			}
 		} else {
 			//Differentiation 1 (again): value is a static value, i.e. check for length to be 0 or less
 			if tagTemplate.GetValueLength() <= 0 {
-				data[RandStringWithLength(tagTemplate.GetKeyLength())] = tagTemplate.GetValueStatic()
+				data[randString(rng, tagTemplate.GetKeyLength())] = tagTemplate.GetValueStatic()
 			} else {
-				data[RandStringWithLength(tagTemplate.GetKeyLength())] = RandStringWithLength(tagTemplate.GetValueLength())
+				key := randString(rng, tagTemplate.GetKeyLength())
+				data[key] = randString(rng, tagTemplate.GetValueLength())
 			}
 		}
 	}
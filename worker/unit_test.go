@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//resultCollector is a ResultStream which keeps the results of each unit.
+type resultCollector struct {
+	lock    sync.Mutex
+	results map[string]*api.Result
+}
+
+func (c *resultCollector) Send(resultPackage *api.ResultPackage) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, result := range resultPackage.Results {
+		c.results[result.Unit] = result
+	}
+	return nil
+}
+
+//awaitResults reports the results of the worker until the collector has a result of each unit.
+func (c *resultCollector) awaitResults(t *testing.T, w *Worker, units []string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.Reporter.Report()
+		c.lock.Lock()
+		complete := true
+		for _, unit := range units {
+			_, exists := c.results[unit]
+			complete = complete && exists
+		}
+		c.lock.Unlock()
+		if complete {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected results of units %v, got %v", units, c.results)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFailedCallsAreRecordedInResults(t *testing.T) {
+	cluster := NewLocalCluster([]string{"frontend", "backend"}, "const", 1)
+	defer cluster.Stop()
+	_, err := cluster.Workers["backend"].Prepare(context.Background(), &api.WorkerConfiguration{
+		WorkerId:     "worker-backend",
+		ServiceName:  "backend",
+		SinkProvider: "jaeger",
+		SinkHostPort: "localhost:6831",
+		Units: []*api.Unit{
+			{Identifier: "b"},
+			{Identifier: "slow", WorkBefore: &api.Work{DistType: "constant", Parameters: map[string]float64{"value": 500000}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frontend := cluster.Workers["frontend"]
+	frontend.CallTimeout = 50 * time.Millisecond
+	remote := func(unit string, sync bool) []*api.UnitRef {
+		return []*api.UnitRef{{ServiceId: "backend", UnitId: unit, Sync: sync, IsRemote: true, HostPort: cluster.ServiceAddress("backend")}}
+	}
+	_, err = frontend.Prepare(context.Background(), &api.WorkerConfiguration{
+		WorkerId:     "worker-frontend",
+		ServiceName:  "frontend",
+		SinkProvider: "jaeger",
+		SinkHostPort: "localhost:6831",
+		Units: []*api.Unit{
+			{Identifier: "ok", Successors: remote("b", true)},
+			{Identifier: "unknown", Successors: remote("missing", true)},
+			{Identifier: "timeout", Successors: remote("slow", true)},
+			{Identifier: "local", Successors: []*api.UnitRef{{ServiceId: "frontend", UnitId: "missing", Sync: true}}},
+			//asynchronous calls finish after the span, so their errors aren't recorded
+			{Identifier: "async", Successors: remote("missing", false)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := &resultCollector{results: make(map[string]*api.Result)}
+	frontend.Reporter.(*BufferingReporter).SetTarget(collector)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(frontend.pendingSuccessors()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the frontend to connect to the backend")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	expected := map[string]struct {
+		code  codes.Code
+		error string
+	}{
+		"ok":      {codes.OK, ""},
+		"unknown": {codes.NotFound, "call to backend/missing failed: unknown unit missing"},
+		"timeout": {codes.DeadlineExceeded, "call to backend/slow failed"},
+		"local":   {codes.NotFound, "call to frontend/missing failed: unknown unit missing"},
+		"async":   {codes.OK, ""},
+	}
+	units := make([]string, 0, len(expected))
+	for unit := range expected {
+		//failed calls to successors don't fail the call of the unit itself
+		if err := callLocal(t, cluster, "frontend", unit); err != nil {
+			t.Errorf("%s: expected the call to succeed, got %v", unit, err)
+		}
+		units = append(units, unit)
+	}
+	collector.awaitResults(t, frontend, units)
+	for unit, expectation := range expected {
+		result := collector.results[unit]
+		if codes.Code(result.StatusCode) != expectation.code || !strings.HasPrefix(result.Error, expectation.error) || (expectation.error == "") != (result.Error == "") {
+			t.Errorf("%s: expected status %s and error %q, got %s and %q", unit, expectation.code, expectation.error, codes.Code(result.StatusCode), result.Error)
+		}
+	}
+	if err := callLocal(t, cluster, "frontend", "missing"); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a call of an unknown unit, got %v", err)
+	}
+}
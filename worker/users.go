@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/opentracing/opentracing-go"
+)
+
+//ClosedLoopGenerator invokes a root unit with a fixed number of virtual users. Each user invokes the unit, waits until its synchronous calls are finished,
+//waits for a think time and repeats, so the throughput of the unit drops when the SUT or successors slow down. TraceCounter and responseTime are accessed atomically.
+type ClosedLoopGenerator struct {
+	TraceCounter int64
+	//responseTime is the sum of the durations of all invocations in nanoseconds.
+	responseTime  int64
+	Tracer        opentracing.Tracer
+	Unit          Unit
+	UnitName      string
+	Users         int64
+	ThinkTime     DistributionSampler
+	Metrics       *GeneratorMetrics
+	thinkTimeLock sync.Mutex
+}
+
+//NewClosedLoopGenerator creates a generator for a unit with virtual users. The seed is the seed of the think times. Metrics are optional.
+func NewClosedLoopGenerator(unit Unit, config *api.Unit, tracer opentracing.Tracer, seed int64, metrics *GeneratorMetrics) (UnitContextGenerator, error) {
+	thinkTime, err := LookupDistribution(config.Users.ThinkTime)
+	if err != nil {
+		return nil, err
+	}
+	thinkTime.SetRNGSeed(seed)
+	return &ClosedLoopGenerator{
+		Tracer:    tracer,
+		Unit:      unit,
+		UnitName:  config.Identifier,
+		Users:     config.Users.Count,
+		ThinkTime: thinkTime,
+		Metrics:   metrics,
+	}, nil
+}
+
+//GenerateUntilExitSignal starts all users and stops them when the stop signal is received. Users finish their current invocation, but don't start new ones.
+func (gen *ClosedLoopGenerator) GenerateUntilExitSignal(stopSignalRecv <-chan bool, reporter ResultReporter, waitGroup *sync.WaitGroup) {
+	go func() {
+		start := time.Now()
+		stopped, stop := context.WithCancel(context.Background())
+		var usersWG sync.WaitGroup
+		for i := int64(0); i < gen.Users; i++ {
+			usersWG.Add(1)
+			go gen.user(stopped, &usersWG)
+		}
+		ticker := time.NewTicker(time.Second)
+		lastCount, lastTime := int64(0), start
+	GenerateLoop:
+		for {
+			select {
+			case <-stopSignalRecv:
+				break GenerateLoop
+			case now := <-ticker.C:
+				count := atomic.LoadInt64(&gen.TraceCounter)
+				if gen.Metrics != nil {
+					gen.Metrics.AchievedThroughput.WithLabelValues(gen.UnitName).Set(float64(count-lastCount) / now.Sub(lastTime).Seconds())
+					gen.Metrics.Traces.WithLabelValues(gen.UnitName).Add(float64(count - lastCount))
+				}
+				lastCount, lastTime = count, now
+			}
+		}
+		ticker.Stop()
+		stop()
+		//invocations in flight are counted and reported, before the generator is finished
+		usersWG.Wait()
+		elapsed := time.Since(start)
+		count := atomic.LoadInt64(&gen.TraceCounter)
+		meanResponseTime := time.Duration(0)
+		if count > 0 {
+			meanResponseTime = time.Duration(atomic.LoadInt64(&gen.responseTime) / count)
+		}
+		log.Printf("Unit %s started %d traces with %d users in %.1fs: %.2f/s, mean response time %v.", gen.UnitName, count, gen.Users, elapsed.Seconds(), float64(count)/elapsed.Seconds(), meanResponseTime)
+		//signal to parent that this worker is successfully finished
+		waitGroup.Done()
+	}()
+}
+
+//user invokes the unit until it is stopped. Users start with a think time, so they don't all invoke the unit at the same time. Users wait for free slots
+//of the concurrency limits, since each of them has only one invocation at a time; the waiting time is part of the response time.
+func (gen *ClosedLoopGenerator) user(stopped context.Context, usersWG *sync.WaitGroup) {
+	defer usersWG.Done()
+	for {
+		if think := gen.thinkTime(); think > 0 {
+			timer := time.NewTimer(think)
+			select {
+			case <-stopped.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if stopped.Err() != nil {
+			return
+		}
+		invoked := time.Now()
+		if !gen.Unit.InvokeLimited(context.Background(), gen.Tracer, stopped) {
+			return
+		}
+		atomic.AddInt64(&gen.responseTime, int64(time.Since(invoked)))
+		atomic.AddInt64(&gen.TraceCounter, 1)
+	}
+}
+
+//thinkTime samples the time a user waits between invocations. Samplers aren't safe for concurrent use, so users take turns.
+func (gen *ClosedLoopGenerator) thinkTime() time.Duration {
+	gen.thinkTimeLock.Lock()
+	defer gen.thinkTimeLock.Unlock()
+	return gen.ThinkTime.GetNextValue()
+}
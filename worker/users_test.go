@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/opentracing/opentracing-go"
+)
+
+//blockingUnit is a unit whose invocations last until finish is closed. Its invocations are limited by the limiter of the worker.
+type blockingUnit struct {
+	Unit
+	worker *Worker
+	//invocations counts the invocations atomically, invoked signals them while the test receives the signals.
+	invocations int64
+	invoked     chan struct{}
+	finish      chan struct{}
+}
+
+func newBlockingUnit(limiter *ConcurrencyLimiter) *blockingUnit {
+	return &blockingUnit{
+		worker:  &Worker{Limiter: limiter, dropped: make(map[string]int64)},
+		invoked: make(chan struct{}, 10),
+		finish:  make(chan struct{}),
+	}
+}
+
+func (u *blockingUnit) Invoke(ctx context.Context, tracer opentracing.Tracer) error {
+	atomic.AddInt64(&u.invocations, 1)
+	select {
+	case u.invoked <- struct{}{}:
+	default:
+	}
+	<-u.finish
+	return nil
+}
+
+func (u *blockingUnit) InvokeLimited(ctx context.Context, tracer opentracing.Tracer, wait context.Context) bool {
+	return u.worker.invokeLimited(wait, nil, func() {
+		u.Invoke(ctx, tracer)
+	})
+}
+
+//stopUsers stops the generator, and returns a channel which is closed when it finished.
+func stopUsers(stop chan<- bool, waitGroup *sync.WaitGroup) <-chan struct{} {
+	stop <- true
+	finished := make(chan struct{})
+	go func() {
+		waitGroup.Wait()
+		close(finished)
+	}()
+	return finished
+}
+
+func newUsers(t *testing.T, unit Unit, count int64) *ClosedLoopGenerator {
+	t.Helper()
+	config := &api.Unit{Identifier: "root", Users: &api.Users{Count: count, ThinkTime: paramWork("constant", "value", 0)}}
+	generator, err := NewClosedLoopGenerator(unit, config, opentracing.NoopTracer{}, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return generator.(*ClosedLoopGenerator)
+}
+
+func TestUsersFinishInvocationsWhenStopped(t *testing.T) {
+	unit := newBlockingUnit(nil)
+	gen := newUsers(t, unit, 3)
+	stop := make(chan bool, 1)
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(1)
+	gen.GenerateUntilExitSignal(stop, nil, &waitGroup)
+	for i := 0; i < 3; i++ {
+		<-unit.invoked
+	}
+	finished := stopUsers(stop, &waitGroup)
+	select {
+	case <-finished:
+		t.Fatal("expected the generator to wait for the invocations of its users")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(unit.finish)
+	waitFor(t, finished, "generator")
+	if gen.TraceCounter != 3 {
+		t.Errorf("expected the 3 invocations in flight to be counted, got %d", gen.TraceCounter)
+	}
+	if unit.invocations != 3 {
+		t.Errorf("expected users not to start new invocations after the stop, %d did", unit.invocations-3)
+	}
+}
+
+func TestUsersWaitForConcurrencyLimit(t *testing.T) {
+	limiter, err := NewConcurrencyLimiter(&api.Concurrency{Limit: 1, Policy: DropPolicy}, "worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unit := newBlockingUnit(limiter)
+	gen := newUsers(t, unit, 3)
+	stop := make(chan bool, 1)
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(1)
+	gen.GenerateUntilExitSignal(stop, nil, &waitGroup)
+	for i := 0; i < 2; i++ {
+		<-unit.invoked
+		select {
+		case <-unit.invoked:
+			t.Fatal("expected users to wait for the slot of the running invocation")
+		case <-time.After(50 * time.Millisecond):
+		}
+		//the next user takes the slot, when the running invocation is finished
+		unit.finish <- struct{}{}
+	}
+	<-unit.invoked
+	finished := stopUsers(stop, &waitGroup)
+	close(unit.finish)
+	waitFor(t, finished, "generator")
+	if gen.TraceCounter != unit.invocations {
+		t.Errorf("expected all %d invocations to be counted, got %d", unit.invocations, gen.TraceCounter)
+	}
+	if len(limiter.slots) != 0 {
+		t.Errorf("expected all slots to be free, %d are held", len(limiter.slots))
+	}
+	if dropped := unit.worker.droppedInvocations(); dropped != nil {
+		t.Errorf("expected waiting users not to be dropped, got %v", dropped)
+	}
+}
@@ -1,8 +1,10 @@
 package worker
 
 import (
+	"net/http"
 	"strings"
 
+	"github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -35,3 +37,21 @@ func (w metadataReaderWriter) ForeachKey(handler func(key, val string) error) er
 
 	return nil
 }
+
+//Some tracers (e.g. the OpenTelemetry bridge) only accept opentracing.HTTPHeadersCarrier for the HTTPHeaders format, so we convert from and to grpc metadata.
+func metadataToHTTPHeaders(md metadata.MD) opentracing.HTTPHeadersCarrier {
+	header := http.Header{}
+	for k, vals := range md {
+		for _, v := range vals {
+			header.Add(k, v)
+		}
+	}
+	return opentracing.HTTPHeadersCarrier(header)
+}
+
+func (w metadataReaderWriter) SetFromHTTPHeaders(carrier opentracing.HTTPHeadersCarrier) error {
+	return carrier.ForeachKey(func(key, val string) error {
+		w.Set(key, val)
+		return nil
+	})
+}
@@ -3,52 +3,164 @@ package worker
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
 	"os/signal"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/dominik-/t-race/api"
+	"github.com/dominik-/t-race/version"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 )
 
+//DefaultCallTimeout is the timeout of calls to remote successors, if a worker doesn't configure one.
+const DefaultCallTimeout = 10 * time.Second
+
+//Worker emulates a single service. A run is configured by the coordinator with Prepare, started with Start and ends after the configured runtime or on Stop.
 type Worker struct {
 	Tracer           opentracing.Tracer
+	Inspector        SpanContextInspector
+	Propagator       *ContextPropagator
 	Reporter         ResultReporter
 	SpanDurationHist prometheus.Histogram
-	Config           *api.WorkerConfiguration
-	ServicePort      int
+	HeaderSizeHist   prometheus.Histogram
+	GeneratorMetrics *GeneratorMetrics
+	//DroppedInvocations counts invocations dropped by concurrency limits, labeled with the unit and the scope of the limit.
+	DroppedInvocations *prometheus.CounterVec
+	//Limiter limits the concurrent invocations of the worker in the current run, nil doesn't limit them.
+	Limiter *ConcurrencyLimiter
+	//stopped is done when the current run is stopped or released, queued invocations stop waiting for slots then.
+	stopped     context.Context
+	Config      *api.WorkerConfiguration
+	ServicePort int
+	//ServiceListen replaces the TCP listener on ServicePort, e.g. for in-memory connections of a LocalCluster. It is called by each Prepare,
+	//since the listener is closed when the run is released.
+	ServiceListen func() (net.Listener, error)
+	//DialOptions are added to the options used to connect to remote successors.
+	DialOptions []grpc.DialOption
+	//CallTimeout limits the duration of calls to remote successors. Zero uses DefaultCallTimeout.
+	CallTimeout      time.Duration
 	SamplingStrategy string
 	SamplingParams   []float64
 	SetupDone        bool
 	MetricsRegistry  prometheus.Registerer
 	UnitExecutorMap  map[string]Unit
+	//targets holds the *callTargets of the prepared run.
+	targets   atomic.Value
+	state     api.WorkerState
+	lastError string
+	run       *workerRun
+	stateLock sync.Mutex
+	dropped   map[string]int64
+	dropLock  sync.Mutex
+	//seed of the current run, from which the seeds of units are derived
+	seed int64
 	api.UnimplementedBenchmarkWorkerServer
 }
 
+//callTargets are the units and the tracer, which serve calls of predecessors. Calls aren't served under the stateLock, since releasing a run waits for them,
+//so Prepare publishes the targets of a run at once when its units are complete.
+type callTargets struct {
+	units  map[string]Unit
+	tracer opentracing.Tracer
+}
+
+//workerRun contains everything created by Prepare, which is released after the run.
+type workerRun struct {
+	closer     io.Closer
+	server     *grpc.Server
+	reporter   *BufferingReporter
+	generators []UnitContextGenerator
+	stopSignal chan bool
+	aborted    bool
+	//stop cancels the stopped context of the worker for this run.
+	stop        context.CancelFunc
+	releaseOnce sync.Once
+}
+
+//release stops the service endpoint and flushes the tracer. It is safe to call release multiple times.
+func (r *workerRun) release() {
+	r.releaseOnce.Do(func() {
+		r.stop()
+		r.server.GracefulStop()
+		r.closer.Close()
+	})
+}
+
+//StartWorker prepares and starts a run in one step, i.e. without synchronization between workers.
 func (w *Worker) StartWorker(config *api.WorkerConfiguration, stream api.BenchmarkWorker_StartWorkerServer) error {
-	//need to do the run here, i.e. start Writer with generator and return results
-	//hook to SIGINT/SIGTERM
-	sigTermRecv := make(chan os.Signal, 1)
-	signal.Notify(sigTermRecv, syscall.SIGINT, syscall.SIGTERM)
+	if _, err := w.Prepare(stream.Context(), config); err != nil {
+		return err
+	}
+	return w.Start(&api.StartRequest{WorkerId: config.WorkerId}, stream)
+}
 
+func (w *Worker) Prepare(ctx context.Context, config *api.WorkerConfiguration) (*api.WorkerStatus, error) {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+	if w.state == api.WorkerState_RUNNING || w.state == api.WorkerState_STOPPING {
+		return nil, status.Errorf(codes.FailedPrecondition, "worker is %s, can't prepare a new run", w.state)
+	}
+	if len(w.SamplingParams) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "worker has no sampling parameter for sampling strategy %s", w.SamplingStrategy)
+	}
+	//a prepared run which was never started is replaced
+	if w.run != nil {
+		w.run.release()
+		w.run = nil
+	}
+	run, err := w.prepareRun(config)
+	if err != nil {
+		log.Printf("Couldn't prepare worker: %v", err)
+		w.state = api.WorkerState_FAILED
+		w.lastError = err.Error()
+		return nil, status.Errorf(codes.Internal, "couldn't prepare worker: %v", err)
+	}
+	w.run = run
+	w.state = api.WorkerState_PREPARED
+	w.lastError = ""
+	log.Printf("Prepared worker. Config: %v\n", config)
+	return w.status(), nil
+}
+
+//prepareRun creates the tracer, metrics, service endpoint and units of a run.
+func (w *Worker) prepareRun(config *api.WorkerConfiguration) (*workerRun, error) {
+	//the service endpoint of the run accepts calls before the units are created, they fail until the units are complete
+	w.targets.Store(&callTargets{})
 	//Create sink (i.e. tracing backend) connection
-	tracer, closer, err := InitTracer(config.SinkHostPort, config.ServiceName, w.SamplingStrategy, w.SamplingParams[0])
-	// we can't go on if this didnt work
+	backend, err := LookupTracerBackend(config.SinkProvider)
+	if err != nil {
+		return nil, err
+	}
+	propagator, err := NewContextPropagator(backend, config.Propagation)
 	if err != nil {
-		log.Fatalf("Couldn't create tracer with given config. Error was: %v", err)
+		return nil, err
+	}
+	tracer, closer, err := backend.NewTracer(config.SinkHostPort, config.ServiceName, w.SamplingStrategy, w.SamplingParams[0])
+	if err != nil {
+		return nil, err
 	}
 	w.Tracer = tracer
-	defer closer.Close()
+	w.Inspector = backend.Inspector()
+	w.Propagator = propagator
 	//Setup for prometheus metrics
 	if !w.SetupDone {
-		w.MetricsRegistry = prometheus.NewRegistry()
+		if w.MetricsRegistry == nil {
+			w.MetricsRegistry = prometheus.NewRegistry()
+		}
 		w.SpanDurationHist = prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: "worker",
 			//Subsystem: config.OperationName,
@@ -56,14 +168,39 @@ func (w *Worker) StartWorker(config *api.WorkerConfiguration, stream api.Benchma
 			Help:    "A Histogram of Span durations",
 			Buckets: []float64{10000.0, 20000.0, 50000.0, 100000.0, 200000.0},
 		})
-		w.MetricsRegistry.MustRegister(w.SpanDurationHist)
+		w.HeaderSizeHist = prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "worker",
+			Name:      "propagation_header_size",
+			Help:      "A Histogram of the size in bytes of trace context headers sent to successors",
+			Buckets:   []float64{50.0, 100.0, 200.0, 500.0, 1000.0, 2000.0},
+		})
+		w.GeneratorMetrics = NewGeneratorMetrics()
+		w.DroppedInvocations = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "worker",
+			Name:      "dropped_invocations_total",
+			Help:      "Invocations dropped because a concurrency limit was reached",
+		}, []string{"unit", "limit"})
+		w.MetricsRegistry.MustRegister(w.SpanDurationHist, w.HeaderSizeHist, w.DroppedInvocations)
+		w.MetricsRegistry.MustRegister(w.GeneratorMetrics.Collectors()...)
 		w.SetupDone = true
 	}
-	w.Reporter = NewBufferingReporter(stream, 500)
+	//results of calls before the run starts are buffered until Start provides the stream
+	reporter := NewBufferingReporter(nil, 500)
+	w.Reporter = reporter
 	w.Config = config
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", w.ServicePort))
+	w.seed = config.Seed
+	if w.seed == 0 {
+		w.seed = time.Now().UnixNano()
+	}
+	var listener net.Listener
+	if w.ServiceListen != nil {
+		listener, err = w.ServiceListen()
+	} else {
+		listener, err = net.Listen("tcp", fmt.Sprintf(":%d", w.ServicePort))
+	}
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		closer.Close()
+		return nil, err
 	}
 	server := grpc.NewServer(
 		grpc.KeepaliveParams(keepalive.ServerParameters{
@@ -73,112 +210,301 @@ func (w *Worker) StartWorker(config *api.WorkerConfiguration, stream api.Benchma
 	api.RegisterBenchmarkWorkerServer(server, w)
 	//start server in separate goroutine so we don't block here
 	go server.Serve(listener)
-	log.Printf("Started worker. Config: %v\n", config)
-	w.UnitExecutorMap = make(map[string]Unit)
-	var generatorWG sync.WaitGroup
-	generators := make([]UnitContextGenerator, 0)
-	stopSignals := make([]chan bool, 0)
-	//stopChan := make(chan bool, 1)
-
+	stopped, stop := context.WithCancel(context.Background())
+	w.stopped = stopped
+	run := &workerRun{
+		stop:       stop,
+		closer:     closer,
+		server:     server,
+		reporter:   reporter,
+		generators: make([]UnitContextGenerator, 0),
+		stopSignal: make(chan bool, 1),
+	}
+	w.Limiter, err = NewConcurrencyLimiter(config.Concurrency, "worker")
+	if err != nil {
+		run.release()
+		return nil, fmt.Errorf("invalid concurrency limit of the worker: %v", err)
+	}
+	w.dropLock.Lock()
+	w.dropped = make(map[string]int64)
+	w.dropLock.Unlock()
+	units := make(map[string]Unit)
 	for _, unit := range config.Units {
 		unitExec, err := CreateUnitExecutorFromConfig(unit, w)
 		if err != nil {
-			log.Fatalf("Couldn't create executor for unit %s!", unit.Identifier)
+			run.release()
+			return nil, fmt.Errorf("couldn't create executor for unit %s: %v", unit.Identifier, err)
+		}
+		if unit.Users != nil && unit.Users.Count > 0 {
+			generator, err := NewClosedLoopGenerator(unitExec, unit, tracer, w.unitSeed(unit.Identifier, "thinkTime"), w.GeneratorMetrics)
+			if err != nil {
+				run.release()
+				return nil, fmt.Errorf("couldn't create virtual users for unit %s: %v", unit.Identifier, err)
+			}
+			run.generators = append(run.generators, generator)
+		} else if unit.ThroughputRatio > 0.000001 {
+			generator, err := NewOpenTracingUnitSpanGenerator(unitExec, unit, config.LoadProfile, w.Config.ServiceName, tracer, w.Config.TargetThroughput, w.unitSeed(unit.Identifier, "arrivals"), w.GeneratorMetrics, w.SpanDurationHist)
+			if err != nil {
+				run.release()
+				return nil, fmt.Errorf("couldn't create generator for unit %s: %v", unit.Identifier, err)
+			}
+			run.generators = append(run.generators, generator)
 		}
-		if unit.ThroughputRatio > 0.000001 {
-			generatorWG.Add(1)
-			stopSignals = append(stopSignals, make(chan bool, 1))
-			generators = append(generators, NewOpenTracingUnitSpanGenerator(unitExec, w.Config.ServiceName, tracer, w.Config.TargetThroughput, w.SpanDurationHist))
+		units[unit.Identifier] = unitExec
+	}
+	w.UnitExecutorMap = units
+	w.targets.Store(&callTargets{units: units, tracer: tracer})
+	//connections are established in the background, the coordinator waits for them via Status
+	w.pendingSuccessors()
+	return run, nil
+}
+
+func (w *Worker) Start(request *api.StartRequest, stream api.BenchmarkWorker_StartServer) error {
+	w.stateLock.Lock()
+	if w.state != api.WorkerState_PREPARED {
+		defer w.stateLock.Unlock()
+		return status.Errorf(codes.FailedPrecondition, "worker is %s, only prepared workers can be started", w.state)
+	}
+	run := w.run
+	w.state = api.WorkerState_RUNNING
+	run.reporter.SetTarget(stream)
+	w.stateLock.Unlock()
+	startTime := time.Now()
+	if request.StartTime != nil {
+		startTime, _ = ptypes.Timestamp(request.StartTime)
+	}
+
+	aborted := true
+	if w.waitForStart(run, stream.Context(), startTime) {
+		log.Printf("Started worker %s.", w.Config.WorkerId)
+		aborted = w.execute(run, stream.Context())
+	} else {
+		log.Printf("Worker %s was stopped before the start time.", w.Config.WorkerId)
+		run.release()
+	}
+
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+	run.reporter.SetTarget(nil)
+	if aborted {
+		w.state = api.WorkerState_ABORTED
+		return status.Error(codes.Aborted, "run was aborted")
+	}
+	w.state = api.WorkerState_FINISHED
+	return nil
+}
+
+//waitForStart blocks until the start time of the run. Returns false if the run was stopped or the coordinator closed the connection before.
+func (w *Worker) waitForStart(run *workerRun, ctx context.Context, startTime time.Time) bool {
+	delay := time.Until(startTime)
+	if delay <= 0 {
+		if delay < -time.Second {
+			log.Printf("Start time %v was %v ago, starting immediately.", startTime, -delay)
 		}
-		w.UnitExecutorMap[unit.Identifier] = unitExec
+		return true
+	}
+	log.Printf("Waiting %v for start time %v.", delay, startTime)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-run.stopSignal:
+		return false
+	case <-ctx.Done():
+		return false
 	}
-	for i, generator := range generators {
-		//TODO: do we need individual stop channels for each generator? to signal them to halt load generation?
+}
+
+//execute generates load until the runtime is over or the run is stopped, and reports the final results. Returns whether the run was aborted.
+func (w *Worker) execute(run *workerRun, ctx context.Context) bool {
+	//hook to SIGINT/SIGTERM
+	sigTermRecv := make(chan os.Signal, 1)
+	signal.Notify(sigTermRecv, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigTermRecv)
+	defer run.release()
+
+	var generatorWG sync.WaitGroup
+	stopSignals := make([]chan bool, len(run.generators))
+	for i, generator := range run.generators {
+		generatorWG.Add(1)
+		stopSignals[i] = make(chan bool, 1)
 		go generator.GenerateUntilExitSignal(stopSignals[i], w.Reporter, &generatorWG)
 	}
-	//w.Generator = NewOpenTracingSpanGenerator(tracer, w)
-	//We create a new done channel here if this is a root service
-	//TODO: Ideally, the "default" case for the done channel link would be that the worker didn't receive "Call"-Requests for a few seconds,
-	//indicating that the root-workers no longer send requests;
-	//doneChannel = w.Generator.WriteSpansUntilExitSignal(stopChan, config.TargetThroughput, w.Reporter)
-	doneChannelLoadGenerators := make(chan bool, 1)
 	doneChannelReporters := make(chan bool, 1)
 	go func() {
 		//TODO make report interval configurable; together with channel buffer size, this limits the maximum throughput!
 		reporterCollectionTicker := time.NewTicker(5 * time.Second)
-	ReporterLoop:
+		defer reporterCollectionTicker.Stop()
 		for {
 			select {
 			case <-doneChannelReporters:
-				//log.Println("Received shutdown signal at write Span loop.")
-				break ReporterLoop
+				return
 			case <-reporterCollectionTicker.C:
 				go w.Reporter.Report()
 			}
 		}
 	}()
-	//create a timer with runtime plus tolerance
+	defer close(doneChannelReporters)
 	//TODO: make tolerance time (time after which worker is shut down forcefully after runtime) a parameter of the benchmark
 	tolerance := 8 * time.Second
-	timer := time.NewTimer(time.Duration(config.RuntimeSeconds) * time.Second)
-WorkerLoop:
-	for {
-		select {
-		case <-timer.C:
-			//Stop all running generators
-			for _, ch := range stopSignals {
-				ch <- true
-			}
-			limit := time.NewTimer(tolerance)
-			go func(doneChannels ...chan bool) {
-				generatorWG.Wait()
-				for _, ch := range doneChannels {
-					ch <- true
-				}
-			}(doneChannelLoadGenerators, doneChannelReporters)
-
-			for {
-				select {
-				case <-doneChannelLoadGenerators:
-					log.Println("Benchmark ended regularly after runtime.")
-					break WorkerLoop
-				case <-limit.C:
-					log.Printf("Benchmark shut down after runtime plus tolerance of %v.\n", tolerance)
-					break WorkerLoop
-				}
-			}
-		case <-sigTermRecv:
-			//Stop all running generators
-			for _, ch := range stopSignals {
-				ch <- true
-			}
-			limit := time.NewTimer(5 * time.Second)
-			go func(doneChannels ...chan bool) {
-				generatorWG.Wait()
-				for _, ch := range doneChannels {
-					ch <- true
-				}
-			}(doneChannelLoadGenerators, doneChannelReporters)
-			for {
-				select {
-				case <-doneChannelLoadGenerators:
-					log.Println("Benchmark ended by manual interrupt from user.")
-					break WorkerLoop
-				case <-limit.C:
-					log.Println("Benchmark shut down forcefully after manual interrupt from user.")
-					break WorkerLoop
-				}
+	timer := time.NewTimer(time.Duration(w.Config.RuntimeSeconds) * time.Second)
+	defer timer.Stop()
+	reason := ""
+	select {
+	case <-timer.C:
+		reason = "after runtime"
+	case <-run.stopSignal:
+		reason = "by the coordinator"
+	case <-sigTermRecv:
+		tolerance = 5 * time.Second
+		reason = "by manual interrupt from user"
+	case <-ctx.Done():
+		//the coordinator is gone, so results can't be reported anyway
+		w.stateLock.Lock()
+		run.aborted = true
+		w.stateLock.Unlock()
+		reason = "because the coordinator closed the connection"
+	}
+	//Stop all running generators and invocations waiting for slots
+	run.stop()
+	for _, ch := range stopSignals {
+		ch <- true
+	}
+	generatorsDone := make(chan bool, 1)
+	go func() {
+		generatorWG.Wait()
+		generatorsDone <- true
+	}()
+	select {
+	case <-generatorsDone:
+		log.Printf("Benchmark ended regularly %s.", reason)
+	case <-time.After(tolerance):
+		log.Printf("Benchmark shut down %s plus tolerance of %v.\n", reason, tolerance)
+	}
+	if !w.isAborted(run) {
+		//important: do final reporting after benchmark ends. We give 5 seconds tolerance to make sure all results are reported.
+		<-time.NewTimer(5 * time.Second).C
+	}
+	//the run might have been aborted during the tolerance, too
+	if w.isAborted(run) {
+		run.reporter.Discard()
+		return true
+	}
+	w.Reporter.Report()
+	return false
+}
+
+func (w *Worker) isAborted(run *workerRun) bool {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+	return run.aborted
+}
+
+func (w *Worker) Stop(ctx context.Context, request *api.StopRequest) (*api.WorkerStatus, error) {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+	switch w.state {
+	case api.WorkerState_RUNNING:
+		log.Printf("Stopping run (abort: %t): %s", request.Abort, request.Reason)
+		w.state = api.WorkerState_STOPPING
+		w.run.aborted = request.Abort
+		w.run.stopSignal <- true
+	case api.WorkerState_STOPPING:
+		//a stopping run can still be aborted
+		w.run.aborted = w.run.aborted || request.Abort
+	case api.WorkerState_PREPARED:
+		log.Printf("Aborting prepared run: %s", request.Reason)
+		w.run.release()
+		w.state = api.WorkerState_ABORTED
+	}
+	return w.status(), nil
+}
+
+func (w *Worker) Status(ctx context.Context, empty *api.Empty) (*api.WorkerStatus, error) {
+	w.stateLock.Lock()
+	defer w.stateLock.Unlock()
+	return w.status(), nil
+}
+
+//status returns the current status; the stateLock must be held by the caller.
+func (w *Worker) status() *api.WorkerStatus {
+	workerStatus := &api.WorkerStatus{
+		State: w.state,
+		Error: w.lastError,
+	}
+	if w.Config != nil {
+		workerStatus.WorkerId = w.Config.WorkerId
+	}
+	if w.run != nil {
+		workerStatus.ResultsReported = w.run.reporter.Reported()
+		workerStatus.DroppedInvocations = w.droppedInvocations()
+	}
+	workerStatus.SamplingStrategy = w.SamplingStrategy
+	if len(w.SamplingParams) > 0 {
+		workerStatus.SamplingParam = w.SamplingParams[0]
+	}
+	workerStatus.Version = version.String()
+	workerStatus.Hostname, _ = os.Hostname()
+	if w.state == api.WorkerState_PREPARED {
+		workerStatus.PendingSuccessors = w.pendingSuccessors()
+		workerStatus.Ready = len(workerStatus.PendingSuccessors) == 0
+	}
+	return workerStatus
+}
+
+//pendingSuccessors returns the services of remote successors, which the worker isn't connected to yet. Idle connections are asked to connect.
+func (w *Worker) pendingSuccessors() []string {
+	pendingSet := make(map[string]bool)
+	for _, unit := range w.UnitExecutorMap {
+		executor, ok := unit.(*UnitExecutor)
+		if !ok {
+			continue
+		}
+		for service, conn := range executor.SuccessorClients {
+			switch conn.GetState() {
+			case connectivity.Ready:
+				continue
+			case connectivity.Idle:
+				conn.Connect()
 			}
+			pendingSet[service] = true
 		}
 	}
-	//important: do final reporting after benchmark ends. We give 5 seconds tolerance to make sure all results are reported.
-	<-time.NewTimer(5 * time.Second).C
-	w.Reporter.Report()
-	server.GracefulStop()
-	return nil
+	pending := make([]string, 0, len(pendingSet))
+	for service := range pendingSet {
+		pending = append(pending, service)
+	}
+	sort.Strings(pending)
+	return pending
 }
 
+//Call invokes a unit of this worker on behalf of a predecessor. It fails with NotFound if the unit doesn't exist.
 func (w *Worker) Call(ctx context.Context, id *api.DispatchId) (*api.Empty, error) {
-	w.UnitExecutorMap[id.UnitReference].Invoke(ctx, w.Tracer)
+	unit, tracer, ok := w.lookupUnit(id.UnitReference)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown unit %s", id.UnitReference)
+	}
+	if err := unit.Invoke(ctx, tracer); err != nil {
+		return nil, err
+	}
 	return &api.Empty{}, nil
 }
+
+//lookupUnit returns a unit of the prepared run and the tracer of the run. It is safe to call while a run is prepared.
+func (w *Worker) lookupUnit(id string) (Unit, opentracing.Tracer, bool) {
+	targets, _ := w.targets.Load().(*callTargets)
+	if targets == nil {
+		return nil, nil, false
+	}
+	unit, ok := targets.units[id]
+	return unit, targets.tracer, ok
+}
+
+//callTimeout returns the timeout of calls to remote successors.
+func (w *Worker) callTimeout() time.Duration {
+	if w.CallTimeout <= 0 {
+		return DefaultCallTimeout
+	}
+	return w.CallTimeout
+}
@@ -0,0 +1,178 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dominik-/t-race/api"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPrepareWithoutSamplingParameter(t *testing.T) {
+	w := &Worker{SamplingStrategy: "const"}
+	_, err := w.Prepare(context.Background(), &api.WorkerConfiguration{WorkerId: "worker-backend", ServiceName: "backend", SinkProvider: "jaeger"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+	if w.run != nil {
+		t.Errorf("expected no run to be prepared")
+	}
+}
+
+//benchmarkClient connects to the benchmark endpoint of a worker of the cluster, like the coordinator does.
+func benchmarkClient(t *testing.T, cluster *LocalCluster, service string) api.BenchmarkWorkerClient {
+	t.Helper()
+	conn, err := grpc.Dial(cluster.BenchmarkAddress(service), grpc.WithInsecure(), cluster.DialOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return api.NewBenchmarkWorkerClient(conn)
+}
+
+func TestPreparedWorkerIsReadyOnceSuccessorsListen(t *testing.T) {
+	cluster := NewLocalCluster([]string{"frontend", "backend"}, "const", 1)
+	defer cluster.Stop()
+	frontend := benchmarkClient(t, cluster, "frontend")
+	_, err := frontend.Prepare(context.Background(), &api.WorkerConfiguration{
+		WorkerId:     "worker-frontend",
+		ServiceName:  "frontend",
+		SinkProvider: "jaeger",
+		SinkHostPort: "localhost:6831",
+		Units: []*api.Unit{{Identifier: "root", Successors: []*api.UnitRef{
+			{ServiceId: "backend", UnitId: "b", Sync: true, IsRemote: true, HostPort: cluster.ServiceAddress("backend")},
+		}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the backend isn't prepared, so it doesn't listen yet
+	workerStatus, err := frontend.Status(context.Background(), &api.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if workerStatus.Ready || !reflect.DeepEqual(workerStatus.PendingSuccessors, []string{"backend"}) {
+		t.Errorf("expected the frontend to wait for the backend, got ready %t, pending %v", workerStatus.Ready, workerStatus.PendingSuccessors)
+	}
+	_, err = benchmarkClient(t, cluster, "backend").Prepare(context.Background(), &api.WorkerConfiguration{
+		WorkerId:     "worker-backend",
+		ServiceName:  "backend",
+		SinkProvider: "jaeger",
+		SinkHostPort: "localhost:6831",
+		Units:        []*api.Unit{{Identifier: "b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the connection is retried with a backoff
+	deadline := time.Now().Add(5 * time.Second)
+	for !workerStatus.Ready {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the frontend to connect to the backend, still pending %v", workerStatus.PendingSuccessors)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if workerStatus, err = frontend.Status(context.Background(), &api.Empty{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(workerStatus.PendingSuccessors) != 0 {
+		t.Errorf("expected no pending successors of a ready worker, got %v", workerStatus.PendingSuccessors)
+	}
+}
+
+//startRun prepares a backend worker generating 50 traces/s for a second and starts it at the given start time.
+func startRun(t *testing.T, client api.BenchmarkWorkerClient, startTime time.Time) api.BenchmarkWorker_StartClient {
+	t.Helper()
+	_, err := client.Prepare(context.Background(), &api.WorkerConfiguration{
+		WorkerId:         "worker-backend",
+		ServiceName:      "backend",
+		SinkProvider:     "jaeger",
+		SinkHostPort:     "localhost:6831",
+		TargetThroughput: 50,
+		RuntimeSeconds:   1,
+		Units:            []*api.Unit{{Identifier: "b", ThroughputRatio: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, err := ptypes.TimestampProto(startTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := client.Start(context.Background(), &api.StartRequest{WorkerId: "worker-backend", StartTime: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return stream
+}
+
+//receiveResults reads the stream until it is closed and returns the results and the final error.
+func receiveResults(stream api.BenchmarkWorker_StartClient) ([]*api.Result, error) {
+	results := make([]*api.Result, 0)
+	for {
+		resultPackage, err := stream.Recv()
+		if err != nil {
+			return results, err
+		}
+		results = append(results, resultPackage.Results...)
+	}
+}
+
+func TestStartWaitsForStartTime(t *testing.T) {
+	cluster := NewLocalCluster([]string{"backend"}, "const", 1)
+	defer cluster.Stop()
+	startTime := time.Now().Add(500 * time.Millisecond)
+	results, err := receiveResults(startRun(t, benchmarkClient(t, cluster, "backend"), startTime))
+	if err != io.EOF {
+		t.Fatalf("expected the run to finish, got %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("expected results of the run")
+	}
+	for _, result := range results {
+		if spanStart, _ := ptypes.Timestamp(result.StartTime); spanStart.Before(startTime) {
+			t.Fatalf("expected spans to start after the start time %v, got a span started at %v", startTime, spanStart)
+		}
+	}
+}
+
+func TestStopBeforeStartTime(t *testing.T) {
+	cluster := NewLocalCluster([]string{"backend"}, "const", 1)
+	defer cluster.Stop()
+	client := benchmarkClient(t, cluster, "backend")
+	stream := startRun(t, client, time.Now().Add(time.Hour))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		workerStatus, err := client.Status(context.Background(), &api.Empty{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if workerStatus.State == api.WorkerState_RUNNING {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the worker to be started, it is %s", workerStatus.State)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := client.Stop(context.Background(), &api.StopRequest{Abort: true, Reason: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	var results []*api.Result
+	var err error
+	go func() {
+		defer close(done)
+		results, err = receiveResults(stream)
+	}()
+	waitFor(t, done, "the stopped run")
+	if status.Code(err) != codes.Aborted || len(results) != 0 {
+		t.Errorf("expected the run to be aborted without results, got %d results and %v", len(results), err)
+	}
+}